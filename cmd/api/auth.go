@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+)
+
+// minJWTSecretLength keeps HS256 secrets too long to guess: as many bytes
+// as the hash.
+const minJWTSecretLength = 32
+
+// newAuthenticator configures authentication from the environment, with
+// keys looking up stored API keys. It returns nil if AUTH_DISABLED turns
+// authentication off.
+func newAuthenticator(keys auth.KeyLookup) *auth.Authenticator {
+	if v := os.Getenv("AUTH_DISABLED"); v != "" {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalf("invalid AUTH_DISABLED: %v", err)
+		}
+		if disabled {
+			log.Println("AUTH_DISABLED is set, every endpoint is open to anyone")
+			return nil
+		}
+	}
+
+	cfg := auth.Config{AdminKey: os.Getenv("ADMIN_API_KEY"), Keys: keys}
+	var jwt auth.JWTConfig
+	if secret := os.Getenv("AUTH_JWT_SECRET"); secret != "" {
+		if len(secret) < minJWTSecretLength {
+			log.Fatalf("AUTH_JWT_SECRET must be at least %d bytes long", minJWTSecretLength)
+		}
+		jwt.Secret = []byte(secret)
+	}
+	if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+		var err error
+		if jwt.Keys, err = auth.OpenJWKS(path); err != nil {
+			log.Fatalf("invalid AUTH_JWKS_FILE: %v", err)
+		}
+	}
+	if jwt.Secret != nil || jwt.Keys != nil {
+		jwt.Issuer = os.Getenv("AUTH_JWT_ISSUER")
+		jwt.Audience = os.Getenv("AUTH_JWT_AUDIENCE")
+		jwt.RoleClaim = os.Getenv("AUTH_JWT_ROLE_CLAIM")
+		jwt.TenantClaim = os.Getenv("AUTH_JWT_TENANT_CLAIM")
+		cfg.JWT = auth.NewJWTVerifier(jwt)
+	}
+	// Stored keys are created by admins, so without an admin key or tokens
+	// no request could ever be authenticated.
+	if cfg.AdminKey == "" && cfg.JWT == nil {
+		log.Fatal("authentication is on but nothing can authenticate: set ADMIN_API_KEY, AUTH_JWT_SECRET or AUTH_JWKS_FILE, or AUTH_DISABLED=true")
+	}
+	return auth.New(cfg)
+}
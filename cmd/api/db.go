@@ -0,0 +1,95 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+)
+
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+	defaultConnMaxIdleTime = 5 * time.Minute
+	defaultConnectTimeout  = time.Minute
+	defaultHealthInterval  = 30 * time.Second
+	defaultReadYourWrites  = 2 * time.Second
+)
+
+// intFromEnv parses an int from key, falling back to def.
+func intFromEnv(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("invalid %s: %q", key, v)
+	}
+	return n
+}
+
+// boolFromEnv parses a bool from key, falling back to false.
+func boolFromEnv(key string) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %q", key, v)
+	}
+	return b
+}
+
+// databaseOptions reads the connection pool settings, how long to wait for
+// the database at startup and whether to turn on row-level security from
+// the environment.
+func databaseOptions() database.Options {
+	return database.Options{
+		MaxOpenConns:     intFromEnv("DB_MAX_OPEN_CONNS", defaultMaxOpenConns),
+		MaxIdleConns:     intFromEnv("DB_MAX_IDLE_CONNS", defaultMaxIdleConns),
+		ConnMaxLifetime:  durationFromEnv("DB_CONN_MAX_LIFETIME", defaultConnMaxLifetime),
+		ConnMaxIdleTime:  durationFromEnv("DB_CONN_MAX_IDLE_TIME", defaultConnMaxIdleTime),
+		ConnectTimeout:   durationFromEnv("DB_CONNECT_TIMEOUT", defaultConnectTimeout),
+		RowLevelSecurity: boolFromEnv("DB_ROW_LEVEL_SECURITY"),
+	}
+}
+
+// startHealthCheck pings the database every DB_HEALTH_INTERVAL. An interval
+// of 0 turns health checks off and returns nil.
+func startHealthCheck(db *sql.DB) *database.Health {
+	interval := durationFromEnv("DB_HEALTH_INTERVAL", defaultHealthInterval)
+	if interval <= 0 {
+		log.Println("DB_HEALTH_INTERVAL is 0, database health checks are off")
+		return nil
+	}
+	return database.StartHealthCheck(db, interval)
+}
+
+// openReplicas connects to the comma-separated read replica URLs in
+// DATABASE_REPLICA_URLS. It returns nil when there are none.
+func openReplicas(primary *sql.DB) *database.Cluster {
+	var urls []string
+	for _, url := range strings.Split(os.Getenv("DATABASE_REPLICA_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	interval := durationFromEnv("DB_HEALTH_INTERVAL", defaultHealthInterval)
+	cluster, err := database.NewCluster(primary, urls, databaseOptions(), interval)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Spreading reads over %d read replicas", len(urls))
+	return cluster
+}
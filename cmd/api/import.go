@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/importer"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+)
+
+// mappingFlags collects repeated -map column=field flags.
+type mappingFlags []string
+
+func (m *mappingFlags) String() string     { return strings.Join(*m, ",") }
+func (m *mappingFlags) Set(v string) error { *m = append(*m, v); return nil }
+
+// runImport implements the "import" subcommand, the command-line equivalent
+// of POST /students/import:
+//
+//	import [-tenant id] [-dry-run] [-on-duplicate fail|skip|update] [-map column=field]... file.csv
+//
+// The students are imported for the tenant, the default one unless -tenant
+// names another. The report is printed as JSON. Use - as the file to read
+// standard input.
+func runImport(dbURL string, args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	tenantID := fs.String("tenant", tenant.Default, "the tenant to import the students for")
+	dryRun := fs.Bool("dry-run", false, "validate the file without writing anything")
+	onDuplicate := fs.String("on-duplicate", importer.OnDuplicateFail, "what to do with rows whose email exists: fail, skip or update")
+	var mapping mappingFlags
+	fs.Var(&mapping, "map", "map a CSV column to a field, as column=field (repeatable)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatal("usage: import [-tenant id] [-dry-run] [-on-duplicate fail|skip|update] [-map column=field]... file.csv")
+	}
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL must be set to import students")
+	}
+
+	// Only tenants the server would serve may be imported into.
+	t, err := loadTenants().Lookup(*tenantID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := tenant.WithTenant(context.Background(), t)
+
+	opts := importer.Options{DryRun: *dryRun, OnDuplicate: *onDuplicate}
+	if opts.Mapping, err = importer.ParseMapping(mapping); err != nil {
+		log.Fatal(err)
+	}
+
+	in := os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		if in, err = os.Open(path); err != nil {
+			log.Fatal(err)
+		}
+		defer in.Close()
+	}
+
+	db, err := database.Connect(dbURL, databaseOptions())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	report, err := importer.Run(in, &importer.PostgresTarget{DB: db, Context: ctx, Actor: "import-cli"}, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
-	"github.com/AashishKumar-3002/FealtyX/internal/storage"
-	"github.com/AashishKumar-3002/FealtyX/internal/memory"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
 	"github.com/AashishKumar-3002/FealtyX/internal/database"
 	"github.com/AashishKumar-3002/FealtyX/internal/handlers"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -20,23 +24,51 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 
+	// Apply deployment-specific validation limits
+	validationConfig, err := validator.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	validator.Configure(validationConfig)
+
+	// Bound each database query; 0 leaves only the request's own lifetime
+	database.QueryTimeout = durationFromEnv("DB_QUERY_TIMEOUT", database.QueryTimeout)
+
 	// Get the database URL from the environment
 	dbURL := os.Getenv("DATABASE_URL")
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(dbURL, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(dbURL, os.Args[2:])
+		return
+	}
+
 	log.Println("DATABASE_URL:", dbURL)
+	dir := loadTenants()
 	if dbURL == "" {
 		log.Println("DATABASE_URL is empty, using in-memory database")
-		store := storage.NewStorage()
-		apiHandler := api.NewAPI(store)
+		// Each tenant has a storage of its own
+		tenants := openTenants(dir)
+		startPurgeJob(tenants.Purge)
 
 		r := mux.NewRouter()
+		r.Use(middleware.RequestID)
+		authenticator := newAuthenticator(func(ctx context.Context, hash string) (models.APIKey, error) {
+			return tenants.GetAPIKeyByHash(hash)
+		})
+		if authenticator != nil {
+			r.Use(authenticator.Middleware)
+		}
+		r.Use(middleware.Tenant(dir))
+		if authenticator != nil {
+			r.Use(auth.NewAuthorizer(tenants.IsAssigned).Middleware)
+		}
 
 		// Define routes
-		r.HandleFunc("/students", apiHandler.CreateStudent).Methods("POST")
-		r.HandleFunc("/students", apiHandler.GetAllStudents).Methods("GET")
-		r.HandleFunc("/students/{id}", apiHandler.GetStudentByID).Methods("GET")
-		r.HandleFunc("/students/{id}", apiHandler.UpdateStudent).Methods("PUT")
-		r.HandleFunc("/students/{id}", apiHandler.DeleteStudent).Methods("DELETE")
-		r.HandleFunc("/students/{id}/summary", apiHandler.GenerateStudentSummary).Methods("GET")
+		memoryRoutes(r, tenants)
 
 		// Start server
 		port := os.Getenv("PORT")
@@ -47,27 +79,50 @@ func main() {
 		log.Fatal(http.ListenAndServe(":"+port, r))
 	} else {
 
-		// Connect to the database
-		db, err := database.Connect(dbURL)
+		// Connect to the database and apply pending migrations
+		db, err := database.Connect(dbURL, databaseOptions())
 		if err != nil {
 			log.Fatal(err)
 		}
 		defer db.Close()
 
+		startPurgeJob(func(before time.Time) (int64, error) {
+			return models.PurgeDeletedStudents(context.Background(), db, before)
+		})
+
 		// Create router
 		r := mux.NewRouter()
+		r.Use(middleware.RequestID)
 
 		// Initialize handlers
 		h := handlers.NewHandler(db)
+		h.Health = startHealthCheck(db)
+		if h.Cluster = openReplicas(db); h.Cluster != nil {
+			defer h.Cluster.Close()
+			r.Use(middleware.ReadYourWrites(durationFromEnv("READ_YOUR_WRITES_WINDOW", defaultReadYourWrites)))
+		}
+		// Keys are looked up on the primary so that a new key works at once.
+		authenticator := newAuthenticator(func(ctx context.Context, hash string) (models.APIKey, error) {
+			return models.GetAPIKeyByHash(ctx, db, hash)
+		})
+		if authenticator != nil {
+			r.Use(authenticator.Middleware)
+		}
+		// Students of other tenants are hidden behind a 404. Like keys, they
+		// are looked up on the primary.
+		r.Use(middleware.Tenant(dir))
+		r.Use(middleware.TenantStudents(func(ctx context.Context, studentID int) (bool, error) {
+			return models.StudentInTenant(ctx, db, studentID)
+		}))
+		if authenticator != nil {
+			// Like keys, assignments are checked on the primary.
+			r.Use(auth.NewAuthorizer(func(ctx context.Context, advisor string, studentID int) (bool, error) {
+				return models.IsAssigned(ctx, db, advisor, studentID)
+			}).Middleware)
+		}
 
 		// Define routes
-		r.HandleFunc("/students", h.CreateStudent).Methods("POST")
-		r.HandleFunc("/students", h.GetAllStudents).Methods("GET")
-		r.HandleFunc("/students/{id}", h.GetStudent).Methods("GET")
-		r.HandleFunc("/students/{id}", h.UpdateStudent).Methods("PUT")
-		r.HandleFunc("/students/{id}", h.DeleteStudent).Methods("DELETE")
-		r.HandleFunc("/students", h.DeleteStudentByIds).Methods("DELETE")
-		r.HandleFunc("/students/{id}/summary", h.GetStudentSummary).Methods("GET")
+		sqlRoutes(r, h)
 
 		// Start server
 		port := os.Getenv("PORT")
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+)
+
+// runMigrate implements the "migrate" subcommand:
+//
+//	migrate up          apply all pending migrations
+//	migrate down [n]    roll back the last n migrations (default 1, 0 for all)
+//	migrate status      list migrations and whether they are applied
+func runMigrate(dbURL string, args []string) {
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL must be set to run migrations")
+	}
+
+	db, err := database.Open(dbURL, databaseOptions())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		err = database.MigrateUp(db)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				log.Fatalf("invalid number of steps %q", args[1])
+			}
+		}
+		err = database.MigrateDown(db, steps)
+	case "status":
+		var statuses []database.MigrationStatus
+		statuses, err = database.GetMigrationStatus(db)
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+	default:
+		log.Fatalf("unknown migrate command %q (want up, down or status)", command)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/memory"
+	"github.com/AashishKumar-3002/FealtyX/internal/storage"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+)
+
+const (
+	defaultFsyncInterval    = time.Second
+	defaultSnapshotInterval = 5 * time.Minute
+)
+
+// openTenants returns the storage of the in-memory backend, one for each
+// tenant. If DATA_DIR is set they are kept on disk, the default tenant's in
+// DATA_DIR and every other's in DATA_DIR/tenants/<id>, with a write-ahead
+// log flushed according to WAL_FSYNC and compacted into a snapshot every
+// SNAPSHOT_INTERVAL.
+func openTenants(dir *tenant.Directory) *api.Tenants {
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		log.Println("DATA_DIR is empty, data is lost when the server stops")
+		return api.NewTenants(func(string) (*storage.Storage, error) {
+			return storage.NewStorage(), nil
+		}, nil)
+	}
+
+	fsync := storage.FsyncAlways
+	if v := os.Getenv("WAL_FSYNC"); v != "" {
+		var err error
+		if fsync, err = storage.ParseFsyncPolicy(v); err != nil {
+			log.Fatalf("invalid WAL_FSYNC: %v", err)
+		}
+	}
+	fsyncInterval := durationFromEnv("WAL_FSYNC_INTERVAL", defaultFsyncInterval)
+	// Keys are looked up in the storage of every tenant, so each tenant
+	// with data on disk is opened now; that also finds damaged data early.
+	stored := func() ([]string, error) {
+		ids := dir.IDs()
+		entries, err := os.ReadDir(filepath.Join(dataDir, "tenants"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if _, err := dir.Lookup(entry.Name()); entry.IsDir() && err == nil {
+				ids = append(ids, entry.Name())
+			}
+		}
+		return ids, nil
+	}
+	tenants := api.NewTenants(func(id string) (*storage.Storage, error) {
+		return storage.Open(storage.Options{
+			Dir:           tenantDir(dataDir, id),
+			Fsync:         fsync,
+			FsyncInterval: fsyncInterval,
+		})
+	}, stored)
+	if err := tenants.OpenStored(); err != nil {
+		log.Fatalf("Error opening the tenants in %s: %v", dataDir, err)
+	}
+	startSnapshotJob(tenants.Snapshot)
+	closeOnSignal(tenants.Close)
+	return tenants
+}
+
+// tenantDir is the directory the storage of a tenant is kept in.
+func tenantDir(dataDir, id string) string {
+	if id == tenant.Default {
+		return dataDir
+	}
+	return filepath.Join(dataDir, "tenants", id)
+}
+
+// startSnapshotJob compacts the write-ahead log every SNAPSHOT_INTERVAL.
+func startSnapshotJob(snapshot func() error) {
+	interval := durationFromEnv("SNAPSHOT_INTERVAL", defaultSnapshotInterval)
+	if interval <= 0 {
+		log.Fatal("SNAPSHOT_INTERVAL must be positive")
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := snapshot(); err != nil {
+				log.Printf("Error taking a snapshot: %v", err)
+			}
+		}
+	}()
+}
+
+// closeOnSignal closes the storage when the server is stopped, so that
+// writes not yet flushed under a relaxed WAL_FSYNC policy are kept.
+func closeOnSignal(closeStorage func() error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("Received %s, closing the storage", sig)
+		if err := closeStorage(); err != nil {
+			log.Fatalf("Error closing the storage: %v", err)
+		}
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	defaultRetention     = 30 * 24 * time.Hour
+	defaultPurgeInterval = time.Hour
+)
+
+// durationFromEnv parses a time.Duration from key, falling back to def.
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return d
+}
+
+// startPurgeJob permanently removes students that have been soft deleted for
+// longer than SOFT_DELETE_RETENTION, checking every PURGE_INTERVAL. A
+// retention of 0 keeps soft-deleted students forever.
+func startPurgeJob(purge func(before time.Time) (int64, error)) {
+	retention := durationFromEnv("SOFT_DELETE_RETENTION", defaultRetention)
+	interval := durationFromEnv("PURGE_INTERVAL", defaultPurgeInterval)
+	if retention <= 0 {
+		log.Println("SOFT_DELETE_RETENTION is 0, soft-deleted students are never purged")
+		return
+	}
+	if interval <= 0 {
+		log.Fatal("PURGE_INTERVAL must be positive")
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purged, err := purge(time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("Error purging deleted students: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Purged %d students deleted more than %s ago", purged, retention)
+			}
+		}
+	}()
+}
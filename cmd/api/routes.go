@@ -0,0 +1,139 @@
+package main
+
+import (
+	"github.com/AashishKumar-3002/FealtyX/internal/handlers"
+	api "github.com/AashishKumar-3002/FealtyX/internal/memory"
+
+	"github.com/gorilla/mux"
+)
+
+// Every route needs an entry in the auth package's route permissions;
+// routes_test.go checks that none is missing.
+
+// memoryRoutes registers the API backed by in-memory storage.
+func memoryRoutes(r *mux.Router, tenants *api.Tenants) {
+	r.HandleFunc("/students", tenants.Handle((*api.API).CreateStudent)).Methods("POST")
+	r.HandleFunc("/students", tenants.Handle((*api.API).GetAllStudents)).Methods("GET")
+	r.HandleFunc("/students/import", tenants.Handle((*api.API).ImportStudents)).Methods("POST")
+	r.HandleFunc("/students/export", tenants.Handle((*api.API).ExportStudents)).Methods("GET")
+	r.HandleFunc("/students/search", tenants.Handle((*api.API).SearchStudents)).Methods("GET")
+	r.HandleFunc("/students/{id}", tenants.Handle((*api.API).GetStudentByID)).Methods("GET")
+	r.HandleFunc("/students/{id}", tenants.Handle((*api.API).UpdateStudent)).Methods("PUT")
+	r.HandleFunc("/students/{id}", tenants.Handle((*api.API).DeleteStudent)).Methods("DELETE")
+	r.HandleFunc("/students/{id:[0-9]+}:restore", tenants.Handle((*api.API).RestoreStudent)).Methods("POST")
+	r.HandleFunc("/students/{id}/history", tenants.Handle((*api.API).GetStudentHistory)).Methods("GET")
+	r.HandleFunc("/students/{id}/summary", tenants.Handle((*api.API).GenerateStudentSummary)).Methods("GET")
+	r.HandleFunc("/students/{id}/advisors", tenants.Handle((*api.API).GetStudentAdvisors)).Methods("GET")
+	r.HandleFunc("/students/{id}/advisors", tenants.Handle((*api.API).AssignAdvisor)).Methods("POST")
+	r.HandleFunc("/students/{id}/advisors/{advisor}", tenants.Handle((*api.API).UnassignAdvisor)).Methods("DELETE")
+	r.HandleFunc("/students/{id}/enrollments", tenants.Handle((*api.API).GetStudentEnrollments)).Methods("GET")
+	r.HandleFunc("/students/{id}/enrollments", tenants.Handle((*api.API).CreateEnrollment)).Methods("POST")
+	r.HandleFunc("/students/{id}/enrollments/{courseId}", tenants.Handle((*api.API).UpdateEnrollment)).Methods("PUT")
+	r.HandleFunc("/students/{id}/enrollments/{courseId}", tenants.Handle((*api.API).DeleteEnrollment)).Methods("DELETE")
+	r.HandleFunc("/students/{id}/grades", tenants.Handle((*api.API).GetStudentGrades)).Methods("GET")
+	r.HandleFunc("/students/{id}/grades", tenants.Handle((*api.API).CreateGrade)).Methods("POST")
+	r.HandleFunc("/students/{id}/grades/{gradeId}", tenants.Handle((*api.API).GetGrade)).Methods("GET")
+	r.HandleFunc("/students/{id}/grades/{gradeId}", tenants.Handle((*api.API).UpdateGrade)).Methods("PUT")
+	r.HandleFunc("/students/{id}/grades/{gradeId}", tenants.Handle((*api.API).DeleteGrade)).Methods("DELETE")
+	r.HandleFunc("/students/{id}/performance", tenants.Handle((*api.API).GetStudentPerformance)).Methods("GET")
+	r.HandleFunc("/students/{id}/attendance", tenants.Handle((*api.API).GetStudentAttendance)).Methods("GET")
+	r.HandleFunc("/students/{id}/attendance", tenants.Handle((*api.API).RecordAttendance)).Methods("POST")
+	r.HandleFunc("/students/{id}/attendance/stats", tenants.Handle((*api.API).GetAttendanceStats)).Methods("GET")
+	r.HandleFunc(`/students/{id}/attendance/{date:\d{4}-\d{2}-\d{2}}`, tenants.Handle((*api.API).DeleteAttendance)).Methods("DELETE")
+	r.HandleFunc("/students/{id}/notes", tenants.Handle((*api.API).GetStudentNotes)).Methods("GET")
+	r.HandleFunc("/students/{id}/notes", tenants.Handle((*api.API).CreateNote)).Methods("POST")
+	r.HandleFunc("/students/{id}/notes/{noteId}", tenants.Handle((*api.API).GetNote)).Methods("GET")
+	r.HandleFunc("/students/{id}/notes/{noteId}", tenants.Handle((*api.API).UpdateNote)).Methods("PUT")
+	r.HandleFunc("/students/{id}/notes/{noteId}", tenants.Handle((*api.API).DeleteNote)).Methods("DELETE")
+	r.HandleFunc("/students/{id}/guardians", tenants.Handle((*api.API).GetStudentGuardians)).Methods("GET")
+	r.HandleFunc("/students/{id}/guardians", tenants.Handle((*api.API).LinkGuardian)).Methods("POST")
+	r.HandleFunc("/students/{id}/guardians/{guardianId}", tenants.Handle((*api.API).UpdateGuardianLink)).Methods("PUT")
+	r.HandleFunc("/students/{id}/guardians/{guardianId}", tenants.Handle((*api.API).UnlinkGuardian)).Methods("DELETE")
+	r.HandleFunc("/students/{id}/guardians/{guardianId}/summary", tenants.Handle((*api.API).GetGuardianSummary)).Methods("GET")
+	r.HandleFunc("/guardians", tenants.Handle((*api.API).GetAllGuardians)).Methods("GET")
+	r.HandleFunc("/guardians", tenants.Handle((*api.API).CreateGuardian)).Methods("POST")
+	r.HandleFunc("/guardians/{id}", tenants.Handle((*api.API).GetGuardian)).Methods("GET")
+	r.HandleFunc("/guardians/{id}", tenants.Handle((*api.API).UpdateGuardian)).Methods("PUT")
+	r.HandleFunc("/guardians/{id}", tenants.Handle((*api.API).DeleteGuardian)).Methods("DELETE")
+	r.HandleFunc("/guardians/{id}/students", tenants.Handle((*api.API).GetGuardianStudents)).Methods("GET")
+	r.HandleFunc("/custom-fields", tenants.Handle((*api.API).GetCustomFields)).Methods("GET")
+	r.HandleFunc("/custom-fields", tenants.Handle((*api.API).CreateCustomField)).Methods("POST")
+	r.HandleFunc("/custom-fields/{name}", tenants.Handle((*api.API).GetCustomField)).Methods("GET")
+	r.HandleFunc("/custom-fields/{name}", tenants.Handle((*api.API).UpdateCustomField)).Methods("PUT")
+	r.HandleFunc("/custom-fields/{name}", tenants.Handle((*api.API).DeleteCustomField)).Methods("DELETE")
+	r.HandleFunc("/courses", tenants.Handle((*api.API).CreateCourse)).Methods("POST")
+	r.HandleFunc("/courses", tenants.Handle((*api.API).GetAllCourses)).Methods("GET")
+	r.HandleFunc("/courses/{id}", tenants.Handle((*api.API).GetCourseByID)).Methods("GET")
+	r.HandleFunc("/courses/{id}", tenants.Handle((*api.API).UpdateCourse)).Methods("PUT")
+	r.HandleFunc("/courses/{id}", tenants.Handle((*api.API).DeleteCourse)).Methods("DELETE")
+	r.HandleFunc("/courses/{id}/attendance", tenants.Handle((*api.API).RecordClassAttendance)).Methods("POST")
+
+	r.HandleFunc("/admin/api-keys", tenants.Handle((*api.API).GetAPIKeys)).Methods("GET")
+	r.HandleFunc("/admin/api-keys", tenants.Handle((*api.API).CreateAPIKey)).Methods("POST")
+	r.HandleFunc("/admin/api-keys/{id}", tenants.Handle((*api.API).DeleteAPIKey)).Methods("DELETE")
+}
+
+// sqlRoutes registers the API backed by the database.
+func sqlRoutes(r *mux.Router, h *handlers.Handler) {
+	r.HandleFunc("/students", h.CreateStudent).Methods("POST")
+	r.HandleFunc("/students", h.GetAllStudents).Methods("GET")
+	r.HandleFunc("/students/import", h.ImportStudents).Methods("POST")
+	r.HandleFunc("/students/export", h.ExportStudents).Methods("GET")
+	r.HandleFunc("/students/search", h.SearchStudents).Methods("GET")
+	r.HandleFunc("/students/{id}", h.GetStudent).Methods("GET")
+	r.HandleFunc("/students/{id}", h.UpdateStudent).Methods("PUT")
+	r.HandleFunc("/students/{id}", h.DeleteStudent).Methods("DELETE")
+	r.HandleFunc("/students", h.DeleteStudentByIds).Methods("DELETE")
+	r.HandleFunc("/students/{id:[0-9]+}:restore", h.RestoreStudent).Methods("POST")
+	r.HandleFunc("/students/{id}/history", h.GetStudentHistory).Methods("GET")
+	r.HandleFunc("/students/{id}/summary", h.GetStudentSummary).Methods("GET")
+	r.HandleFunc("/students/{id}/advisors", h.GetStudentAdvisors).Methods("GET")
+	r.HandleFunc("/students/{id}/advisors", h.AssignAdvisor).Methods("POST")
+	r.HandleFunc("/students/{id}/advisors/{advisor}", h.UnassignAdvisor).Methods("DELETE")
+	r.HandleFunc("/students/{id}/enrollments", h.GetStudentEnrollments).Methods("GET")
+	r.HandleFunc("/students/{id}/enrollments", h.CreateEnrollment).Methods("POST")
+	r.HandleFunc("/students/{id}/enrollments/{courseId}", h.UpdateEnrollment).Methods("PUT")
+	r.HandleFunc("/students/{id}/enrollments/{courseId}", h.DeleteEnrollment).Methods("DELETE")
+	r.HandleFunc("/students/{id}/grades", h.GetStudentGrades).Methods("GET")
+	r.HandleFunc("/students/{id}/grades", h.CreateGrade).Methods("POST")
+	r.HandleFunc("/students/{id}/grades/{gradeId}", h.GetGrade).Methods("GET")
+	r.HandleFunc("/students/{id}/grades/{gradeId}", h.UpdateGrade).Methods("PUT")
+	r.HandleFunc("/students/{id}/grades/{gradeId}", h.DeleteGrade).Methods("DELETE")
+	r.HandleFunc("/students/{id}/performance", h.GetStudentPerformance).Methods("GET")
+	r.HandleFunc("/students/{id}/attendance", h.GetStudentAttendance).Methods("GET")
+	r.HandleFunc("/students/{id}/attendance", h.RecordAttendance).Methods("POST")
+	r.HandleFunc("/students/{id}/attendance/stats", h.GetAttendanceStats).Methods("GET")
+	r.HandleFunc(`/students/{id}/attendance/{date:\d{4}-\d{2}-\d{2}}`, h.DeleteAttendance).Methods("DELETE")
+	r.HandleFunc("/students/{id}/notes", h.GetStudentNotes).Methods("GET")
+	r.HandleFunc("/students/{id}/notes", h.CreateNote).Methods("POST")
+	r.HandleFunc("/students/{id}/notes/{noteId}", h.GetNote).Methods("GET")
+	r.HandleFunc("/students/{id}/notes/{noteId}", h.UpdateNote).Methods("PUT")
+	r.HandleFunc("/students/{id}/notes/{noteId}", h.DeleteNote).Methods("DELETE")
+	r.HandleFunc("/students/{id}/guardians", h.GetStudentGuardians).Methods("GET")
+	r.HandleFunc("/students/{id}/guardians", h.LinkGuardian).Methods("POST")
+	r.HandleFunc("/students/{id}/guardians/{guardianId}", h.UpdateGuardianLink).Methods("PUT")
+	r.HandleFunc("/students/{id}/guardians/{guardianId}", h.UnlinkGuardian).Methods("DELETE")
+	r.HandleFunc("/students/{id}/guardians/{guardianId}/summary", h.GetGuardianSummary).Methods("GET")
+	r.HandleFunc("/guardians", h.GetAllGuardians).Methods("GET")
+	r.HandleFunc("/guardians", h.CreateGuardian).Methods("POST")
+	r.HandleFunc("/guardians/{id}", h.GetGuardian).Methods("GET")
+	r.HandleFunc("/guardians/{id}", h.UpdateGuardian).Methods("PUT")
+	r.HandleFunc("/guardians/{id}", h.DeleteGuardian).Methods("DELETE")
+	r.HandleFunc("/guardians/{id}/students", h.GetGuardianStudents).Methods("GET")
+	r.HandleFunc("/custom-fields", h.GetCustomFields).Methods("GET")
+	r.HandleFunc("/custom-fields", h.CreateCustomField).Methods("POST")
+	r.HandleFunc("/custom-fields/{name}", h.GetCustomField).Methods("GET")
+	r.HandleFunc("/custom-fields/{name}", h.UpdateCustomField).Methods("PUT")
+	r.HandleFunc("/custom-fields/{name}", h.DeleteCustomField).Methods("DELETE")
+	r.HandleFunc("/courses", h.CreateCourse).Methods("POST")
+	r.HandleFunc("/courses", h.GetAllCourses).Methods("GET")
+	r.HandleFunc("/courses/{id}", h.GetCourse).Methods("GET")
+	r.HandleFunc("/courses/{id}", h.UpdateCourse).Methods("PUT")
+	r.HandleFunc("/courses/{id}", h.DeleteCourse).Methods("DELETE")
+	r.HandleFunc("/courses/{id}/attendance", h.RecordClassAttendance).Methods("POST")
+	r.HandleFunc("/metrics", h.GetMetrics).Methods("GET")
+
+	r.HandleFunc("/admin/api-keys", h.GetAPIKeys).Methods("GET")
+	r.HandleFunc("/admin/api-keys", h.CreateAPIKey).Methods("POST")
+	r.HandleFunc("/admin/api-keys/{id}", h.DeleteAPIKey).Methods("DELETE")
+}
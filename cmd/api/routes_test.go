@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/handlers"
+	api "github.com/AashishKumar-3002/FealtyX/internal/memory"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+	"github.com/AashishKumar-3002/FealtyX/internal/storage"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	adminKey   = "fx_admin"
+	advisorKey = "fx_advisor"
+	viewerKey  = "fx_viewer"
+)
+
+// testKeys looks up the advisor and viewer keys; the admin key is the
+// deployment's.
+func testKeys(_ context.Context, hash string) (models.APIKey, error) {
+	switch hash {
+	case auth.HashAPIKey(advisorKey):
+		return models.APIKey{Name: "adv", Role: string(auth.RoleAdvisor), Tenant: tenant.Default}, nil
+	case auth.HashAPIKey(viewerKey):
+		return models.APIKey{Name: "view", Role: string(auth.RoleViewer), Tenant: tenant.Default}, nil
+	}
+	return models.APIKey{}, apperror.NotFound("no such key")
+}
+
+// testRouters returns a router for each backend, with the middleware main
+// puts in front of them.
+func testRouters(t *testing.T) map[string]*mux.Router {
+	t.Helper()
+	authenticator := auth.New(auth.Config{AdminKey: adminKey, Keys: testKeys})
+	dir := tenant.NewDirectory()
+
+	tenants := api.NewTenants(func(string) (*storage.Storage, error) {
+		return storage.NewStorage(), nil
+	}, nil)
+	memory := mux.NewRouter()
+	memory.Use(authenticator.Middleware)
+	memory.Use(middleware.Tenant(dir))
+	memory.Use(auth.NewAuthorizer(tenants.IsAssigned).Middleware)
+	memoryRoutes(memory, tenants)
+
+	db, err := database.Connect("sqlite://"+filepath.Join(t.TempDir(), "test.db"), database.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	sql := mux.NewRouter()
+	sql.Use(authenticator.Middleware)
+	sql.Use(middleware.Tenant(dir))
+	sql.Use(middleware.TenantStudents(func(ctx context.Context, studentID int) (bool, error) {
+		return models.StudentInTenant(ctx, db, studentID)
+	}))
+	sql.Use(auth.NewAuthorizer(func(ctx context.Context, advisor string, studentID int) (bool, error) {
+		return models.IsAssigned(ctx, db, advisor, studentID)
+	}).Middleware)
+	sqlRoutes(sql, handlers.NewHandler(db))
+
+	return map[string]*mux.Router{"memory": memory, "sql": sql}
+}
+
+func TestEveryRouteHasAPermission(t *testing.T) {
+	for name, r := range testRouters(t) {
+		err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				return err
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return fmt.Errorf("%s: %v", template, err)
+			}
+			for _, method := range methods {
+				if _, ok := auth.RoutePermission(method, template); !ok {
+					t.Errorf("%s: %s %s has no permission, so no role may use it", name, method, template)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+// call makes a request with key and decodes the response into out, if it
+// is not nil and the request succeeded.
+func call(t *testing.T, r http.Handler, key, method, path string, body interface{}, out interface{}) int {
+	t.Helper()
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("X-API-Key", key)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if out != nil && rr.Code < 300 {
+		if err := json.Unmarshal(rr.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %s: %v", method, path, rr.Body, err)
+		}
+	}
+	return rr.Code
+}
+
+func TestRolesAreKeptToTheirPermissions(t *testing.T) {
+	for name, r := range testRouters(t) {
+		t.Run(name, func(t *testing.T) {
+			// Alice is assigned to the advisor and Bob is not; they share a
+			// guardian.
+			var alice, bob models.Student
+			for _, s := range []*models.Student{&alice, &bob} {
+				email := fmt.Sprintf("rbac.%p@example.com", s)
+				if code := call(t, r, adminKey, "POST", "/students", map[string]interface{}{"name": "Rbac Student", "age": 20, "email": email}, s); code != http.StatusCreated {
+					t.Fatalf("creating a student returned %d", code)
+				}
+			}
+			if code := call(t, r, adminKey, "POST", fmt.Sprintf("/students/%d/advisors", alice.ID), map[string]string{"advisor": "api-key:adv"}, nil); code >= 300 {
+				t.Fatalf("assigning the advisor returned %d", code)
+			}
+			var guardian models.Guardian
+			if code := call(t, r, adminKey, "POST", "/guardians", map[string]string{
+				"name": "Rbac Guardian", "email": "rbac.guardian@example.com", "preferred_contact": "email", "preferred_language": "en",
+			}, &guardian); code != http.StatusCreated {
+				t.Fatalf("creating a guardian returned %d", code)
+			}
+			for _, s := range []models.Student{alice, bob} {
+				link := map[string]interface{}{"guardian_id": guardian.ID, "relationship": "parent"}
+				if code := call(t, r, adminKey, "POST", fmt.Sprintf("/students/%d/guardians", s.ID), link, nil); code >= 300 {
+					t.Fatalf("linking the guardian returned %d", code)
+				}
+			}
+
+			var course models.Course
+			if code := call(t, r, adminKey, "POST", "/courses", map[string]string{"code": "RBAC1", "title": "Rbac Course"}, &course); code != http.StatusCreated {
+				t.Fatalf("creating a course returned %d", code)
+			}
+			attendance := func(students ...models.Student) map[string]interface{} {
+				var records []map[string]interface{}
+				for _, s := range students {
+					records = append(records, map[string]interface{}{"student_id": s.ID, "status": "present"})
+				}
+				return map[string]interface{}{"date": "2024-10-01", "records": records}
+			}
+			classAttendance := fmt.Sprintf("/courses/%d/attendance", course.ID)
+
+			for _, tc := range []struct {
+				key, method, path string
+				body              interface{}
+				want              int
+			}{
+				{viewerKey, "GET", fmt.Sprintf("/students/%d", bob.ID), nil, http.StatusOK},
+				{viewerKey, "POST", "/students", map[string]interface{}{"name": "Viewer Student", "age": 20, "email": "viewer@example.com"}, http.StatusForbidden},
+				{viewerKey, "PUT", fmt.Sprintf("/students/%d", alice.ID), map[string]interface{}{"name": "Changed Name", "age": 21, "email": "changed@example.com"}, http.StatusForbidden},
+				{viewerKey, "DELETE", fmt.Sprintf("/students/%d", alice.ID), nil, http.StatusForbidden},
+				{viewerKey, "POST", fmt.Sprintf("/students/%d/notes", alice.ID), map[string]string{"content": "note"}, http.StatusForbidden},
+				{viewerKey, "POST", "/courses", map[string]string{"code": "V1", "title": "Viewer Course"}, http.StatusForbidden},
+				{viewerKey, "GET", "/admin/api-keys", nil, http.StatusForbidden},
+
+				{advisorKey, "GET", fmt.Sprintf("/students/%d", alice.ID), nil, http.StatusOK},
+				{advisorKey, "GET", fmt.Sprintf("/students/%d/history", alice.ID), nil, http.StatusOK},
+				{advisorKey, "GET", fmt.Sprintf("/students/%d", bob.ID), nil, http.StatusForbidden},
+				{advisorKey, "GET", fmt.Sprintf("/students/%d/history", bob.ID), nil, http.StatusForbidden},
+				{advisorKey, "GET", fmt.Sprintf("/students/%d/guardians", bob.ID), nil, http.StatusForbidden},
+				{advisorKey, "PUT", fmt.Sprintf("/students/%d", bob.ID), map[string]interface{}{"name": "Changed Name", "age": 21, "email": "changed@example.com"}, http.StatusForbidden},
+				{advisorKey, "POST", "/students", map[string]interface{}{"name": "Advisor Student", "age": 20, "email": "advisor@example.com"}, http.StatusForbidden},
+				{advisorKey, "POST", fmt.Sprintf("/students/%d/advisors", bob.ID), map[string]string{"advisor": "api-key:adv"}, http.StatusForbidden},
+				// A class's attendance may be taken by an advisor of every
+				// student in it.
+				{viewerKey, "POST", classAttendance, attendance(alice), http.StatusForbidden},
+				{advisorKey, "POST", classAttendance, attendance(alice), http.StatusOK},
+				{advisorKey, "POST", classAttendance, attendance(alice, bob), http.StatusForbidden},
+				{adminKey, "POST", classAttendance, attendance(alice, bob), http.StatusOK},
+			} {
+				if code := call(t, r, tc.key, tc.method, tc.path, tc.body, nil); code != tc.want {
+					t.Errorf("%s %s %s returned %d, want %d", tc.key, tc.method, tc.path, code, tc.want)
+				}
+			}
+
+			// The advisor's listings hold Alice only.
+			var listed []models.Student
+			call(t, r, advisorKey, "GET", "/students", nil, &listed)
+			if len(listed) != 1 || listed[0].ID != alice.ID {
+				t.Errorf("the advisor listed %+v, want only student %d", listed, alice.ID)
+			}
+			var links []models.StudentGuardian
+			call(t, r, advisorKey, "GET", fmt.Sprintf("/guardians/%d/students", guardian.ID), nil, &links)
+			if len(links) != 1 || links[0].StudentID != alice.ID {
+				t.Errorf("the advisor saw the guardian's students %+v, want only student %d", links, alice.ID)
+			}
+
+			req := httptest.NewRequest("GET", "/students/export?format=ndjson", nil)
+			req.Header.Set("X-API-Key", advisorKey)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+			var exported models.Student
+			if rr.Code != http.StatusOK || len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &exported) != nil || exported.ID != alice.ID {
+				t.Errorf("the advisor exported %d %s, want only student %d", rr.Code, rr.Body, alice.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+)
+
+// loadTenants returns the tenants the deployment serves: those in
+// TENANTS_FILE, along with the default tenant, any tenant if TENANTS_OPEN
+// is set, or else only the default tenant.
+func loadTenants() *tenant.Directory {
+	open := false
+	if v := os.Getenv("TENANTS_OPEN"); v != "" {
+		var err error
+		if open, err = strconv.ParseBool(v); err != nil {
+			log.Fatalf("invalid TENANTS_OPEN: %v", err)
+		}
+	}
+
+	path := os.Getenv("TENANTS_FILE")
+	switch {
+	case path != "" && open:
+		log.Fatal("set TENANTS_FILE or TENANTS_OPEN, not both")
+	case open:
+		log.Println("TENANTS_OPEN is set, any tenant a request names is served")
+		return tenant.NewOpenDirectory()
+	case path == "":
+		return tenant.NewDirectory()
+	}
+	dir, err := tenant.LoadDirectory(path)
+	if err != nil {
+		log.Fatalf("invalid TENANTS_FILE: %v", err)
+	}
+	log.Printf("Serving tenants %v", dir.IDs())
+	return dir
+}
@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+const (
+	// defaultContextTokens matches Ollama's default num_ctx.
+	defaultContextTokens = 2048
+	// charsPerToken is a rough estimate used to size prompts without a
+	// tokenizer.
+	charsPerToken = 4
+	// maxReduceRounds bounds how many times chunk summaries are merged.
+	maxReduceRounds = 5
+)
+
+const condenseInstruction = "Condense the following advisor notes about a student into a short paragraph. " +
+	"Keep concrete facts, concerns and recommendations; drop repetition.\n\n"
+
+// generateFunc sends a prompt to the model. It is a variable so that the
+// chunking logic can be exercised without Ollama.
+var generateFunc = generate
+
+// contextTokens is the model's context window, from OLLAMA_CONTEXT_TOKENS.
+func contextTokens() int {
+	if n, err := strconv.Atoi(os.Getenv("OLLAMA_CONTEXT_TOKENS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultContextTokens
+}
+
+// noteBudget is how many characters of notes fit in one prompt, leaving half
+// the context window for the instructions, the rest of the prompt and the
+// response.
+func noteBudget() int {
+	return contextTokens() * charsPerToken / 2
+}
+
+func formatNote(n models.Note) string {
+	return fmt.Sprintf("[%s, %s] %s", n.CreatedAt.Format(models.DateLayout), n.Author, n.Body)
+}
+
+// CondenseNotes reduces a student's notes to text that fits in the summary
+// prompt. Notes that already fit are returned as is; otherwise they are
+// split into chunks that are summarized separately (map) and the summaries
+// are merged until they fit (reduce).
+func CondenseNotes(ctx context.Context, notes []models.Note) (string, error) {
+	texts := make([]string, len(notes))
+	for i, n := range notes {
+		texts[i] = formatNote(n)
+	}
+
+	budget := noteBudget()
+	for round := 0; ; round++ {
+		joined := strings.Join(texts, "\n")
+		if len(joined) <= budget {
+			return joined, nil
+		}
+		if round == maxReduceRounds {
+			head, _ := splitAt(joined, budget)
+			return head, nil
+		}
+
+		chunks := chunkTexts(texts, budget)
+		summaries := make([]string, 0, len(chunks))
+		for _, chunk := range chunks {
+			summary, err := generateFunc(ctx, condenseInstruction+chunk)
+			if err != nil {
+				return "", err
+			}
+			summaries = append(summaries, strings.TrimSpace(summary))
+		}
+		texts = summaries
+	}
+}
+
+// chunkTexts packs texts into chunks of at most budget characters, splitting
+// any single text that is longer than that.
+func chunkTexts(texts []string, budget int) []string {
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, text := range texts {
+		for len(text) > budget {
+			flush()
+			var head string
+			head, text = splitAt(text, budget)
+			chunks = append(chunks, head)
+		}
+		if current.Len()+len(text)+1 > budget {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(text)
+	}
+	flush()
+	return chunks
+}
+
+// splitAt splits s at or just before byte n without breaking a UTF-8
+// character.
+func splitAt(s string, n int) (string, string) {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n], s[n:]
+}
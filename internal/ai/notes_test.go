@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+func TestChunkTexts(t *testing.T) {
+	for _, tc := range []struct {
+		texts  []string
+		budget int
+		want   []string
+	}{
+		{[]string{"abc", "def"}, 10, []string{"abc\ndef"}},
+		{[]string{"abc", "def", "ghijklmnopqrstu"}, 10, []string{"abc\ndef", "ghijklmnop", "qrstu"}},
+		{[]string{"abcde", "fghij"}, 10, []string{"abcde", "fghij"}},
+		// Long texts are not split inside a character.
+		{[]string{"ééééé"}, 5, []string{"éé", "éé", "é"}},
+	} {
+		got := chunkTexts(tc.texts, tc.budget)
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("chunkTexts(%q, %d) = %q, want %q", tc.texts, tc.budget, got, tc.want)
+		}
+		for _, chunk := range got {
+			if len(chunk) > tc.budget || !utf8.ValidString(chunk) {
+				t.Errorf("chunkTexts(%q, %d) made chunk %q", tc.texts, tc.budget, chunk)
+			}
+		}
+	}
+}
+
+// stubGenerate replaces the model for the duration of the test and returns
+// the prompts it is sent.
+func stubGenerate(t *testing.T, fn func(prompt string) (string, error)) *[]string {
+	t.Helper()
+	var prompts []string
+	saved := generateFunc
+	generateFunc = func(_ context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return fn(prompt)
+	}
+	t.Cleanup(func() { generateFunc = saved })
+	return &prompts
+}
+
+func testNotes(n int) []models.Note {
+	notes := make([]models.Note, n)
+	for i := range notes {
+		notes[i] = models.Note{
+			Author:    "advisor",
+			Body:      fmt.Sprintf("note %d about the student", i),
+			CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	return notes
+}
+
+func TestCondenseNotes(t *testing.T) {
+	// A budget of 100 characters.
+	t.Setenv("OLLAMA_CONTEXT_TOKENS", "50")
+	ctx := context.Background()
+
+	t.Run("notes that fit are kept", func(t *testing.T) {
+		prompts := stubGenerate(t, func(string) (string, error) { return "summary", nil })
+		got, err := CondenseNotes(ctx, testNotes(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "[2024-09-01, advisor] note 0 about the student\n[2024-09-01, advisor] note 1 about the student"
+		if got != want || len(*prompts) != 0 {
+			t.Errorf("got %q after %d prompts, want %q without any", got, len(*prompts), want)
+		}
+	})
+
+	t.Run("chunks are summarized", func(t *testing.T) {
+		prompts := stubGenerate(t, func(string) (string, error) { return " summary ", nil })
+		got, err := CondenseNotes(ctx, testNotes(10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(*prompts) < 2 {
+			t.Fatalf("expected the notes to be split, got %d prompts", len(*prompts))
+		}
+		for _, prompt := range *prompts {
+			chunk, ok := strings.CutPrefix(prompt, condenseInstruction)
+			if !ok || len(chunk) > noteBudget() {
+				t.Errorf("unexpected prompt %q", prompt)
+			}
+		}
+		if want := strings.TrimSuffix(strings.Repeat("summary\n", len(*prompts)), "\n"); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("summaries that do not shrink are cut off", func(t *testing.T) {
+		prompts := stubGenerate(t, func(string) (string, error) { return strings.Repeat("x", 200), nil })
+		got, err := CondenseNotes(ctx, testNotes(10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != noteBudget() {
+			t.Errorf("expected %d characters, got %d", noteBudget(), len(got))
+		}
+		if len(*prompts) == 0 {
+			t.Error("expected the notes to be summarized")
+		}
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		failure := errors.New("model unavailable")
+		stubGenerate(t, func(string) (string, error) { return "", failure })
+		if _, err := CondenseNotes(ctx, testNotes(10)); !errors.Is(err, failure) {
+			t.Errorf("expected %v, got %v", failure, err)
+		}
+	})
+}
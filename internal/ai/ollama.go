@@ -2,6 +2,8 @@ package ai
 
 import (
 	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +11,9 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
 	"github.com/AashishKumar-3002/FealtyX/internal/models"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
 	"github.com/joho/godotenv"
 )
 
@@ -32,7 +36,55 @@ type GenerateResponse struct {
      EvalDuration int64 `json:"eval_duration"` }
 
 
-func GenerateStudentSummary(student models.Student) (string, error) {
+// defaultModel generates summaries unless OLLAMA_MODEL or the tenant's
+// settings name another model.
+const defaultModel = "llama3.2:1b"
+
+// GenerateStudentSummary asks Ollama for a summary of everything known about
+// the student, following the summary prompt of the tenant of ctx. It gives
+// up when ctx ends.
+func GenerateStudentSummary(ctx context.Context, sc SummaryContext) (string, error) {
+	var notes string
+	if len(sc.Notes) > 0 {
+		var err error
+		if notes, err = CondenseNotes(ctx, sc.Notes); err != nil {
+			return "", err
+		}
+	}
+	t, _ := tenant.FromContext(ctx)
+	return generateFunc(ctx, withInstructions(buildSummaryPrompt(sc, notes), t.Settings.SummaryPrompt))
+}
+
+// GenerateGuardianSummary asks Ollama for an update on the student written
+// for one of their guardians, in the guardian's preferred language and
+// following the guardian prompt of the tenant of ctx. Advisor notes are
+// internal and are left out.
+func GenerateGuardianSummary(ctx context.Context, sc SummaryContext, link models.StudentGuardian) (string, error) {
+	t, _ := tenant.FromContext(ctx)
+	return generateFunc(ctx, withInstructions(buildGuardianPrompt(sc, link), t.Settings.GuardianPrompt))
+}
+
+// upstreamError describes a failed exchange with Ollama, telling a deadline
+// or a client that went away apart from Ollama failing.
+func upstreamError(ctx context.Context, err error, message string) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return apperror.Timeout(err, "summary service did not answer in time")
+	case context.Canceled:
+		return err
+	}
+	return apperror.Upstream(err, message)
+}
+
+// model returns the model that generates text for the tenant of ctx.
+func model(ctx context.Context) string {
+	t, _ := tenant.FromContext(ctx)
+	return cmp.Or(t.Settings.OllamaModel, os.Getenv("OLLAMA_MODEL"), defaultModel)
+}
+
+// generate sends a single non-streaming prompt to Ollama, to the model of
+// the tenant of ctx.
+func generate(ctx context.Context, prompt string) (string, error) {
 
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
@@ -45,10 +97,9 @@ func GenerateStudentSummary(student models.Student) (string, error) {
 		ollamaPort = "11434" // Default port if not set
 	}
 
-    prompt := fmt.Sprintf("Generate a brief summary for a student named %s, who is %d years old and has the email %s.", student.Name, student.Age, student.Email)
 	ollamaURL := fmt.Sprintf("http://localhost:%s/api/generate", ollamaPort)
 	requestBody := GenerateRequest{ 
-		Model: "llama3.2:1b", 
+		Model: model(ctx), 
 		Prompt: prompt, 
 		Stream: false, 
 	} 
@@ -57,7 +108,7 @@ func GenerateStudentSummary(student models.Student) (string, error) {
 		return "", fmt.Errorf("error marshalling request body: %v", err) 
 	}
 
-	req, err := http.NewRequest("POST", ollamaURL, bytes.NewBuffer(jsonStr))
+	req, err := http.NewRequestWithContext(ctx, "POST", ollamaURL, bytes.NewBuffer(jsonStr))
 	if err != nil { 
 		return "", fmt.Errorf("error creating request: %v", err) 
 	} 
@@ -66,19 +117,23 @@ func GenerateStudentSummary(student models.Student) (string, error) {
     client := &http.Client{} 
     resp, err := client.Do(req) 
     if err != nil { 
-        return "", fmt.Errorf("error making request: %v", err) 
+        return "", upstreamError(ctx, err, "summary service is unavailable")
     } 
     defer resp.Body.Close()
 
     body, err := io.ReadAll(resp.Body)
     if err != nil {
-        return "", fmt.Errorf("error reading response: %v", err)
+        return "", upstreamError(ctx, err, "error reading summary response")
+    }
+
+    if resp.StatusCode != http.StatusOK {
+        return "", apperror.Upstream(fmt.Errorf("ollama returned %s: %s", resp.Status, body), "summary service returned an error")
     }
 
     var generateResponse GenerateResponse 
     err = json.Unmarshal(body, &generateResponse) 
     if err != nil { 
-        return "", fmt.Errorf("error unmarshalling response: %v", err) 
+        return "", apperror.Upstream(err, "error decoding summary response") 
     } 
     return generateResponse.Response, nil
 
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// SummaryContext is everything known about a student that a summary can
+// draw on.
+type SummaryContext struct {
+	Student models.Student
+	// CustomFields label the student's attributes.
+	CustomFields []models.CustomField
+	Enrollments  []models.Enrollment
+	Grades       []models.Grade
+	Attendance   []models.AttendanceRecord
+	// Notes are condensed with CondenseNotes before being added to the
+	// prompt. Leave empty to summarize without them.
+	Notes []models.Note
+}
+
+// buildSummaryPrompt describes the student. condensedNotes is the output of
+// CondenseNotes for sc.Notes.
+func buildSummaryPrompt(sc SummaryContext, condensedNotes string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Generate a brief summary for a student named %s, who is %d years old and has the email %s.",
+		sc.Student.Name, sc.Student.Age, sc.Student.Email)
+	writeRecords(&b, sc)
+
+	if condensedNotes != "" {
+		b.WriteString("\nAdvisor notes:\n")
+		b.WriteString(condensedNotes)
+		b.WriteString("\nInclude the advisors' observations in the summary.\n")
+	}
+	return b.String()
+}
+
+// buildGuardianPrompt asks for an update addressed to a guardian of the
+// student, in the guardian's preferred language.
+func buildGuardianPrompt(sc SummaryContext, link models.StudentGuardian) string {
+	var b strings.Builder
+	guardian := link.Guardian
+	fmt.Fprintf(&b, "Write a short, warm update for %s, the %s of a student named %s, who is %d years old.",
+		guardian.Name, link.Relationship, sc.Student.Name, sc.Student.Age)
+	writeRecords(&b, sc)
+
+	fmt.Fprintf(&b, "\nAddress %s directly and write in plain language a parent can follow, without grading jargon. "+
+		"Mention what is going well, anything that needs attention at home, and end with a suggestion of how they can help.\n",
+		guardian.Name)
+	fmt.Fprintf(&b, "Write the entire update in %s.\n", languageName(guardian.PreferredLanguage))
+	return b.String()
+}
+
+// withInstructions adds a school's own instructions, if any, to the end of
+// a prompt.
+func withInstructions(prompt, instructions string) string {
+	if instructions == "" {
+		return prompt
+	}
+	return prompt + "\nThe school asks you to follow these instructions as well:\n" + instructions + "\n"
+}
+
+// writeRecords describes the student's attributes, courses, grades and
+// attendance.
+func writeRecords(b *strings.Builder, sc SummaryContext) {
+	if len(sc.Student.Attributes) > 0 {
+		writeAttributes(b, sc.Student.Attributes, sc.CustomFields)
+	}
+	if len(sc.Enrollments) > 0 {
+		b.WriteString("\n\nCourse enrollments:\n")
+		for _, e := range sc.Enrollments {
+			if e.Course == nil {
+				continue
+			}
+			fmt.Fprintf(b, "- %s %s (%d credits): %s\n", e.Course.Code, e.Course.Title, e.Course.Credits, e.Status)
+		}
+	}
+	if len(sc.Grades) > 0 {
+		writePerformance(b, sc.Grades)
+	}
+	if len(sc.Attendance) > 0 {
+		writeAttendance(b, sc.Attendance)
+	}
+}
+
+// languageNames spells out common language tags so the model does not have
+// to interpret them.
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"tl": "Tagalog",
+	"uk": "Ukrainian",
+	"vi": "Vietnamese",
+	"zh": "Chinese",
+}
+
+// languageName names the language of a BCP 47 tag such as pt-BR, falling
+// back to the tag itself.
+func languageName(tag string) string {
+	base, region, _ := strings.Cut(tag, "-")
+	name, ok := languageNames[strings.ToLower(base)]
+	if !ok {
+		return fmt.Sprintf("the language with the BCP 47 tag %s", tag)
+	}
+	if region != "" {
+		return fmt.Sprintf("%s (%s)", name, tag)
+	}
+	return name
+}
+
+// writeAttributes lists the student's custom field values in the order the
+// fields were defined.
+func writeAttributes(b *strings.Builder, attrs map[string]interface{}, fields []models.CustomField) {
+	b.WriteString("\n\nOther details:\n")
+	for _, f := range fields {
+		if value, ok := attrs[f.Name]; ok {
+			fmt.Fprintf(b, "- %s: %v\n", f.DisplayName(), value)
+		}
+	}
+}
+
+func writeAttendance(b *strings.Builder, records []models.AttendanceRecord) {
+	stats := models.CalculateAttendanceStats(records, models.DateRange{})
+	fmt.Fprintf(b, "\nAttendance from %s to %s: %.1f%% attended over %d days (%d present, %d late, %d absent, %d excused).\n",
+		records[0].Date, records[len(records)-1].Date, stats.AttendanceRate, stats.TotalDays,
+		stats.PresentDays, stats.LateDays, stats.AbsentDays, stats.ExcusedDays)
+	fmt.Fprintf(b, "Current attendance streak: %d days; longest run of absences: %d days.\n",
+		stats.CurrentStreak, stats.LongestAbsenceStreak)
+	if stats.ChronicallyAbsent {
+		b.WriteString("The student is chronically absent and the summary should mention it.\n")
+	}
+}
+
+// writePerformance describes the student's academic standing so the summary
+// can comment on it.
+func writePerformance(b *strings.Builder, grades []models.Grade) {
+	p := models.CalculatePerformance(grades)
+	fmt.Fprintf(b, "\nAcademic performance: cumulative GPA %.2f over %d credits, trend %s.\n",
+		p.CumulativeGPA, p.TotalCredits, strings.ReplaceAll(p.Trend, "_", " "))
+	for _, t := range p.Terms {
+		fmt.Fprintf(b, "- %s: GPA %.2f, average score %.1f across %d courses\n", t.Term, t.GPA, t.AverageScore, t.Courses)
+	}
+
+	b.WriteString("Grades:\n")
+	for _, g := range grades {
+		course := fmt.Sprintf("course %d", g.CourseID)
+		if g.Course != nil {
+			course = g.Course.Code + " " + g.Course.Title
+		}
+		fmt.Fprintf(b, "- %s, %s: %s (%.1f)\n", g.Term, course, g.LetterGrade, g.Score)
+	}
+	b.WriteString("Describe the student's academic standing and how it is changing over time.\n")
+}
@@ -0,0 +1,107 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error so it can be mapped to an HTTP status.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindUpstream
+	KindTimeout
+	KindCanceled
+	KindUnauthorized
+	KindForbidden
+)
+
+// FieldError describes a single invalid field in a request body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error is the typed error returned by models, storage and ai so that both
+// backends surface the same failures the same way.
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func BadRequest(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(message string, fields []FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+// Unauthorized rejects a request that did not prove who made it.
+func Unauthorized(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden rejects a request its maker is not allowed to make.
+func Forbidden(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// Upstream wraps a failure talking to an external dependency such as Ollama.
+func Upstream(err error, format string, args ...interface{}) *Error {
+	return &Error{Kind: KindUpstream, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// Timeout wraps a failure caused by a deadline passing, such as a database
+// query that ran past its timeout.
+func Timeout(err error, format string, args ...interface{}) *Error {
+	return &Error{Kind: KindTimeout, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// KindOf reports the kind of err, or KindInternal if err is not an *Error.
+// A bare context error counts as a timeout or a cancellation.
+func KindOf(err error) Kind {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e.Kind
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	}
+	return KindInternal
+}
+
+// Is reports whether err is an *Error of the given kind.
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
@@ -0,0 +1,102 @@
+package apperror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+const problemContentType = "application/problem+json"
+
+// StatusClientClosedRequest is nginx's non-standard status for a request
+// whose client went away before it was answered. Nobody reads the response,
+// but it keeps such requests apart from failures in the logs.
+const StatusClientClosedRequest = 499
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type problemType struct {
+	status int
+	slug   string
+}
+
+var problemTypes = map[Kind]problemType{
+	KindInternal:     {http.StatusInternalServerError, "internal"},
+	KindBadRequest:   {http.StatusBadRequest, "bad-request"},
+	KindValidation:   {http.StatusUnprocessableEntity, "validation"},
+	KindNotFound:     {http.StatusNotFound, "not-found"},
+	KindConflict:     {http.StatusConflict, "conflict"},
+	KindUpstream:     {http.StatusBadGateway, "upstream"},
+	KindTimeout:      {http.StatusGatewayTimeout, "timeout"},
+	KindCanceled:     {StatusClientClosedRequest, "canceled"},
+	KindUnauthorized: {http.StatusUnauthorized, "unauthorized"},
+	KindForbidden:    {http.StatusForbidden, "forbidden"},
+}
+
+// statusText is http.StatusText, extended to StatusClientClosedRequest.
+func statusText(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+// Status returns the HTTP status code for err.
+func Status(err error) int {
+	return problemTypes[KindOf(err)].status
+}
+
+// NewProblem builds the problem body for err. Internal errors are not
+// described to the client; their detail only goes to the log.
+func NewProblem(r *http.Request, err error) Problem {
+	kind := KindOf(err)
+	pt := problemTypes[kind]
+	p := Problem{
+		Type:     "/problems/" + pt.slug,
+		Title:    statusText(pt.status),
+		Status:   pt.status,
+		Instance: r.URL.Path,
+	}
+
+	var e *Error
+	switch {
+	case kind == KindInternal:
+		p.Detail = "an unexpected error occurred"
+	case kind == KindUpstream && errors.As(err, &e):
+		p.Detail = e.Message
+	case errors.As(err, &e):
+		p.Detail = e.Message
+		p.Errors = e.Fields
+	case kind == KindTimeout:
+		p.Detail = "the request timed out"
+	case kind == KindCanceled:
+		p.Detail = "the client closed the request"
+	}
+	return p
+}
+
+// Write renders err as an application/problem+json response. Once the
+// client has gone away, failures are put down to that rather than reported
+// as errors of the server's own.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(r.Context().Err(), context.Canceled) && Status(err) >= http.StatusInternalServerError {
+		err = &Error{Kind: KindCanceled, Message: "the client closed the request", Err: err}
+	}
+	p := NewProblem(r, err)
+	if p.Status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
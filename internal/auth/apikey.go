@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// apiKeyPrefix starts every generated key, which makes leaked keys easy to
+// search for.
+const apiKeyPrefix = "fx_"
+
+// shownKeyLength is how much of a key is kept in the clear, to tell keys
+// apart in listings.
+const shownKeyLength = len(apiKeyPrefix) + 8
+
+// NewAPIKey generates a random API key. It returns the key, which is only
+// ever shown to whoever asked for it, along with the start of it and the
+// hash that are stored.
+func NewAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:shownKeyLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hash an API key is stored and looked up by. Keys
+// are random and long, so a plain SHA-256 is as good as a slow password
+// hash, and it lets a key be found by its hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// isJWT reports whether a bearer token is a JWT rather than an API key: a
+// JWT has three parts separated by dots, which base64url never holds.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
@@ -0,0 +1,138 @@
+// Package auth authenticates requests with API keys and JWT bearer tokens,
+// passes who made them down in the request context and checks that their
+// role allows them.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// Ways a principal can authenticate.
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// Principal is who made a request.
+type Principal struct {
+	// Subject names the principal in the audit log: "api-key:" followed by
+	// the key's name, or the sub claim of a token.
+	Subject string `json:"subject"`
+	Method  string `json:"method"`
+	Role    Role   `json:"role"`
+	// Tenant is the tenant the principal belongs to and may only act for.
+	// It is empty for the bootstrap admin key, which acts for any tenant.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type contextKey int
+
+const principalKey contextKey = iota
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// FromContext returns the principal set by the middleware, or nil if the
+// request was not authenticated.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
+
+// KeyLookup finds the stored API key with the given hash, of any tenant,
+// failing with a not found error if there is none.
+type KeyLookup func(ctx context.Context, hash string) (models.APIKey, error)
+
+// Config is how requests may authenticate.
+type Config struct {
+	// AdminKey is an admin API key that is not stored anywhere, for
+	// creating the first stored keys of each tenant. Empty turns it off.
+	AdminKey string
+	// Keys looks up stored API keys.
+	Keys KeyLookup
+	// JWT verifies bearer tokens; nil rejects them.
+	JWT *JWTVerifier
+}
+
+// Authenticator checks the credentials of requests.
+type Authenticator struct {
+	adminKeyHash string
+	keys         KeyLookup
+	jwt          *JWTVerifier
+}
+
+func New(cfg Config) *Authenticator {
+	a := &Authenticator{keys: cfg.Keys, jwt: cfg.JWT}
+	if cfg.AdminKey != "" {
+		a.adminKeyHash = HashAPIKey(cfg.AdminKey)
+	}
+	return a
+}
+
+// Middleware answers requests without valid credentials with 401 and passes
+// the principal of the others down in their context. An API key is sent in
+// the X-API-Key header or as a bearer token, and a JWT as a bearer token.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.authenticate(r)
+		if err != nil {
+			if apperror.Is(err, apperror.KindUnauthorized) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="fealtyx"`)
+			}
+			apperror.Write(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return a.apiKey(r.Context(), key)
+	}
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, apperror.Unauthorized("send an API key in the %s header or a bearer token", apiKeyHeader)
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, apperror.Unauthorized("the Authorization header must hold a bearer token")
+	}
+	if !isJWT(token) {
+		return a.apiKey(r.Context(), token)
+	}
+	if a.jwt == nil {
+		return nil, apperror.Unauthorized("bearer tokens are not accepted, only API keys")
+	}
+	claims, err := a.jwt.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{Subject: claims.Subject, Method: MethodJWT, Role: claims.Role, Tenant: claims.Tenant}, nil
+}
+
+func (a *Authenticator) apiKey(ctx context.Context, key string) (*Principal, error) {
+	hash := HashAPIKey(key)
+	if a.adminKeyHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(a.adminKeyHash)) == 1 {
+		return &Principal{Subject: "api-key:admin", Method: MethodAPIKey, Role: RoleAdmin}, nil
+	}
+	stored, err := a.keys(ctx, hash)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return nil, apperror.Unauthorized("invalid API key")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{Subject: "api-key:" + stored.Name, Method: MethodAPIKey, Role: Role(stored.Role), Tenant: stored.Tenant}, nil
+}
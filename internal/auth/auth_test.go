@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// keyStore is a KeyLookup over keys held in memory, by hash.
+type keyStore struct {
+	mu   sync.Mutex
+	keys map[string]models.APIKey
+}
+
+func (s *keyStore) lookup(_ context.Context, hash string) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[hash]
+	if !ok {
+		return models.APIKey{}, apperror.NotFound("no such key")
+	}
+	return key, nil
+}
+
+func TestMiddlewareAuthenticatesAPIKeysAndTokens(t *testing.T) {
+	key, _, hash, err := NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &keyStore{keys: map[string]models.APIKey{hash: {Name: "ops", Role: string(RoleAdvisor), Tenant: "north"}}}
+	a := New(Config{AdminKey: "fx_bootstrap", Keys: store.lookup, JWT: NewJWTVerifier(JWTConfig{Secret: testSecret})})
+
+	var got *Principal
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+	serve := func(header, value string) int {
+		got = nil
+		r := httptest.NewRequest("GET", "/students", nil)
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		if rr.Code == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: %s got 401 without WWW-Authenticate", header, value)
+		}
+		return rr.Code
+	}
+	token := hs256(t, map[string]interface{}{"alg": "HS256"}, map[string]interface{}{
+		"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix(), "role": "viewer", "tenant": "south",
+	}, testSecret)
+
+	for _, tc := range []struct {
+		header, value string
+		want          Principal
+	}{
+		{"X-API-Key", key, Principal{Subject: "api-key:ops", Method: MethodAPIKey, Role: RoleAdvisor, Tenant: "north"}},
+		{"Authorization", "Bearer " + key, Principal{Subject: "api-key:ops", Method: MethodAPIKey, Role: RoleAdvisor, Tenant: "north"}},
+		{"X-API-Key", "fx_bootstrap", Principal{Subject: "api-key:admin", Method: MethodAPIKey, Role: RoleAdmin}},
+		{"Authorization", "bearer " + token, Principal{Subject: "user-1", Method: MethodJWT, Role: RoleViewer, Tenant: "south"}},
+	} {
+		if code := serve(tc.header, tc.value); code != http.StatusOK || got == nil || *got != tc.want {
+			t.Errorf("%s: %s: got %d with %+v, want %+v", tc.header, tc.value, code, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct{ header, value string }{
+		{"", ""},
+		{"X-API-Key", "fx_unknown"},
+		{"Authorization", "Bearer fx_unknown"},
+		{"Authorization", "Basic " + key},
+		{"Authorization", "Bearer "},
+		{"Authorization", "Bearer " + token + "x"},
+	} {
+		if code := serve(tc.header, tc.value); code != http.StatusUnauthorized || got != nil {
+			t.Errorf("%s: %s: got %d, want 401", tc.header, tc.value, code)
+		}
+	}
+
+	// A revoked key stops working at once.
+	store.mu.Lock()
+	delete(store.keys, hash)
+	store.mu.Unlock()
+	if code := serve("X-API-Key", key); code != http.StatusUnauthorized {
+		t.Errorf("revoked key: got %d, want 401", code)
+	}
+}
+
+func TestMiddlewareRejectsTokensWithoutAVerifier(t *testing.T) {
+	a := New(Config{Keys: (&keyStore{}).lookup})
+	handler := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest("GET", "/students", nil)
+	r.Header.Set("Authorization", "Bearer "+hs256(t, map[string]interface{}{"alg": "HS256"}, validClaims(), testSecret))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("got %d, want 401", rr.Code)
+	}
+}
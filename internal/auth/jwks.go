@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval is how soon after the key set was read a token
+// naming a key it does not hold makes it read again. Keys rotated into the
+// file are picked up without a restart, but tokens with made-up key IDs
+// cannot make the file be read on every request.
+const jwksRefreshInterval = time.Minute
+
+// JWKS is the set of RSA signing keys in a JSON Web Key Set file. It is
+// safe for concurrent use.
+type JWKS struct {
+	path string
+	now  func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+	read time.Time
+}
+
+// OpenJWKS reads the key set in the file at path, failing if it holds no
+// usable key.
+func OpenJWKS(path string) (*JWKS, error) {
+	keys, err := LoadJWKS(path)
+	if err != nil {
+		return nil, err
+	}
+	return &JWKS{path: path, now: time.Now, keys: keys, read: time.Now()}, nil
+}
+
+// Key returns the key with the given ID. If there is none it reads the file
+// again, unless it did so less than jwksRefreshInterval ago. A file that has
+// become unreadable or invalid is logged and the keys read before are kept.
+func (s *JWKS) Key(kid string) (*rsa.PublicKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, true
+	}
+	now := s.now()
+	if now.Sub(s.read) < jwksRefreshInterval {
+		return nil, false
+	}
+	s.read = now
+	keys, err := LoadJWKS(s.path)
+	if err != nil {
+		log.Printf("Keeping the previous JWT keys: %v", err)
+		return nil, false
+	}
+	s.keys = keys
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// jwk is the part of a JSON Web Key that describes an RSA public key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// LoadJWKS reads the RSA signing keys of a JSON Web Key Set file, by key
+// ID. Keys of other types or uses are skipped.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") || (k.Alg != "" && k.Alg != "RS256") {
+			continue
+		}
+		key, err := k.rsaKey()
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", path, k.Kid, err)
+		}
+		if _, ok := keys[k.Kid]; ok {
+			return nil, fmt.Errorf("%s: key ID %q is used twice", path, k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s holds no RSA signing keys", path)
+	}
+	return keys, nil
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("bad modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("bad exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("unsupported exponent")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	if key.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("the modulus is shorter than 2048 bits")
+	}
+	return key, nil
+}
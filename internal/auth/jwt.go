@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"cmp"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+)
+
+// clockSkew is how far apart the clocks of the server and token issuers
+// may be when checking exp and nbf.
+const clockSkew = time.Minute
+
+// JWTConfig is which tokens a JWTVerifier accepts.
+type JWTConfig struct {
+	// Secret verifies HS256 tokens; empty rejects them.
+	Secret []byte
+	// Keys verify RS256 tokens by key ID; nil rejects them.
+	Keys *JWKS
+	// Issuer, if set, must be the iss claim.
+	Issuer string
+	// Audience, if set, must be the aud claim or one of them.
+	Audience string
+	// RoleClaim names the claim holding the principal's role, "role" if
+	// empty. Tokens without it get the viewer role.
+	RoleClaim string
+	// TenantClaim names the claim holding the principal's tenant, "tenant"
+	// if empty. Tokens without it belong to the default tenant.
+	TenantClaim string
+}
+
+// JWTVerifier checks the signature and claims of JWT bearer tokens.
+type JWTVerifier struct {
+	cfg JWTConfig
+	now func() time.Time
+}
+
+func NewJWTVerifier(cfg JWTConfig) *JWTVerifier {
+	return &JWTVerifier{cfg: cfg, now: time.Now}
+}
+
+// Claims are the registered claims of a token that the verifier checks.
+type Claims struct {
+	Subject   string       `json:"sub"`
+	Issuer    string       `json:"iss"`
+	Audience  audience     `json:"aud"`
+	ExpiresAt *numericDate `json:"exp"`
+	NotBefore *numericDate `json:"nbf"`
+	// Role and Tenant are read from the configured role and tenant claims.
+	Role   Role   `json:"-"`
+	Tenant string `json:"-"`
+}
+
+// audience is the aud claim, which is either a string or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+// numericDate is a time in seconds since the epoch, possibly fractional.
+type numericDate float64
+
+func (d numericDate) Time() time.Time {
+	sec, frac := math.Modf(float64(d))
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+func invalidToken(format string, args ...interface{}) error {
+	return apperror.Unauthorized("invalid token: "+format, args...)
+}
+
+// Verify checks a token's signature, with the key its alg and kid header
+// name, and then its claims: exp is required, sub must be set, the role
+// claim, if any, must name a role and the tenant claim, if any, a valid
+// tenant ID. The algorithm must be one the verifier has a key for, so a
+// token cannot pick a weaker one, or none.
+func (v *JWTVerifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalidToken("not a JWT")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalidToken("malformed header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalidToken("malformed signature")
+	}
+	if err := v.verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+	role, err := stringClaim(raw, cmp.Or(v.cfg.RoleClaim, "role"), string(RoleViewer))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseRole(role); err != nil {
+		return nil, invalidToken("unknown role %q", role)
+	}
+	claims.Role = Role(role)
+	if claims.Tenant, err = stringClaim(raw, cmp.Or(v.cfg.TenantClaim, "tenant"), tenant.Default); err != nil {
+		return nil, err
+	}
+	if tenant.Validate(claims.Tenant) != nil {
+		return nil, invalidToken("invalid tenant %q", claims.Tenant)
+	}
+	return &claims, nil
+}
+
+// stringClaim returns the claim called name, or def if there is none.
+func stringClaim(claims map[string]json.RawMessage, name, def string) (string, error) {
+	raw, ok := claims[name]
+	if !ok {
+		return def, nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", invalidToken("the %s claim is not a string", name)
+	}
+	return value, nil
+}
+
+func (v *JWTVerifier) verifySignature(alg, kid, signed string, signature []byte) error {
+	switch alg {
+	case "HS256":
+		if len(v.cfg.Secret) == 0 {
+			return invalidToken("HS256 is not accepted")
+		}
+		mac := hmac.New(sha256.New, v.cfg.Secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return invalidToken("bad signature")
+		}
+		return nil
+
+	case "RS256":
+		if v.cfg.Keys == nil {
+			return invalidToken("RS256 is not accepted")
+		}
+		key, ok := v.cfg.Keys.Key(kid)
+		if !ok {
+			return invalidToken("unknown key %q", kid)
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return invalidToken("bad signature")
+		}
+		return nil
+	}
+	return invalidToken("unsupported algorithm %q", alg)
+}
+
+func (v *JWTVerifier) checkClaims(claims *Claims) error {
+	now := v.now()
+	switch {
+	case claims.ExpiresAt == nil:
+		return invalidToken("no exp claim")
+	case now.After(claims.ExpiresAt.Time().Add(clockSkew)):
+		return invalidToken("expired")
+	case claims.NotBefore != nil && now.Add(clockSkew).Before(claims.NotBefore.Time()):
+		return invalidToken("not valid yet")
+	case v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer:
+		return invalidToken("wrong issuer")
+	case v.cfg.Audience != "" && !slices.Contains(claims.Audience, v.cfg.Audience):
+		return invalidToken("wrong audience")
+	case claims.Subject == "":
+		return invalidToken("no sub claim")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+var (
+	rsaKeysOnce sync.Once
+	rsaKeys     [2]*rsa.PrivateKey
+)
+
+// testRSAKeys returns two RSA keys, generated once for all the tests.
+func testRSAKeys(t *testing.T) [2]*rsa.PrivateKey {
+	t.Helper()
+	rsaKeysOnce.Do(func() {
+		for i := range rsaKeys {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				panic(err)
+			}
+			rsaKeys[i] = key
+		}
+	})
+	return rsaKeys
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hs256 signs a token with secret, whatever alg its header names.
+func hs256(t *testing.T, header, claims map[string]interface{}, secret []byte) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func rs256(t *testing.T, kid string, claims map[string]interface{}, key *rsa.PrivateKey) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]interface{}{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS writes a key set of the public halves of keys, by key ID.
+func writeJWKS(t *testing.T, path string, keys map[string]*rsa.PrivateKey) {
+	t.Helper()
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA", Kid: kid, Use: "sig", Alg: "RS256",
+			N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openTestJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) (*JWKS, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, keys)
+	set, err := OpenJWKS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set, path
+}
+
+// unsigned returns a token with an empty signature.
+func unsigned(t *testing.T, alg string) string {
+	t.Helper()
+	return encodeSegment(t, map[string]interface{}{"alg": alg}) + "." + encodeSegment(t, validClaims()) + "."
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestVerifyRejectsAlgorithmConfusion(t *testing.T) {
+	key := testRSAKeys(t)[0]
+	keys, _ := openTestJWKS(t, map[string]*rsa.PrivateKey{"k1": key})
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	rsaOnly := NewJWTVerifier(JWTConfig{Keys: keys})
+	both := NewJWTVerifier(JWTConfig{Keys: keys, Secret: testSecret})
+
+	for name, tc := range map[string]struct {
+		v     *JWTVerifier
+		token string
+	}{
+		// The public key is no secret, so it must not verify an HMAC.
+		"HS256 signed with the public key": {rsaOnly, hs256(t, map[string]interface{}{"alg": "HS256", "kid": "k1"}, validClaims(), publicPEM)},
+		"HS256 signed with the modulus":    {rsaOnly, hs256(t, map[string]interface{}{"alg": "HS256", "kid": "k1"}, validClaims(), key.N.Bytes())},
+		"RS256 header over an HMAC":        {both, hs256(t, map[string]interface{}{"alg": "RS256", "kid": "k1"}, validClaims(), testSecret)},
+		"none":                             {both, unsigned(t, "none")},
+		"None":                             {both, unsigned(t, "None")},
+		"no alg":                           {both, hs256(t, map[string]interface{}{}, validClaims(), testSecret)},
+		"HS512":                            {both, hs256(t, map[string]interface{}{"alg": "HS512"}, validClaims(), testSecret)},
+		"HS256 signed with another secret": {both, hs256(t, map[string]interface{}{"alg": "HS256"}, validClaims(), []byte("another secret of thirty-two bytes"))},
+		"RS256 signed by a key the verifier lacks": {rsaOnly, rs256(t, "k1", validClaims(), testRSAKeys(t)[1])},
+	} {
+		if _, err := tc.v.Verify(tc.token); err == nil {
+			t.Errorf("%s: expected the token to be rejected", name)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		v     *JWTVerifier
+		token string
+	}{
+		"RS256": {rsaOnly, rs256(t, "k1", validClaims(), key)},
+		"HS256": {both, hs256(t, map[string]interface{}{"alg": "HS256"}, validClaims(), testSecret)},
+	} {
+		if _, err := tc.v.Verify(tc.token); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestVerifyChecksClaims(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	v := NewJWTVerifier(JWTConfig{Secret: testSecret, Issuer: "https://issuer.example", Audience: "fealtyx", RoleClaim: "app_role"})
+	v.now = func() time.Time { return now }
+	at := func(d time.Duration) int64 { return now.Add(d).Unix() }
+
+	for _, tc := range []struct {
+		name   string
+		change map[string]interface{}
+		valid  bool
+	}{
+		{"valid", nil, true},
+		{"expired", map[string]interface{}{"exp": at(-2 * time.Minute)}, false},
+		{"expired within the clock skew", map[string]interface{}{"exp": at(-30 * time.Second)}, true},
+		{"no exp", map[string]interface{}{"exp": nil}, false},
+		{"not valid yet", map[string]interface{}{"nbf": at(2 * time.Minute)}, false},
+		{"not valid yet within the clock skew", map[string]interface{}{"nbf": at(30 * time.Second)}, true},
+		{"valid since", map[string]interface{}{"nbf": at(-time.Hour)}, true},
+		{"wrong issuer", map[string]interface{}{"iss": "https://other.example"}, false},
+		{"no issuer", map[string]interface{}{"iss": nil}, false},
+		{"wrong audience", map[string]interface{}{"aud": "other"}, false},
+		{"audience among others", map[string]interface{}{"aud": []string{"other", "fealtyx"}}, true},
+		{"no audience", map[string]interface{}{"aud": nil}, false},
+		{"no sub", map[string]interface{}{"sub": nil}, false},
+		{"unknown role", map[string]interface{}{"app_role": "owner"}, false},
+		{"role that is not a string", map[string]interface{}{"app_role": 1}, false},
+		{"invalid tenant", map[string]interface{}{"tenant": "Not A Tenant"}, false},
+	} {
+		claims := map[string]interface{}{
+			"sub": "user-1", "iss": "https://issuer.example", "aud": "fealtyx",
+			"exp": at(time.Hour), "app_role": "advisor", "tenant": "north",
+		}
+		for k, value := range tc.change {
+			if value == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = value
+			}
+		}
+		got, err := v.Verify(hs256(t, map[string]interface{}{"alg": "HS256"}, claims, testSecret))
+		if (err == nil) != tc.valid {
+			t.Errorf("%s: got error %v, want valid %v", tc.name, err, tc.valid)
+			continue
+		}
+		if tc.valid && (got.Subject != "user-1" || got.Role != RoleAdvisor || got.Tenant != "north") {
+			t.Errorf("%s: unexpected claims %+v", tc.name, got)
+		}
+	}
+}
+
+func TestVerifyRereadsJWKSForUnknownKeys(t *testing.T) {
+	keys := testRSAKeys(t)
+	set, path := openTestJWKS(t, map[string]*rsa.PrivateKey{"k1": keys[0]})
+	now := time.Now()
+	set.now = func() time.Time { return now }
+	v := NewJWTVerifier(JWTConfig{Keys: set})
+	rotated := rs256(t, "k2", validClaims(), keys[1])
+
+	if _, err := v.Verify(rotated); err == nil {
+		t.Fatal("expected a token with an unknown key ID to be rejected")
+	}
+
+	// The new key is only read once the last read is old enough.
+	writeJWKS(t, path, map[string]*rsa.PrivateKey{"k1": keys[0], "k2": keys[1]})
+	if _, err := v.Verify(rotated); err == nil {
+		t.Error("expected the key set not to be read again so soon")
+	}
+	now = now.Add(jwksRefreshInterval)
+	if _, err := v.Verify(rotated); err != nil {
+		t.Errorf("expected the rotated key to be read: %v", err)
+	}
+
+	// A broken file leaves the keys as they were.
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(jwksRefreshInterval)
+	if _, err := v.Verify(rs256(t, "k3", validClaims(), keys[1])); err == nil {
+		t.Error("expected an unknown key ID to be rejected")
+	}
+	if _, err := v.Verify(rs256(t, "k1", validClaims(), keys[0])); err != nil {
+		t.Errorf("expected the keys read before to be kept: %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/gorilla/mux"
+)
+
+// Role is what a principal may do.
+type Role string
+
+const (
+	// RoleAdmin may do everything.
+	RoleAdmin Role = "admin"
+	// RoleAdvisor may read and change the records of the students assigned
+	// to it and generate their summaries.
+	RoleAdvisor Role = "advisor"
+	// RoleViewer may read everything but API keys, and change nothing.
+	RoleViewer Role = "viewer"
+)
+
+// ParseRole checks that s names a role.
+func ParseRole(s string) (Role, error) {
+	switch role := Role(s); role {
+	case RoleAdmin, RoleAdvisor, RoleViewer:
+		return role, nil
+	}
+	return "", apperror.BadRequest("role must be admin, advisor or viewer")
+}
+
+// Permission is an action on a kind of record.
+type Permission string
+
+const (
+	ReadStudents Permission = "students:read"
+	// WriteStudents allows changing a student and its enrollments, grades,
+	// attendance, notes and guardian links.
+	WriteStudents     Permission = "students:write"
+	CreateStudents    Permission = "students:create"
+	DeleteStudents    Permission = "students:delete"
+	GenerateSummaries Permission = "summaries:generate"
+	// ReadCatalog and WriteCatalog cover courses, guardians and custom
+	// fields.
+	ReadCatalog  Permission = "catalog:read"
+	WriteCatalog Permission = "catalog:write"
+	// Administer covers API keys, advisor assignments and metrics.
+	Administer Permission = "admin"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {ReadStudents, WriteStudents, CreateStudents, DeleteStudents, GenerateSummaries,
+		ReadCatalog, WriteCatalog, Administer},
+	RoleAdvisor: {ReadStudents, WriteStudents, GenerateSummaries, ReadCatalog},
+	RoleViewer:  {ReadStudents, ReadCatalog},
+}
+
+// Can reports whether the role has the permission.
+func (r Role) Can(p Permission) bool {
+	return slices.Contains(rolePermissions[r], p)
+}
+
+// routePermissions is the permission each route needs, by method and path
+// template. A route that is missing here is denied to everyone.
+var routePermissions = map[string]Permission{
+	"GET /students":                                             ReadStudents,
+	"POST /students":                                            CreateStudents,
+	"DELETE /students":                                          DeleteStudents,
+	"POST /students/import":                                     CreateStudents,
+	"GET /students/export":                                      ReadStudents,
+	"GET /students/search":                                      ReadStudents,
+	"GET /students/{id}":                                        ReadStudents,
+	"PUT /students/{id}":                                        WriteStudents,
+	"DELETE /students/{id}":                                     DeleteStudents,
+	"POST /students/{id:[0-9]+}:restore":                        DeleteStudents,
+	"GET /students/{id}/history":                                ReadStudents,
+	"GET /students/{id}/summary":                                GenerateSummaries,
+	"GET /students/{id}/advisors":                               ReadStudents,
+	"POST /students/{id}/advisors":                              Administer,
+	"DELETE /students/{id}/advisors/{advisor}":                  Administer,
+	"GET /students/{id}/enrollments":                            ReadStudents,
+	"POST /students/{id}/enrollments":                           WriteStudents,
+	"PUT /students/{id}/enrollments/{courseId}":                 WriteStudents,
+	"DELETE /students/{id}/enrollments/{courseId}":              WriteStudents,
+	"GET /students/{id}/grades":                                 ReadStudents,
+	"POST /students/{id}/grades":                                WriteStudents,
+	"GET /students/{id}/grades/{gradeId}":                       ReadStudents,
+	"PUT /students/{id}/grades/{gradeId}":                       WriteStudents,
+	"DELETE /students/{id}/grades/{gradeId}":                    WriteStudents,
+	"GET /students/{id}/performance":                            ReadStudents,
+	"GET /students/{id}/attendance":                             ReadStudents,
+	"POST /students/{id}/attendance":                            WriteStudents,
+	"GET /students/{id}/attendance/stats":                       ReadStudents,
+	`DELETE /students/{id}/attendance/{date:\d{4}-\d{2}-\d{2}}`: WriteStudents,
+	"GET /students/{id}/notes":                                  ReadStudents,
+	"POST /students/{id}/notes":                                 WriteStudents,
+	"GET /students/{id}/notes/{noteId}":                         ReadStudents,
+	"PUT /students/{id}/notes/{noteId}":                         WriteStudents,
+	"DELETE /students/{id}/notes/{noteId}":                      WriteStudents,
+	"GET /students/{id}/guardians":                              ReadStudents,
+	"POST /students/{id}/guardians":                             WriteStudents,
+	"PUT /students/{id}/guardians/{guardianId}":                 WriteStudents,
+	"DELETE /students/{id}/guardians/{guardianId}":              WriteStudents,
+	"GET /students/{id}/guardians/{guardianId}/summary":         GenerateSummaries,
+	"GET /guardians":                                            ReadCatalog,
+	"POST /guardians":                                           WriteCatalog,
+	"GET /guardians/{id}":                                       ReadCatalog,
+	"PUT /guardians/{id}":                                       WriteCatalog,
+	"DELETE /guardians/{id}":                                    WriteCatalog,
+	"GET /guardians/{id}/students":                              ReadStudents,
+	"GET /custom-fields":                                        ReadCatalog,
+	"POST /custom-fields":                                       WriteCatalog,
+	"GET /custom-fields/{name}":                                 ReadCatalog,
+	"PUT /custom-fields/{name}":                                 WriteCatalog,
+	"DELETE /custom-fields/{name}":                              WriteCatalog,
+	"GET /courses":                                              ReadCatalog,
+	"POST /courses":                                             WriteCatalog,
+	"GET /courses/{id}":                                         ReadCatalog,
+	"PUT /courses/{id}":                                         WriteCatalog,
+	"DELETE /courses/{id}":                                      WriteCatalog,
+	"POST /courses/{id}/attendance":                             WriteStudents,
+	"GET /metrics":                                              Administer,
+	"GET /admin/api-keys":                                       Administer,
+	"POST /admin/api-keys":                                      Administer,
+	"DELETE /admin/api-keys/{id}":                               Administer,
+}
+
+// RoutePermission returns the permission a route needs, by the method and
+// path template it was registered with.
+func RoutePermission(method, template string) (Permission, bool) {
+	p, ok := routePermissions[method+" "+template]
+	return p, ok
+}
+
+// AssignmentLookup reports whether a student is assigned to an advisor.
+type AssignmentLookup func(ctx context.Context, advisor string, studentID int) (bool, error)
+
+// Authorizer checks that principals may make the requests they make.
+type Authorizer struct {
+	assigned AssignmentLookup
+}
+
+func NewAuthorizer(assigned AssignmentLookup) *Authorizer {
+	return &Authorizer{assigned: assigned}
+}
+
+// Middleware answers requests the principal's role does not allow with 403,
+// as well as requests by advisors for a student that is not assigned to
+// them. It goes after the authenticator's middleware, on a router, so that
+// the route has been matched.
+func (z *Authorizer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := z.authorize(r); err != nil {
+			apperror.Write(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (z *Authorizer) authorize(r *http.Request) error {
+	p := FromContext(r.Context())
+	if p == nil {
+		return apperror.Unauthorized("the request is not authenticated")
+	}
+	var template string
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ = route.GetPathTemplate()
+	}
+	permission, ok := RoutePermission(r.Method, template)
+	if !ok {
+		log.Printf("No permission is defined for %s %s, denying it", r.Method, template)
+		return apperror.Forbidden("no role may do this")
+	}
+	if !p.Role.Can(permission) {
+		return apperror.Forbidden("the %s role may not do this", p.Role)
+	}
+
+	if p.Role != RoleAdvisor || !strings.HasPrefix(template, "/students/{id") {
+		return nil
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		// The handler rejects the ID.
+		return nil
+	}
+	assigned, err := z.assigned(r.Context(), p.Subject, id)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return apperror.Forbidden("student %d is not assigned to you", id)
+	}
+	return nil
+}
+
+// Check fails with 403 if the principal in ctx may not do what p allows.
+// It is for actions that depend on more than the route, and lets everything
+// through if authentication is off.
+func Check(ctx context.Context, p Permission) error {
+	if principal := FromContext(ctx); principal != nil && !principal.Role.Can(p) {
+		return apperror.Forbidden("the %s role may not do this", principal.Role)
+	}
+	return nil
+}
+
+// AdvisorScope returns the subject of the principal in ctx if it is an
+// advisor, whose listings hold only the students assigned to it, and ""
+// otherwise.
+func AdvisorScope(ctx context.Context) string {
+	if p := FromContext(ctx); p != nil && p.Role == RoleAdvisor {
+		return p.Subject
+	}
+	return ""
+}
@@ -0,0 +1,14 @@
+// Package backendtest holds the tests both student backends must pass, the
+// SQL one and the in-memory one, so that each runs the same cases.
+package backendtest
+
+import "github.com/AashishKumar-3002/FealtyX/internal/models"
+
+// Backend is a student store under test, in the default tenant.
+type Backend interface {
+	CreateStudent(student models.Student) (models.Student, error)
+	SaveSummary(summary models.StudentSummary) error
+	CreateNote(note models.Note) error
+	ListStudents(filter models.StudentFilter, page models.StudentPage) ([]models.Student, *models.StudentCursor, error)
+	SearchStudents(q string, limit int) ([]models.SearchResult, error)
+}
@@ -0,0 +1,134 @@
+package backendtest
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// keysetStudents tie on names, ignoring case of ASCII and other letters
+// alike, and on ages. keysetOrders
+// lists their indexes in ascending order of each sort field, ties broken
+// by ID; descending order is the reverse.
+var (
+	keysetStudents = []models.Student{
+		{Name: "Keyset Ann", Age: 30, Email: "keyset.c@example.com"},
+		{Name: "keyset ann", Age: 30, Email: "keyset.a@example.com"},
+		{Name: "Keyset Bob", Age: 31, Email: "keyset.b@example.com"},
+		{Name: "Keyset Ann", Age: 29, Email: "KEYSET.D@example.com"},
+		{Name: "Keyset Cy", Age: 30, Email: "keyset.e@example.com"},
+		{Name: "Keyset Bob", Age: 32, Email: "keyset.f@example.com"},
+		{Name: "keyset élodie", Age: 31, Email: "keyset.g@example.com"},
+		{Name: "Keyset Élodie", Age: 29, Email: "keyset.h@example.com"},
+	}
+	keysetOrders = map[string][]int{
+		models.SortByID:    {0, 1, 2, 3, 4, 5, 6, 7},
+		models.SortByName:  {0, 1, 3, 2, 5, 4, 6, 7},
+		models.SortByAge:   {3, 7, 0, 1, 4, 2, 6, 5},
+		models.SortByEmail: {1, 2, 0, 3, 4, 5, 6, 7},
+	}
+)
+
+func intp(n int) *int { return &n }
+
+// keysetRanges are range filters, each with the indexes of the students it
+// keeps.
+var keysetRanges = []struct {
+	filter models.StudentFilter
+	keeps  []int
+}{
+	{models.StudentFilter{}, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+	{models.StudentFilter{MinAge: intp(30)}, []int{0, 1, 2, 4, 5, 6}},
+	{models.StudentFilter{MaxAge: intp(30)}, []int{0, 1, 3, 4, 7}},
+	{models.StudentFilter{MinAge: intp(30), MaxAge: intp(30)}, []int{0, 1, 4}},
+	{models.StudentFilter{MinAge: intp(31), MaxAge: intp(31)}, []int{2, 6}},
+	{models.StudentFilter{MinAge: intp(33)}, nil},
+	{models.StudentFilter{MaxAge: intp(28)}, nil},
+	{models.StudentFilter{NamePrefix: "keyset b"}, []int{2, 5}},
+	{models.StudentFilter{NamePrefix: "keyset ann", MinAge: intp(30)}, []int{0, 1}},
+	{models.StudentFilter{EmailPrefix: "keyset.d"}, []int{3}},
+	{models.StudentFilter{NamePrefix: "keyset é"}, []int{6, 7}},
+	{models.StudentFilter{NamePrefix: "keyset élodie", MaxAge: intp(29)}, []int{7}},
+}
+
+// List checks that paging through b's listings, in every sort order and
+// page size, returns each student a range filter keeps exactly once. Only
+// students whose names start with "keyset" are listed, so b may hold
+// others.
+func List(t *testing.T, b Backend) {
+	t.Helper()
+	var ids []int
+	for _, student := range keysetStudents {
+		created, err := b.CreateStudent(student)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	for _, r := range keysetRanges {
+		filter := r.filter
+		if filter.NamePrefix == "" {
+			filter.NamePrefix = "keyset"
+		}
+		for sort, order := range keysetOrders {
+			var want []int
+			for _, i := range order {
+				if slices.Contains(r.keeps, i) {
+					want = append(want, ids[i])
+				}
+			}
+			for _, desc := range []bool{false, true} {
+				if desc {
+					want = slices.Clone(want)
+					slices.Reverse(want)
+				}
+				for _, limit := range []int{0, 1, 2, 4} {
+					if got := listAll(t, b, filter, sort, desc, limit); !slices.Equal(got, want) {
+						t.Errorf("%+v sorted by %s (desc %v) in pages of %d: got %v, want %v", r.filter, sort, desc, limit, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// listAll pages through a listing, passing each cursor through the after
+// parameter, and returns the IDs in the order they came.
+func listAll(t *testing.T, b Backend, filter models.StudentFilter, sort string, desc bool, limit int) []int {
+	t.Helper()
+	values := url.Values{"sort": {sort}}
+	if desc {
+		values.Set("sort", "-"+sort)
+	}
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	var ids []int
+	for pages := 0; ; pages++ {
+		if pages > len(keysetStudents)+1 {
+			t.Fatalf("listing by %s does not end", values.Get("sort"))
+		}
+		page, err := models.ParseStudentPage(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		students, next, err := b.ListStudents(filter, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if limit > 0 && len(students) > limit {
+			t.Errorf("page of %d students with limit %d", len(students), limit)
+		}
+		for _, s := range students {
+			ids = append(ids, s.ID)
+		}
+		if next == nil {
+			return ids
+		}
+		values.Set("after", next.Encode())
+	}
+}
@@ -0,0 +1,85 @@
+package backendtest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// Search checks that b ranks and highlights search results and reads
+// operator characters as punctuation.
+func Search(t *testing.T, b Backend) {
+	t.Helper()
+	create := func(name, email string) models.Student {
+		t.Helper()
+		student, err := b.CreateStudent(models.Student{Name: name, Age: 20, Email: email})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return student
+	}
+	vera := create("Vera Violin", "vera.search@example.com")
+	sam := create("Sam Summary", "sam.search@example.com")
+	nina := create("Nina Note", "nina.search@example.com")
+	if err := b.SaveSummary(models.StudentSummary{StudentID: sam.ID, Summary: "Plays the violin in the school orchestra."}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.CreateNote(models.Note{StudentID: nina.ID, Author: "advisor", Body: "Practises the violin <daily> & wants lessons."}); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(q string) []models.SearchResult {
+		t.Helper()
+		results, err := b.SearchStudents(q, 10)
+		if err != nil {
+			t.Fatalf("searching for %q: %v", q, err)
+		}
+		return results
+	}
+
+	// A match in the name outranks one in the summary, which outranks one
+	// in a note.
+	results := search("violin")
+	if len(results) != 3 || results[0].Student.ID != vera.ID || results[1].Student.ID != sam.ID || results[2].Student.ID != nina.ID {
+		t.Fatalf("expected Vera, Sam and Nina in that order, got %+v", results)
+	}
+	if !(results[0].Rank > results[1].Rank && results[1].Rank > results[2].Rank) {
+		t.Errorf("expected falling ranks, got %v, %v, %v", results[0].Rank, results[1].Rank, results[2].Rank)
+	}
+
+	if got := results[0].Highlights.Name; got != "Vera <mark>Violin</mark>" {
+		t.Errorf("name highlight %q", got)
+	}
+	if got := results[1].Highlights.Summary; !strings.Contains(got, "<mark>violin</mark>") {
+		t.Errorf("summary highlight %q", got)
+	}
+	if notes := results[2].Highlights.Notes; len(notes) != 1 || !strings.Contains(notes[0], "<mark>violin</mark>") ||
+		!strings.Contains(notes[0], "&lt;daily&gt; &amp;") || strings.Contains(notes[0], "<daily>") {
+		t.Errorf("note highlights %q", notes)
+	}
+
+	// Operator characters are read as punctuation, never as query syntax.
+	for q, want := range map[string][]int{
+		"violin:*":       {vera.ID, sam.ID, nina.ID},
+		"!violin":        {vera.ID, sam.ID, nina.ID},
+		"(violin)":       {vera.ID, sam.ID, nina.ID},
+		"'violin'":       {vera.ID, sam.ID, nina.ID},
+		"violin & (":     {vera.ID, sam.ID, nina.ID},
+		`violin\`:        {vera.ID, sam.ID, nina.ID},
+		"violin <-> |":   {vera.ID, sam.ID, nina.ID},
+		"violin & !vera": {vera.ID},
+		"violin | vera":  {vera.ID},
+		"vio:*":          nil,
+		"the and":        nil,
+	} {
+		var got []int
+		for _, r := range search(q) {
+			got = append(got, r.Student.ID)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%q found %v, want %v", q, got, want)
+		}
+	}
+}
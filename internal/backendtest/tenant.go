@@ -0,0 +1,143 @@
+package backendtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/importer"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	northKey = "fx_north"
+	southKey = "fx_south"
+)
+
+// tenantKeys looks up an admin key of the north tenant and one of the
+// south tenant.
+func tenantKeys(_ context.Context, hash string) (models.APIKey, error) {
+	switch hash {
+	case auth.HashAPIKey(northKey):
+		return models.APIKey{Name: "north", Role: string(auth.RoleAdmin), Tenant: "north"}, nil
+	case auth.HashAPIKey(southKey):
+		return models.APIKey{Name: "south", Role: string(auth.RoleAdmin), Tenant: "south"}, nil
+	}
+	return models.APIKey{}, apperror.NotFound("no such key")
+}
+
+// TenantRoutes are the handlers of one backend that TenantIsolation goes
+// through, and the middleware that hides other tenants' students from
+// them, if the backend needs one.
+type TenantRoutes struct {
+	Create, Get, Update, Delete, History, Export, Search, ImportStudents http.HandlerFunc
+	Students                                                             mux.MiddlewareFunc
+}
+
+// TenantIsolation creates a student in the north tenant and checks that
+// the south tenant can neither reach it nor find it.
+func TenantIsolation(t *testing.T, routes TenantRoutes) {
+	t.Helper()
+	r := mux.NewRouter()
+	r.Use(auth.New(auth.Config{Keys: tenantKeys}).Middleware)
+	r.Use(middleware.Tenant(tenant.NewOpenDirectory()))
+	if routes.Students != nil {
+		r.Use(routes.Students)
+	}
+	r.HandleFunc("/students", routes.Create).Methods("POST")
+	r.HandleFunc("/students/import", routes.ImportStudents).Methods("POST")
+	r.HandleFunc("/students/export", routes.Export).Methods("GET")
+	r.HandleFunc("/students/search", routes.Search).Methods("GET")
+	r.HandleFunc("/students/{id}", routes.Get).Methods("GET")
+	r.HandleFunc("/students/{id}", routes.Update).Methods("PUT")
+	r.HandleFunc("/students/{id}", routes.Delete).Methods("DELETE")
+	r.HandleFunc("/students/{id}/history", routes.History).Methods("GET")
+
+	serve := func(key, tenantID, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		if strings.HasPrefix(path, "/students/import") {
+			req.Header.Set("Content-Type", "text/csv")
+		}
+		if tenantID != "" {
+			req.Header.Set(tenant.Header, tenantID)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	studentJSON := func(name string) string {
+		b, _ := json.Marshal(models.Student{Name: name, Age: 20, Email: "tenant.isolation@example.com"})
+		return string(b)
+	}
+
+	rr := serve(northKey, "", "POST", "/students", studentJSON("Tenant North"))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create returned %d: %s", rr.Code, rr.Body)
+	}
+	var north models.Student
+	json.Unmarshal(rr.Body.Bytes(), &north)
+	path := fmt.Sprintf("/students/%d", north.ID)
+
+	for _, tc := range []struct{ method, path, body string }{
+		{"GET", path, ""},
+		{"PUT", path, studentJSON("Tenant Changed")},
+		{"DELETE", path, ""},
+		{"GET", path + "/history", ""},
+	} {
+		if rr := serve(southKey, "", tc.method, tc.path, tc.body); rr.Code != http.StatusNotFound {
+			t.Errorf("%s %s from another tenant returned %d, want 404", tc.method, tc.path, rr.Code)
+		}
+	}
+	// A key may not act for another tenant, but may name its own.
+	if rr := serve(northKey, "south", "GET", "/students/export?format=ndjson", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("a north key acting for south returned %d, want 403", rr.Code)
+	}
+	if rr := serve(northKey, "north", "GET", path, ""); rr.Code != http.StatusOK {
+		t.Errorf("a north key naming north returned %d, want 200", rr.Code)
+	}
+
+	// The same email is free in the south tenant, so importing it there
+	// creates a student rather than updating the north one.
+	rr = serve(southKey, "", "POST", "/students/import?on_duplicate=update", "name,age,email\nTenant South,30,tenant.isolation@example.com\n")
+	var report importer.Report
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil || report.Created != 1 || report.Updated != 0 {
+		t.Errorf("importing into the south tenant returned %d: %s", rr.Code, rr.Body)
+	}
+
+	rr = serve(southKey, "", "GET", "/students/export?format=ndjson", "")
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	var exported models.Student
+	if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &exported) != nil || exported.Name != "Tenant South" {
+		t.Errorf("the south tenant exported %d %s, want only its own student", rr.Code, rr.Body)
+	}
+
+	rr = serve(southKey, "", "GET", "/students/search?q=tenant", "")
+	var results []models.SearchResult
+	json.Unmarshal(rr.Body.Bytes(), &results)
+	if len(results) != 1 || results[0].Student.Name != "Tenant South" {
+		t.Errorf("the south tenant found %d %s, want only its own student", rr.Code, rr.Body)
+	}
+
+	rr = serve(northKey, "", "GET", path+"/history", "")
+	var history []models.AuditEntry
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil || len(history) != 1 || history[0].Action != "create" {
+		t.Errorf("expected the north student's history to hold only its creation, got %d %s", rr.Code, rr.Body)
+	}
+	rr = serve(northKey, "", "GET", path, "")
+	var got models.Student
+	if json.Unmarshal(rr.Body.Bytes(), &got); got.Name != "Tenant North" || got.Age != 20 {
+		t.Errorf("expected the north student to be untouched, got %d %s", rr.Code, rr.Body)
+	}
+}
@@ -2,31 +2,92 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
 
 	_ "github.com/lib/pq"
 )
 
-func Connect(databaseURL string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", databaseURL)
+// Dialect identifies the SQL database behind a *sql.DB.
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	SQLite
+)
+
+func (d Dialect) String() string {
+	if d == SQLite {
+		return "sqlite"
+	}
+	return "postgres"
+}
+
+// sqliteScheme prefixes database URLs that select the embedded SQLite
+// backend, e.g. sqlite://data/fealtyx.db.
+const sqliteScheme = "sqlite://"
+
+// DialectOf reports which database db, a *sql.DB or a *Tx, talks to.
+func DialectOf(db interface{ Driver() driver.Driver }) Dialect {
+	if _, ok := db.Driver().(*sqliteDriver); ok {
+		return SQLite
+	}
+	return Postgres
+}
+
+// Open connects to the database without touching the schema, waiting for
+// it as opts allow. URLs starting with sqlite:// open a SQLite file;
+// anything else is handed to Postgres.
+func Open(databaseURL string, opts Options) (*sql.DB, error) {
+	db, err := openPool(databaseURL, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = db.Ping(); err != nil {
+	if err = waitForDatabase(db, opts.ConnectTimeout); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS students (
-			id SERIAL PRIMARY KEY,
-			name TEXT NOT NULL,
-			age INTEGER NOT NULL,
-			email TEXT NOT NULL UNIQUE
-		)
-	`)
+	return db, nil
+}
+
+// openPool sets up the connection pool for databaseURL without connecting.
+func openPool(databaseURL string, opts Options) (*sql.DB, error) {
+	var db *sql.DB
+	var err error
+	switch {
+	case strings.HasPrefix(databaseURL, sqliteScheme) && opts.RowLevelSecurity:
+		return nil, errors.New("row-level security needs Postgres")
+	case strings.HasPrefix(databaseURL, sqliteScheme):
+		db, err = openSQLite(strings.TrimPrefix(databaseURL, sqliteScheme))
+	case opts.RowLevelSecurity:
+		var connector driver.Connector
+		if connector, err = openRowLevelSecurity(databaseURL); err == nil {
+			db = sql.OpenDB(connector)
+		}
+	default:
+		db, err = sql.Open("postgres", databaseURL)
+	}
 	if err != nil {
 		return nil, err
 	}
+	opts.apply(db)
+	return db, nil
+}
+
+// Connect opens the database and applies any pending migrations.
+func Connect(databaseURL string, opts Options) (*sql.DB, error) {
+	db, err := Open(databaseURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := MigrateUp(db); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return db, nil
-}
\ No newline at end of file
+}
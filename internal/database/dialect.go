@@ -0,0 +1,62 @@
+package database
+
+import "fmt"
+
+// The models write their queries for Postgres; SQLite takes the same $1
+// placeholders and most of the same SQL. Where the two differ, a query
+// takes the fragment from the Dialect of the database it runs on, as
+// DialectOf reports it.
+
+// JSONContains is the condition that the JSON object in column contains
+// the one in param, as Postgres's jsonb @> operator checks.
+func (d Dialect) JSONContains(column, param string) string {
+	if d == SQLite {
+		return fmt.Sprintf("jsonb_contains(%s, %s)", column, param)
+	}
+	return fmt.Sprintf("%s @> %s", column, param)
+}
+
+// JSONHasKey is the condition that the JSON object in column has the key
+// in param.
+func (d Dialect) JSONHasKey(column, param string) string {
+	if d == SQLite {
+		return fmt.Sprintf("jsonb_exists(%s, %s)", column, param)
+	}
+	return fmt.Sprintf("%s ? %s", column, param)
+}
+
+// JSONRemoveKey is the JSON object in column without the key in param,
+// which may not hold a double quote.
+func (d Dialect) JSONRemoveKey(column, param string) string {
+	if d == SQLite {
+		return fmt.Sprintf(`json_remove(%s, '$."' || %s || '"')`, column, param)
+	}
+	return fmt.Sprintf("%s - %s", column, param)
+}
+
+// Cast is expr as the Postgres type typ. SQLite values carry their own
+// types, so there it is expr unchanged.
+func (d Dialect) Cast(expr, typ string) string {
+	if d == SQLite {
+		return expr
+	}
+	return expr + "::" + typ
+}
+
+// ForUpdate ends a SELECT that locks the rows it reads until the end of
+// the transaction. SQLite transactions hold the database's write lock from
+// the start, so there it is empty.
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// ByteOrder is the collation that compares text byte by byte.
+func (d Dialect) ByteOrder() string {
+	if d == SQLite {
+		return "COLLATE BINARY"
+	}
+	return `COLLATE "C"`
+}
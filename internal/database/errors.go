@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLSTATE codes for the constraint failures callers tell apart. SQLite
+// failures are reported with the same codes.
+const (
+	UniqueViolation     = "23505"
+	ForeignKeyViolation = "23503"
+	QueryCanceled       = "57014"
+)
+
+// ConstraintError is a constraint failure reported by either backend.
+type ConstraintError struct {
+	// Code is the SQLSTATE code of the failure.
+	Code string
+	// Constraint is the Postgres name of the violated constraint, or empty
+	// if the backend does not say which one it was.
+	Constraint string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string { return e.Err.Error() }
+
+func (e *ConstraintError) Unwrap() error { return e.Err }
+
+// AsConstraintError reports whether err is a constraint failure and, if so,
+// which one.
+func AsConstraintError(err error) (*ConstraintError, bool) {
+	var constraintErr *ConstraintError
+	if errors.As(err, &constraintErr) {
+		return constraintErr, true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Class() == "23" {
+		return &ConstraintError{Code: string(pqErr.Code), Constraint: pqErr.Constraint, Err: err}, true
+	}
+	return nil, false
+}
+
+// Unavailable reports whether err means the database could not be reached
+// or could not serve the query at all, as opposed to rejecting it.
+func Unavailable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "53", "57":
+			return pqErr.Code != QueryCanceled
+		}
+	}
+	return false
+}
+
+// Interrupted reports whether err means a query was cut short because its
+// context ended. Postgres and SQLite report that with their own errors
+// rather than the context's.
+func Interrupted(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == QueryCanceled
+	}
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_INTERRUPT
+}
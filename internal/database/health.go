@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// healthPingTimeout bounds each health check ping.
+const healthPingTimeout = 5 * time.Second
+
+// HealthStatus is the outcome of the latest health check.
+type HealthStatus struct {
+	Healthy             bool      `json:"healthy"`
+	LastCheck           time.Time `json:"last_check"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+}
+
+// Health pings a database in the background and records whether it
+// answers.
+type Health struct {
+	name   string
+	mu     sync.RWMutex
+	status HealthStatus
+}
+
+// StartHealthCheck pings db every interval for as long as the process
+// runs, logging when the database stops or starts answering. db is assumed
+// healthy to begin with, since Open has just reached it.
+func StartHealthCheck(db *sql.DB, interval time.Duration) *Health {
+	h := newHealth("Database")
+	h.start(db, interval)
+	return h
+}
+
+// newHealth returns a healthy Health whose log lines start with name.
+func newHealth(name string) *Health {
+	return &Health{name: name, status: HealthStatus{Healthy: true, LastCheck: time.Now()}}
+}
+
+func (h *Health) start(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), healthPingTimeout)
+			h.record(db.PingContext(ctx))
+			cancel()
+		}
+	}()
+}
+
+func (h *Health) record(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	wasHealthy := h.status.Healthy
+	h.status.LastCheck = time.Now()
+	if err != nil {
+		h.status.Healthy = false
+		h.status.LastError = err.Error()
+		h.status.ConsecutiveFailures++
+		if wasHealthy {
+			log.Printf("%s health check failed: %v", h.name, err)
+		}
+		return
+	}
+	if !wasHealthy {
+		log.Printf("%s is answering again after %d failed health checks", h.name, h.status.ConsecutiveFailures)
+	}
+	h.status = HealthStatus{Healthy: true, LastCheck: h.status.LastCheck}
+}
+
+// Status returns the outcome of the latest health check.
+func (h *Health) Status() HealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.status
+}
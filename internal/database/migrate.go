@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the Postgres advisory lock key held while migrating so
+// that replicas starting at the same time apply each migration only once.
+const migrationLockID = 7261090263
+
+// Migration is one versioned schema change loaded from migrations/.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrations returns the embedded migrations for the dialect ordered by
+// version. Files named <version>_<name>.<up|down>.sql hold Postgres DDL; a
+// <version>_<name>.<up|down>.sqlite.sql file next to one replaces it on
+// SQLite.
+func Migrations(dialect Dialect) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		isVariant := strings.HasSuffix(base, ".sqlite")
+		base = strings.TrimSuffix(base, ".sqlite")
+		direction := base[strings.LastIndex(base, ".")+1:]
+		base = strings.TrimSuffix(base, "."+direction)
+		parts := strings.SplitN(base, "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		// On SQLite the variant overwrites the Postgres file read before it;
+		// entries come sorted, and ".sql" sorts before ".sqlite.sql".
+		if isVariant && dialect != SQLite {
+			continue
+		}
+
+		body, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration.
+func MigrateUp(db *sql.DB) error {
+	return withMigrationLock(db, func(conn *sql.Conn, applied map[int]bool) error {
+		migrations, err := Migrations(DialectOf(db))
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if applied[m.Version] {
+				continue
+			}
+			log.Printf("Applying migration %04d_%s", m.Version, m.Name)
+			err := inTx(conn, func(tx *sql.Tx) error {
+				if _, err := tx.Exec(m.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateDown rolls back the most recent steps applied migrations, or all of
+// them if steps is zero or negative.
+func MigrateDown(db *sql.DB, steps int) error {
+	return withMigrationLock(db, func(conn *sql.Conn, applied map[int]bool) error {
+		migrations, err := Migrations(DialectOf(db))
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if !applied[m.Version] {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %04d_%s cannot be rolled back", m.Version, m.Name)
+			}
+			log.Printf("Rolling back migration %04d_%s", m.Version, m.Name)
+			err := inTx(conn, func(tx *sql.Tx) error {
+				if _, err := tx.Exec(m.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", m.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if steps--; steps == 0 {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// GetMigrationStatus lists every known migration and whether it is applied.
+func GetMigrationStatus(db *sql.DB) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := withMigrationLock(db, func(conn *sql.Conn, applied map[int]bool) error {
+		migrations, err := Migrations(DialectOf(db))
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			statuses = append(statuses, MigrationStatus{Migration: m, Applied: applied[m.Version]})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// withMigrationLock runs fn on a single connection holding the migration
+// advisory lock, after making sure schema_migrations exists. SQLite has no
+// advisory locks, but each migration's transaction takes the database's
+// write lock, and an applied migration is skipped on the next start.
+func withMigrationLock(db *sql.DB, fn func(conn *sql.Conn, applied map[int]bool) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	appliedAt := "TIMESTAMPTZ NOT NULL DEFAULT now()"
+	if DialectOf(db) == SQLite {
+		appliedAt = "TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP"
+	} else {
+		// Advisory locks belong to the session, so lock and unlock on conn.
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+			return fmt.Errorf("acquiring migration lock: %w", err)
+		}
+		defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at `+appliedAt+`
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return fn(conn, applied)
+}
+
+func inTx(conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS students (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	age INTEGER NOT NULL,
+	email TEXT NOT NULL UNIQUE
+);
+
+-- Emails are unique regardless of case, matching the in-memory store.
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_lower_idx ON students (lower(email));
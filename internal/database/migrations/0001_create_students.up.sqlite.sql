@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS students (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	age INTEGER NOT NULL,
+	email TEXT NOT NULL
+);
+
+-- A named index rather than a column constraint, so that 0002 can drop it.
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_key ON students (email);
+
+-- Emails are unique regardless of case, matching the in-memory store.
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_lower_idx ON students (lower(email));
@@ -0,0 +1,11 @@
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS students_deleted_at_idx;
+DROP INDEX IF EXISTS students_email_lower_idx;
+CREATE UNIQUE INDEX students_email_lower_idx ON students (lower(email));
+ALTER TABLE students ADD CONSTRAINT students_email_key UNIQUE (email);
+
+ALTER TABLE students
+	DROP COLUMN deleted_at,
+	DROP COLUMN updated_at,
+	DROP COLUMN created_at;
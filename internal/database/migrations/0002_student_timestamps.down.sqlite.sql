@@ -0,0 +1,10 @@
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS students_deleted_at_idx;
+DROP INDEX IF EXISTS students_email_lower_idx;
+CREATE UNIQUE INDEX students_email_lower_idx ON students (lower(email));
+CREATE UNIQUE INDEX students_email_key ON students (email);
+
+ALTER TABLE students DROP COLUMN deleted_at;
+ALTER TABLE students DROP COLUMN updated_at;
+ALTER TABLE students DROP COLUMN created_at;
@@ -0,0 +1,11 @@
+ALTER TABLE students
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Soft-deleted students release their email so it can be reused.
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_email_key;
+DROP INDEX IF EXISTS students_email_lower_idx;
+CREATE UNIQUE INDEX students_email_lower_idx ON students (lower(email)) WHERE deleted_at IS NULL;
+
+CREATE INDEX students_deleted_at_idx ON students (deleted_at) WHERE deleted_at IS NOT NULL;
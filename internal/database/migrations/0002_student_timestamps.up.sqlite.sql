@@ -0,0 +1,20 @@
+-- SQLite cannot add a column whose default is not a constant, so the table
+-- is rebuilt instead. Dropping the old table drops students_email_key.
+CREATE TABLE students_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	age INTEGER NOT NULL,
+	email TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	deleted_at TIMESTAMP
+);
+
+INSERT INTO students_new (id, name, age, email) SELECT id, name, age, email FROM students;
+DROP TABLE students;
+ALTER TABLE students_new RENAME TO students;
+
+-- Soft-deleted students release their email so it can be reused.
+CREATE UNIQUE INDEX students_email_lower_idx ON students (lower(email)) WHERE deleted_at IS NULL;
+
+CREATE INDEX students_deleted_at_idx ON students (deleted_at) WHERE deleted_at IS NOT NULL;
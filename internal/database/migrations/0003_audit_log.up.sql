@@ -0,0 +1,24 @@
+CREATE TABLE audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	request_id TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	before JSONB,
+	after JSONB,
+	changes JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX audit_log_student_idx ON audit_log (student_id, created_at);
+
+-- Audit entries are immutable.
+CREATE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_log entries cannot be modified';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_immutable
+	BEFORE UPDATE OR DELETE ON audit_log
+	FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
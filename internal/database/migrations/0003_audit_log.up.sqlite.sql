@@ -0,0 +1,24 @@
+CREATE TABLE audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	request_id TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	before TEXT,
+	after TEXT,
+	changes TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX audit_log_student_idx ON audit_log (student_id, created_at);
+
+-- Audit entries are immutable.
+CREATE TRIGGER audit_log_immutable_update BEFORE UPDATE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log entries cannot be modified');
+END;
+
+CREATE TRIGGER audit_log_immutable_delete BEFORE DELETE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log entries cannot be modified');
+END;
@@ -0,0 +1,24 @@
+CREATE TABLE courses (
+	id SERIAL PRIMARY KEY,
+	code TEXT NOT NULL,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	credits INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX courses_code_lower_idx ON courses (lower(code));
+
+-- Enrollments go away with their student or course.
+CREATE TABLE enrollments (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	course_id INTEGER NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+	status TEXT NOT NULL DEFAULT 'active',
+	enrolled_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (student_id, course_id)
+);
+
+CREATE INDEX enrollments_course_idx ON enrollments (course_id);
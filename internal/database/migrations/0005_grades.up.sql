@@ -0,0 +1,13 @@
+CREATE TABLE grades (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	course_id INTEGER NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+	term TEXT NOT NULL,
+	score NUMERIC(5, 2) NOT NULL,
+	letter_grade TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (student_id, course_id, term)
+);
+
+CREATE INDEX grades_course_idx ON grades (course_id);
@@ -0,0 +1,13 @@
+CREATE TABLE grades (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	course_id INTEGER NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+	term TEXT NOT NULL,
+	score NUMERIC(5, 2) NOT NULL,
+	letter_grade TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	UNIQUE (student_id, course_id, term)
+);
+
+CREATE INDEX grades_course_idx ON grades (course_id);
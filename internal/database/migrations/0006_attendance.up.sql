@@ -0,0 +1,11 @@
+CREATE TABLE attendance (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	course_id INTEGER REFERENCES courses (id) ON DELETE SET NULL,
+	date DATE NOT NULL,
+	status TEXT NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (student_id, date)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE attendance (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	course_id INTEGER REFERENCES courses (id) ON DELETE SET NULL,
+	date DATE NOT NULL,
+	status TEXT NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	UNIQUE (student_id, date)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE notes (
+	id SERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	author TEXT NOT NULL,
+	body TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX notes_student_idx ON notes (student_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS students_attributes_idx;
+ALTER TABLE students DROP COLUMN IF EXISTS attributes;
+DROP TABLE IF EXISTS custom_fields;
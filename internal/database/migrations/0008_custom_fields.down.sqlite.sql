@@ -0,0 +1,2 @@
+ALTER TABLE students DROP COLUMN attributes;
+DROP TABLE IF EXISTS custom_fields;
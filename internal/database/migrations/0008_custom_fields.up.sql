@@ -0,0 +1,15 @@
+CREATE TABLE custom_fields (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	label TEXT NOT NULL DEFAULT '',
+	type TEXT NOT NULL CHECK (type IN ('string', 'integer', 'number', 'boolean', 'date', 'enum')),
+	required BOOLEAN NOT NULL DEFAULT false,
+	rules JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE students ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+-- Supports attribute filters, which are containment queries.
+CREATE INDEX students_attributes_idx ON students USING GIN (attributes jsonb_path_ops);
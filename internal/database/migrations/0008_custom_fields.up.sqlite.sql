@@ -0,0 +1,14 @@
+CREATE TABLE custom_fields (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	label TEXT NOT NULL DEFAULT '',
+	type TEXT NOT NULL CHECK (type IN ('string', 'integer', 'number', 'boolean', 'date', 'enum')),
+	required BOOLEAN NOT NULL DEFAULT false,
+	rules TEXT NOT NULL DEFAULT '{}',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- Attribute filters scan the table; SQLite has no index for JSON
+-- containment.
+ALTER TABLE students ADD COLUMN attributes TEXT NOT NULL DEFAULT '{}';
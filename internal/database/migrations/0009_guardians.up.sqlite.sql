@@ -0,0 +1,24 @@
+CREATE TABLE guardians (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	email TEXT NOT NULL DEFAULT '',
+	phone TEXT NOT NULL DEFAULT '',
+	preferred_contact TEXT NOT NULL DEFAULT 'email',
+	preferred_language TEXT NOT NULL DEFAULT 'en',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- Links go away with their student or guardian.
+CREATE TABLE student_guardians (
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	guardian_id INTEGER NOT NULL REFERENCES guardians (id) ON DELETE CASCADE,
+	relationship TEXT NOT NULL,
+	is_primary BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	PRIMARY KEY (student_id, guardian_id)
+);
+
+CREATE INDEX student_guardians_guardian_idx ON student_guardians (guardian_id);
+CREATE UNIQUE INDEX student_guardians_primary_idx ON student_guardians (student_id) WHERE is_primary;
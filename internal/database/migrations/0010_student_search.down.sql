@@ -0,0 +1,3 @@
+ALTER TABLE notes DROP COLUMN IF EXISTS search;
+DROP TABLE IF EXISTS student_summaries;
+ALTER TABLE students DROP COLUMN IF EXISTS search;
@@ -0,0 +1,21 @@
+-- Full-text search weighs a match in the name most, then the email, the
+-- summary and the notes, using the A to D labels of ts_rank.
+ALTER TABLE students ADD COLUMN search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', name), 'A') || setweight(to_tsvector('english', email), 'B')
+) STORED;
+CREATE INDEX students_search_idx ON students USING GIN (search);
+
+-- The latest generated summary of each student, kept so that it can be
+-- searched.
+CREATE TABLE student_summaries (
+	student_id INTEGER PRIMARY KEY REFERENCES students (id) ON DELETE CASCADE,
+	summary TEXT NOT NULL,
+	generated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	search tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', summary), 'C')) STORED
+);
+CREATE INDEX student_summaries_search_idx ON student_summaries USING GIN (search);
+
+ALTER TABLE notes ADD COLUMN search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', body), 'D')
+) STORED;
+CREATE INDEX notes_search_idx ON notes USING GIN (search);
@@ -0,0 +1,7 @@
+-- SQLite searches with the in-process index, so only the summaries are
+-- needed.
+CREATE TABLE student_summaries (
+	student_id INTEGER PRIMARY KEY REFERENCES students (id) ON DELETE CASCADE,
+	summary TEXT NOT NULL,
+	generated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS students_age_sort_idx;
+DROP INDEX IF EXISTS students_email_sort_idx;
+DROP INDEX IF EXISTS students_name_sort_idx;
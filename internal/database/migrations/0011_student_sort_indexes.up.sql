@@ -0,0 +1,6 @@
+-- Indexes for sorting and paging student listings. Names and emails sort
+-- byte by byte, as in the in-memory backend, which also lets the name and
+-- email indexes serve prefix filters.
+CREATE INDEX students_name_sort_idx ON students ((lower(name) COLLATE "C"), id);
+CREATE INDEX students_email_sort_idx ON students ((lower(email) COLLATE "C"), id);
+CREATE INDEX students_age_sort_idx ON students (age, id);
@@ -0,0 +1,9 @@
+CREATE TABLE api_keys (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	admin BOOLEAN NOT NULL DEFAULT false,
+	created_by TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
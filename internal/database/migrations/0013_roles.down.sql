@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS student_advisors;
+ALTER TABLE api_keys ADD COLUMN admin BOOLEAN NOT NULL DEFAULT false;
+UPDATE api_keys SET admin = (role = 'admin');
+ALTER TABLE api_keys DROP COLUMN role;
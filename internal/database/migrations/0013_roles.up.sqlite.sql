@@ -0,0 +1,16 @@
+-- Keys get a role in place of the admin flag.
+ALTER TABLE api_keys ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('admin', 'advisor', 'viewer'));
+UPDATE api_keys SET role = 'admin' WHERE admin;
+ALTER TABLE api_keys DROP COLUMN admin;
+
+-- Advisors are the subjects of principals, which are not rows of any table.
+-- Assignments go away with their student.
+CREATE TABLE student_advisors (
+	student_id INTEGER NOT NULL REFERENCES students (id) ON DELETE CASCADE,
+	advisor TEXT NOT NULL,
+	assigned_by TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	PRIMARY KEY (student_id, advisor)
+);
+
+CREATE INDEX student_advisors_advisor_idx ON student_advisors (advisor);
@@ -0,0 +1,50 @@
+-- Rows of other tenants are kept, and may then clash with the default
+-- tenant's emails, codes and names.
+DROP INDEX IF EXISTS guardians_tenant_idx;
+DROP INDEX IF EXISTS students_tenant_idx;
+DROP INDEX IF EXISTS students_name_sort_idx;
+DROP INDEX IF EXISTS students_email_sort_idx;
+DROP INDEX IF EXISTS students_age_sort_idx;
+CREATE INDEX students_name_sort_idx ON students ((lower(name) COLLATE BINARY), id);
+CREATE INDEX students_email_sort_idx ON students ((lower(email) COLLATE BINARY), id);
+CREATE INDEX students_age_sort_idx ON students (age, id);
+
+DROP INDEX IF EXISTS courses_code_lower_idx;
+CREATE UNIQUE INDEX courses_code_lower_idx ON courses (lower(code));
+DROP INDEX IF EXISTS students_email_lower_idx;
+CREATE UNIQUE INDEX students_email_lower_idx ON students (lower(email)) WHERE deleted_at IS NULL;
+
+CREATE TABLE custom_fields_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	label TEXT NOT NULL DEFAULT '',
+	type TEXT NOT NULL CHECK (type IN ('string', 'integer', 'number', 'boolean', 'date', 'enum')),
+	required BOOLEAN NOT NULL DEFAULT false,
+	rules TEXT NOT NULL DEFAULT '{}',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+INSERT INTO custom_fields_old (id, name, label, type, required, rules, created_at, updated_at)
+	SELECT id, name, label, type, required, rules, created_at, updated_at FROM custom_fields;
+DROP TABLE custom_fields;
+ALTER TABLE custom_fields_old RENAME TO custom_fields;
+
+CREATE TABLE api_keys_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	role TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('admin', 'advisor', 'viewer')),
+	created_by TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+INSERT INTO api_keys_old (id, name, prefix, key_hash, role, created_by, created_at)
+	SELECT id, name, prefix, key_hash, role, created_by, created_at FROM api_keys;
+DROP TABLE api_keys;
+ALTER TABLE api_keys_old RENAME TO api_keys;
+
+ALTER TABLE guardians DROP COLUMN tenant_id;
+ALTER TABLE courses DROP COLUMN tenant_id;
+ALTER TABLE students DROP COLUMN tenant_id;
@@ -0,0 +1,77 @@
+-- Each school on the deployment is a tenant. Students, courses, guardians,
+-- custom fields and API keys belong to one, and the records of a student
+-- to the student's tenant. Existing rows go to the default tenant.
+ALTER TABLE students ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE courses ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE guardians ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE custom_fields ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Emails, course codes, custom field names and key names are unique within
+-- a tenant, so that one school cannot learn another's by clashing with them.
+DROP INDEX students_email_lower_idx;
+CREATE UNIQUE INDEX students_email_lower_idx ON students (tenant_id, lower(email)) WHERE deleted_at IS NULL;
+DROP INDEX courses_code_lower_idx;
+CREATE UNIQUE INDEX courses_code_lower_idx ON courses (tenant_id, lower(code));
+ALTER TABLE custom_fields DROP CONSTRAINT custom_fields_name_key;
+ALTER TABLE custom_fields ADD CONSTRAINT custom_fields_tenant_id_name_key UNIQUE (tenant_id, name);
+ALTER TABLE api_keys DROP CONSTRAINT api_keys_name_key;
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_tenant_id_name_key UNIQUE (tenant_id, name);
+
+-- Listings are of a single tenant.
+CREATE INDEX students_tenant_idx ON students (tenant_id, id);
+DROP INDEX students_name_sort_idx;
+DROP INDEX students_email_sort_idx;
+DROP INDEX students_age_sort_idx;
+CREATE INDEX students_name_sort_idx ON students (tenant_id, (lower(name) COLLATE "C"), id);
+CREATE INDEX students_email_sort_idx ON students (tenant_id, (lower(email) COLLATE "C"), id);
+CREATE INDEX students_age_sort_idx ON students (tenant_id, age, id);
+CREATE INDEX guardians_tenant_idx ON guardians (tenant_id, id);
+
+-- Row-level security backs up the tenant conditions of the queries. The
+-- API sets app.tenant_id to the tenant each connection acts for when
+-- DB_ROW_LEVEL_SECURITY is on; while it is empty, as it is otherwise and
+-- for work that spans tenants such as the purge job, every row is visible.
+-- The records of a student are visible with the student.
+ALTER TABLE students ENABLE ROW LEVEL SECURITY;
+ALTER TABLE students FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON students
+	USING (coalesce(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+ALTER TABLE courses ENABLE ROW LEVEL SECURITY;
+ALTER TABLE courses FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON courses
+	USING (coalesce(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+ALTER TABLE guardians ENABLE ROW LEVEL SECURITY;
+ALTER TABLE guardians FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON guardians
+	USING (coalesce(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+ALTER TABLE custom_fields ENABLE ROW LEVEL SECURITY;
+ALTER TABLE custom_fields FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON custom_fields
+	USING (coalesce(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON api_keys
+	USING (coalesce(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+
+ALTER TABLE enrollments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE enrollments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON enrollments USING (student_id IN (SELECT id FROM students));
+ALTER TABLE grades ENABLE ROW LEVEL SECURITY;
+ALTER TABLE grades FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON grades USING (student_id IN (SELECT id FROM students));
+ALTER TABLE attendance ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attendance FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON attendance USING (student_id IN (SELECT id FROM students));
+ALTER TABLE notes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notes FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notes USING (student_id IN (SELECT id FROM students));
+ALTER TABLE student_guardians ENABLE ROW LEVEL SECURITY;
+ALTER TABLE student_guardians FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON student_guardians USING (student_id IN (SELECT id FROM students));
+ALTER TABLE student_summaries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE student_summaries FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON student_summaries USING (student_id IN (SELECT id FROM students));
+ALTER TABLE student_advisors ENABLE ROW LEVEL SECURITY;
+ALTER TABLE student_advisors FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON student_advisors USING (student_id IN (SELECT id FROM students));
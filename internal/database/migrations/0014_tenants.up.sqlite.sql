@@ -0,0 +1,60 @@
+-- Each school on the deployment is a tenant. Students, courses, guardians,
+-- custom fields and API keys belong to one, and the records of a student
+-- to the student's tenant. Existing rows go to the default tenant.
+ALTER TABLE students ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE courses ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE guardians ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- SQLite cannot drop the unique constraints on custom field and key names,
+-- so those tables are rebuilt with ones on the tenant and name instead.
+CREATE TABLE custom_fields_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	tenant_id TEXT NOT NULL DEFAULT 'default',
+	name TEXT NOT NULL,
+	label TEXT NOT NULL DEFAULT '',
+	type TEXT NOT NULL CHECK (type IN ('string', 'integer', 'number', 'boolean', 'date', 'enum')),
+	required BOOLEAN NOT NULL DEFAULT false,
+	rules TEXT NOT NULL DEFAULT '{}',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+INSERT INTO custom_fields_new (id, name, label, type, required, rules, created_at, updated_at)
+	SELECT id, name, label, type, required, rules, created_at, updated_at FROM custom_fields;
+DROP TABLE custom_fields;
+ALTER TABLE custom_fields_new RENAME TO custom_fields;
+CREATE UNIQUE INDEX custom_fields_tenant_id_name_key ON custom_fields (tenant_id, name);
+
+CREATE TABLE api_keys_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	tenant_id TEXT NOT NULL DEFAULT 'default',
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	role TEXT NOT NULL DEFAULT 'viewer' CHECK (role IN ('admin', 'advisor', 'viewer')),
+	created_by TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+INSERT INTO api_keys_new (id, name, prefix, key_hash, role, created_by, created_at)
+	SELECT id, name, prefix, key_hash, role, created_by, created_at FROM api_keys;
+DROP TABLE api_keys;
+ALTER TABLE api_keys_new RENAME TO api_keys;
+CREATE UNIQUE INDEX api_keys_tenant_id_name_key ON api_keys (tenant_id, name);
+
+-- Emails and course codes are unique within a tenant, so that one school
+-- cannot learn another's by clashing with them.
+DROP INDEX students_email_lower_idx;
+CREATE UNIQUE INDEX students_email_lower_idx ON students (tenant_id, lower(email)) WHERE deleted_at IS NULL;
+DROP INDEX courses_code_lower_idx;
+CREATE UNIQUE INDEX courses_code_lower_idx ON courses (tenant_id, lower(code));
+
+-- Listings are of a single tenant.
+CREATE INDEX students_tenant_idx ON students (tenant_id, id);
+DROP INDEX students_name_sort_idx;
+DROP INDEX students_email_sort_idx;
+DROP INDEX students_age_sort_idx;
+CREATE INDEX students_name_sort_idx ON students (tenant_id, (lower(name) COLLATE BINARY), id);
+CREATE INDEX students_email_sort_idx ON students (tenant_id, (lower(email) COLLATE BINARY), id);
+CREATE INDEX students_age_sort_idx ON students (tenant_id, age, id);
+CREATE INDEX guardians_tenant_idx ON guardians (tenant_id, id);
@@ -0,0 +1,2 @@
+-- The indexes stay built with the lower() the database package registers.
+SELECT 1;
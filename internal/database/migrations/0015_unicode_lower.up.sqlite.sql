@@ -0,0 +1,3 @@
+-- lower() now folds every letter, not only ASCII ones, so the indexes on
+-- lower(name), lower(email) and lower(code) are rebuilt to match it.
+REINDEX;
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Options size the connection pool and say how long Open waits for the
+// database. The zero value keeps database/sql's defaults and tries once.
+type Options struct {
+	// MaxOpenConns caps the open connections; 0 means no limit.
+	MaxOpenConns int
+	// MaxIdleConns is how many idle connections are kept for reuse; 0
+	// means database/sql's default of 2.
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections once they are this old, so that
+	// they move to new servers behind a load balancer; 0 keeps them.
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections that sit idle this long; 0 keeps
+	// them.
+	ConnMaxIdleTime time.Duration
+	// ConnectTimeout is how long Open keeps retrying while the database
+	// does not answer, as when the API starts before Postgres; 0 means a
+	// single attempt.
+	ConnectTimeout time.Duration
+	// RowLevelSecurity confines each Postgres query to the rows of the
+	// tenant of its context with row-level security, on top of the
+	// queries' own conditions. SQLite has no row-level security.
+	RowLevelSecurity bool
+}
+
+func (o Options) apply(db *sql.DB) {
+	if o.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(o.MaxOpenConns)
+	}
+	if o.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(o.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(o.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+}
+
+const (
+	initialConnectBackoff = 250 * time.Millisecond
+	maxConnectBackoff     = 10 * time.Second
+)
+
+// waitForDatabase pings db until it answers, backing off exponentially
+// between attempts, and gives up once timeout has passed or the error is
+// one that waiting will not fix.
+func waitForDatabase(db *sql.DB, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := initialConnectBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		if timeout <= 0 {
+			ctx, cancel = context.WithCancel(context.Background())
+		}
+		err := db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		wait := min(backoff, time.Until(deadline))
+		if !retryable(err) || wait <= 0 {
+			return err
+		}
+		log.Printf("Database is not ready (attempt %d): %v; retrying in %s", attempt, err, wait.Round(time.Millisecond))
+		time.Sleep(wait)
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+}
+
+// retryable reports whether a failed ping is worth repeating. Rejected
+// credentials are not; anything else may be a server that is still
+// starting.
+func retryable(err error) bool {
+	var pqErr *pq.Error
+	return !errors.As(err, &pqErr) || pqErr.Code.Class() != "28"
+}
+
+// PoolStats is a snapshot of the connection pool, from sql.DBStats.
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+// Stats reports the state of db's connection pool.
+func Stats(db *sql.DB) PoolStats {
+	s := db.Stats()
+	return PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
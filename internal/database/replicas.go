@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// replicaPingTimeout bounds the first ping of each replica, so that a
+// replica that is down does not hold up startup.
+const replicaPingTimeout = 5 * time.Second
+
+// Cluster is a primary database and the read replicas that follow it.
+// Writes always go to Primary; reads that can tolerate replication lag go
+// through Read.
+type Cluster struct {
+	Primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	name string
+	db   *sql.DB
+	// health is nil when health checks are off, in which case the replica
+	// is always tried.
+	health *Health
+}
+
+func (r *replica) healthy() bool {
+	return r.health == nil || r.health.Status().Healthy
+}
+
+// NewCluster connects to the read replicas at replicaURLs, which must be
+// Postgres, and pairs them with primary. Each replica is pinged every
+// healthInterval, and skipped while it does not answer; 0 turns the checks
+// off. A replica that is down at startup is not an error.
+func NewCluster(primary *sql.DB, replicaURLs []string, opts Options, healthInterval time.Duration) (*Cluster, error) {
+	c := &Cluster{Primary: primary}
+	if len(replicaURLs) > 0 && DialectOf(primary) != Postgres {
+		return nil, errors.New("read replicas need a Postgres primary")
+	}
+
+	for i, url := range replicaURLs {
+		if strings.HasPrefix(url, sqliteScheme) {
+			return nil, fmt.Errorf("replica %d: read replicas must be Postgres", i+1)
+		}
+		db, err := openPool(url, opts)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		r := &replica{name: fmt.Sprintf("Replica %d", i+1), db: db}
+		c.replicas = append(c.replicas, r)
+
+		ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if healthInterval <= 0 {
+			if err != nil {
+				log.Printf("%s is not answering: %v", r.name, err)
+			}
+			continue
+		}
+		r.health = newHealth(r.name)
+		r.health.record(err)
+		r.health.start(db, healthInterval)
+	}
+
+	return c, nil
+}
+
+// Close closes the replicas' connection pools; the primary is left to its
+// owner.
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, r := range c.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Read runs fn against the next healthy replica in turn. It runs fn against
+// the primary instead when there is no healthy replica, when ctx comes from
+// WithPrimary, or when the replica fails in a way the primary may not, in
+// which case the replica is also marked unhealthy until its next health
+// check.
+func (c *Cluster) Read(ctx context.Context, fn func(db *sql.DB) error) error {
+	r := c.pick(ctx)
+	if r == nil {
+		return fn(c.Primary)
+	}
+
+	err := fn(r.db)
+	if err == nil || ctx.Err() != nil || !replicaFailed(err) {
+		return err
+	}
+	log.Printf("%s failed, reading from the primary: %v", r.name, err)
+	if r.health != nil {
+		r.health.record(err)
+	}
+	return fn(c.Primary)
+}
+
+func (c *Cluster) pick(ctx context.Context) *replica {
+	if len(c.replicas) == 0 || PrimaryRequired(ctx) {
+		return nil
+	}
+	start := c.next.Add(1)
+	for i := range c.replicas {
+		r := c.replicas[(start+uint64(i))%uint64(len(c.replicas))]
+		if r.healthy() {
+			return r
+		}
+	}
+	return nil
+}
+
+// serializationFailure is what a hot standby reports when it cancels a
+// query that conflicts with the changes it is replaying.
+const serializationFailure = "40001"
+
+// replicaFailed reports whether a read that failed on a replica is worth
+// repeating on the primary.
+func replicaFailed(err error) bool {
+	if Unavailable(err) || Interrupted(err) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == serializationFailure
+}
+
+// ReplicaStatus describes a replica for the metrics endpoint.
+type ReplicaStatus struct {
+	Name   string        `json:"name"`
+	Health *HealthStatus `json:"health,omitempty"`
+	Pool   PoolStats     `json:"pool"`
+}
+
+// Replicas reports the health and pool statistics of each replica.
+func (c *Cluster) Replicas() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		s := ReplicaStatus{Name: r.name, Pool: Stats(r.db)}
+		if r.health != nil {
+			status := r.health.Status()
+			s.Health = &status
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a context whose reads all go to the primary, so that
+// a request that has written sees its own writes rather than a replica
+// that has yet to catch up.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryRequired reports whether ctx comes from WithPrimary.
+func PrimaryRequired(ctx context.Context) bool {
+	required, _ := ctx.Value(primaryKey{}).(bool)
+	return required
+}
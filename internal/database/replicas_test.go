@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// stubDB returns a pool that is never connected; the tests only check which
+// pool Read hands out.
+func stubDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("postgres", "postgres://stub.invalid/stub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testCluster returns a cluster of stub pools whose replicas are health
+// checked only when the tests record a result.
+func testCluster(t *testing.T, replicas int) *Cluster {
+	t.Helper()
+	c := &Cluster{Primary: stubDB(t)}
+	for i := 0; i < replicas; i++ {
+		c.replicas = append(c.replicas, &replica{name: "Replica", db: stubDB(t), health: newHealth("Replica")})
+	}
+	return c
+}
+
+// readFrom returns the pools that n reads run against, counted by pool.
+func readFrom(t *testing.T, ctx context.Context, c *Cluster, n int) map[*sql.DB]int {
+	t.Helper()
+	used := map[*sql.DB]int{}
+	for i := 0; i < n; i++ {
+		if err := c.Read(ctx, func(db *sql.DB) error {
+			used[db]++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return used
+}
+
+func TestClusterReadsReplicasInTurn(t *testing.T) {
+	c := testCluster(t, 3)
+	used := readFrom(t, context.Background(), c, 9)
+	for i, r := range c.replicas {
+		if used[r.db] != 3 {
+			t.Errorf("replica %d served %d of 9 reads, want 3", i+1, used[r.db])
+		}
+	}
+	if used[c.Primary] != 0 {
+		t.Errorf("the primary served %d reads", used[c.Primary])
+	}
+}
+
+func TestClusterSkipsUnhealthyReplicas(t *testing.T) {
+	c := testCluster(t, 2)
+	down := errors.New("connection refused")
+	ctx := context.Background()
+
+	c.replicas[0].health.record(down)
+	if used := readFrom(t, ctx, c, 4); used[c.replicas[1].db] != 4 {
+		t.Errorf("expected the healthy replica to serve every read, got %v", used)
+	}
+
+	c.replicas[1].health.record(down)
+	if used := readFrom(t, ctx, c, 2); used[c.Primary] != 2 {
+		t.Errorf("expected the primary to serve every read with no healthy replica, got %v", used)
+	}
+
+	c.replicas[0].health.record(nil)
+	if used := readFrom(t, ctx, c, 2); used[c.replicas[0].db] != 2 {
+		t.Errorf("expected the recovered replica to serve every read, got %v", used)
+	}
+}
+
+func TestClusterRetriesFailedReadsOnThePrimary(t *testing.T) {
+	c := testCluster(t, 1)
+	r := c.replicas[0]
+	ctx := context.Background()
+
+	// A failure of the query itself is not retried.
+	var tries []*sql.DB
+	err := c.Read(ctx, func(db *sql.DB) error {
+		tries = append(tries, db)
+		return sql.ErrNoRows
+	})
+	if !errors.Is(err, sql.ErrNoRows) || len(tries) != 1 || !r.healthy() {
+		t.Errorf("expected the error from the replica alone, got %v after %d tries", err, len(tries))
+	}
+
+	tries = nil
+	err = c.Read(ctx, func(db *sql.DB) error {
+		tries = append(tries, db)
+		if db == r.db {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil || len(tries) != 2 || tries[0] != r.db || tries[1] != c.Primary {
+		t.Errorf("expected the read to move from the replica to the primary, got %v", err)
+	}
+	if r.healthy() {
+		t.Error("expected the replica to be marked unhealthy")
+	}
+}
+
+func TestClusterReadsThePrimaryWhenRequired(t *testing.T) {
+	c := testCluster(t, 2)
+	if used := readFrom(t, WithPrimary(context.Background()), c, 4); used[c.Primary] != 4 {
+		t.Errorf("expected the primary to serve every read, got %v", used)
+	}
+}
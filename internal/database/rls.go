@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/lib/pq"
+)
+
+// With Options.RowLevelSecurity, each Postgres connection sets
+// app.tenant_id to the tenant of the context of each query, and the
+// row-level security policies of the tenants migration hide the rows of
+// every other tenant. Work without a tenant, such as the purge job and API
+// key lookups, sets it to "" and sees every row. The policies do not bind
+// superusers or roles with BYPASSRLS.
+
+// unknownTenant is never a tenant ID, and stands for a connection whose
+// app.tenant_id is not known after a failure.
+const unknownTenant = "?"
+
+func openRowLevelSecurity(databaseURL string) (driver.Connector, error) {
+	connector, err := pq.NewConnector(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantConnector{connector}, nil
+}
+
+type tenantConnector struct {
+	driver.Connector
+}
+
+func (c *tenantConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantConn{Conn: conn}, nil
+}
+
+// tenantConn sets app.tenant_id before each statement that runs for
+// another tenant than the last. Within a transaction it is set when the
+// transaction begins, since a setting changed in a transaction that rolls
+// back reverts.
+type tenantConn struct {
+	driver.Conn
+	// tenant is the ID app.tenant_id is set to.
+	tenant string
+	inTx   bool
+}
+
+// use sets app.tenant_id to the tenant of ctx, or "" if there is none.
+func (c *tenantConn) use(ctx context.Context) error {
+	var id string
+	if t, ok := tenant.FromContext(ctx); ok {
+		id = t.ID
+	}
+	if c.inTx || id == c.tenant {
+		return nil
+	}
+	c.tenant = unknownTenant
+	_, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, "SELECT set_config('app.tenant_id', $1, false)",
+		[]driver.NamedValue{{Ordinal: 1, Value: id}})
+	if err != nil {
+		return err
+	}
+	c.tenant = id
+	return nil
+}
+
+func (c *tenantConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *tenantConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c *tenantConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *tenantConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	tx, err := c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.inTx = true
+	return &tenantTx{tx, c}, nil
+}
+
+func (c *tenantConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c *tenantConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *tenantConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+func (c *tenantConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *tenantConn) IsValid() bool {
+	return c.Conn.(driver.Validator).IsValid()
+}
+
+type tenantTx struct {
+	driver.Tx
+	conn *tenantConn
+}
+
+func (t *tenantTx) Commit() error {
+	t.conn.inTx = false
+	return t.Tx.Commit()
+}
+
+func (t *tenantTx) Rollback() error {
+	t.conn.inTx = false
+	return t.Tx.Rollback()
+}
@@ -0,0 +1,362 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// The SQLite backend runs the same queries as Postgres, apart from the
+// fragments the models take from the Dialect. The driver below wraps
+// modernc.org/sqlite to register the functions those fragments call, and
+// reports constraint failures as *ConstraintError with the Postgres
+// constraint name, where SQLite says which constraint it was.
+
+// sqliteParams are added to every SQLite DSN. Foreign keys are enforced as
+// in Postgres, readers run alongside the writer, and busy connections wait
+// rather than fail. Transactions take the write lock up front so that two
+// read-then-write transactions cannot deadlock on the upgrade.
+var sqliteParams = []string{
+	"_pragma=foreign_keys(1)",
+	"_pragma=journal_mode(WAL)",
+	"_pragma=busy_timeout(5000)",
+	"_txlock=immediate",
+	"_time_format=sqlite",
+}
+
+// sqliteTimeLayout matches what the driver writes for time.Time values with
+// _time_format=sqlite, so stored times compare correctly as text.
+const sqliteTimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+// baseSQLiteDriver is modernc's registered driver, the one that knows about
+// the functions registered in init.
+var baseSQLiteDriver driver.Driver
+
+func init() {
+	sqlite.MustRegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+		return time.Now().UTC().Format(sqliteTimeLayout), nil
+	})
+	sqlite.MustRegisterDeterministicScalarFunction("lower", 1, unicodeLower)
+	sqlite.MustRegisterDeterministicScalarFunction("jsonb_contains", 2, jsonbContains)
+	sqlite.MustRegisterDeterministicScalarFunction("jsonb_exists", 2, jsonbExists)
+
+	db, err := sql.Open("sqlite", "")
+	if err != nil {
+		panic(err)
+	}
+	baseSQLiteDriver = db.Driver()
+	db.Close()
+}
+
+// openSQLite opens the SQLite database at path, which may carry extra
+// driver parameters after a '?'.
+func openSQLite(path string) (*sql.DB, error) {
+	path, query, _ := strings.Cut(path, "?")
+	if path == "" {
+		return nil, errors.New("sqlite database URL has no file path")
+	}
+	params := sqliteParams
+	if query != "" {
+		params = append(params[:len(params):len(params)], query)
+	}
+	return sql.OpenDB(&sqliteConnector{dsn: "file:" + path + "?" + strings.Join(params, "&")}), nil
+}
+
+// sqliteDriver exists so that DialectOf can recognize SQLite databases.
+type sqliteDriver struct{}
+
+func (sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	return (&sqliteConnector{dsn: dsn}).Connect(context.Background())
+}
+
+type sqliteConnector struct {
+	dsn string
+}
+
+func (c *sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := baseSQLiteDriver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{conn}, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver { return &sqliteDriver{} }
+
+// sqliteConn translates errors on the way out.
+type sqliteConn struct {
+	driver.Conn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+	if err != nil {
+		return nil, c.translateError(ctx, err)
+	}
+	return &sqliteStmt{stmt, c}, nil
+}
+
+func (c *sqliteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	return res, c.translateError(ctx, err)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, c.translateError(ctx, err)
+	}
+	return &sqliteRows{rows, c}, nil
+}
+
+func (c *sqliteConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+func (c *sqliteConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *sqliteConn) IsValid() bool {
+	return c.Conn.(driver.Validator).IsValid()
+}
+
+// CheckNamedValue stores byte slices, which only ever hold JSON, as text so
+// the JSON functions can read them, and times in UTC so they compare as
+// text.
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case []byte:
+		nv.Value = string(v)
+	case time.Time:
+		nv.Value = v.UTC()
+	default:
+		nv.Value = v
+	}
+	return nil
+}
+
+type sqliteStmt struct {
+	driver.Stmt
+	conn *sqliteConn
+}
+
+func (s *sqliteStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	res, err := s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+	return res, s.conn.translateError(ctx, err)
+}
+
+func (s *sqliteStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
+	if err != nil {
+		return nil, s.conn.translateError(ctx, err)
+	}
+	return &sqliteRows{rows, s.conn}, nil
+}
+
+// sqliteRows translates errors from Next, where a failing INSERT ...
+// RETURNING reports its constraint violation.
+type sqliteRows struct {
+	driver.Rows
+	conn *sqliteConn
+}
+
+func (r *sqliteRows) Next(dest []driver.Value) error {
+	return r.conn.translateError(context.Background(), r.Rows.Next(dest))
+}
+
+// translateError turns constraint failures into *ConstraintError.
+func (c *sqliteConn) translateError(ctx context.Context, err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return &ConstraintError{Code: UniqueViolation, Constraint: c.uniqueConstraint(ctx, sqliteErr), Err: err}
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		// SQLite does not say which foreign key failed.
+		return &ConstraintError{Code: ForeignKeyViolation, Err: err}
+	}
+	return err
+}
+
+// uniqueConstraint names the violated constraint the way Postgres would.
+// SQLite names expression indexes, as in "index 'courses_code_lower_idx'",
+// but otherwise only lists the columns, as in "enrollments.student_id,
+// enrollments.course_id", so the unique index on those columns is looked
+// up. Indexes SQLite created for UNIQUE and PRIMARY KEY constraints get the
+// names Postgres gives those constraints.
+func (c *sqliteConn) uniqueConstraint(ctx context.Context, err *sqlite.Error) string {
+	msg := err.Error()
+	if i := strings.LastIndex(msg, "failed: "); i >= 0 {
+		msg = msg[i+len("failed: "):]
+	}
+	if i := strings.LastIndex(msg, " ("); i >= 0 {
+		msg = msg[:i]
+	}
+	if name, ok := strings.CutPrefix(msg, "index '"); ok {
+		return strings.TrimSuffix(name, "'")
+	}
+
+	var table string
+	var columns []string
+	for _, qualified := range strings.Split(msg, ", ") {
+		var column string
+		table, column, _ = strings.Cut(qualified, ".")
+		columns = append(columns, column)
+	}
+	name := table + "_" + strings.Join(columns, "_") + "_key"
+
+	rows, qerr := c.Conn.(driver.QueryerContext).QueryContext(ctx, `
+		SELECT il.name, il.origin,
+			(SELECT group_concat(name, ', ') FROM (SELECT name FROM pragma_index_info(il.name) ORDER BY seqno))
+		FROM pragma_index_list(?1) AS il WHERE il."unique"`,
+		[]driver.NamedValue{{Ordinal: 1, Value: table}})
+	if qerr != nil {
+		return name
+	}
+	defer rows.Close()
+	index := make([]driver.Value, 3)
+	for rows.Next(index) == nil {
+		if index[2] != strings.Join(columns, ", ") {
+			continue
+		}
+		switch index[1] {
+		case "c":
+			return index[0].(string)
+		case "pk":
+			return table + "_pkey"
+		}
+		return name
+	}
+	if len(columns) == 1 && c.isRowID(ctx, table, columns[0]) {
+		return table + "_pkey"
+	}
+	return name
+}
+
+// isRowID reports whether column is the INTEGER PRIMARY KEY of table,
+// which SQLite keeps as the rowid rather than in an index.
+func (c *sqliteConn) isRowID(ctx context.Context, table, column string) bool {
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx,
+		`SELECT pk FROM pragma_table_info(?1) WHERE name = ?2`,
+		[]driver.NamedValue{{Ordinal: 1, Value: table}, {Ordinal: 2, Value: column}})
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+	pk := make([]driver.Value, 1)
+	return rows.Next(pk) == nil && pk[0] == int64(1)
+}
+
+// jsonArg decodes a JSON function argument. ok is false for SQL NULL and
+// for values that are not JSON.
+func jsonArg(v driver.Value) (doc interface{}, ok bool) {
+	var text []byte
+	switch v := v.(type) {
+	case string:
+		text = []byte(v)
+	case []byte:
+		text = v
+	default:
+		return nil, false
+	}
+	if err := json.Unmarshal(text, &doc); err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// unicodeLower replaces SQLite's lower(), which leaves all but ASCII
+// letters alone, with the lowercasing of Postgres and of the listings'
+// sort keys.
+func unicodeLower(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	switch v := args[0].(type) {
+	case string:
+		return strings.ToLower(v), nil
+	case []byte:
+		return strings.ToLower(string(v)), nil
+	}
+	return args[0], nil
+}
+
+// jsonbContains implements the jsonb @> operator.
+func jsonbContains(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	container, ok := jsonArg(args[0])
+	if !ok {
+		return nil, nil
+	}
+	contained, ok := jsonArg(args[1])
+	if !ok {
+		return nil, nil
+	}
+	return jsonContains(container, contained), nil
+}
+
+// jsonContains reports whether b is contained in a: objects by key,
+// arrays by element regardless of order, scalars by equality.
+func jsonContains(a, b interface{}) bool {
+	switch b := b.(type) {
+	case map[string]interface{}:
+		a, ok := a.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, bv := range b {
+			if av, ok := a[key]; !ok || !jsonContains(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := a.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, bv := range b {
+			found := false
+			for _, av := range a {
+				if found = jsonContains(av, bv); found {
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// jsonbExists implements the jsonb ? operator for objects.
+func jsonbExists(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	doc, ok := jsonArg(args[0])
+	key, isText := args[1].(string)
+	if !ok || !isText {
+		return nil, nil
+	}
+	object, _ := doc.(map[string]interface{})
+	_, exists := object[key]
+	return exists, nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteRunsTheDialectFragments(t *testing.T) {
+	db, err := Connect("sqlite://"+filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	d := DialectOf(db)
+	if d != SQLite {
+		t.Fatalf("expected SQLite, got %v", d)
+	}
+
+	doc := `{"name":"a","tags":["x","y"],"b.c":1}`
+	for _, tc := range []struct {
+		query string
+		args  []interface{}
+		want  string
+	}{
+		{"SELECT " + d.JSONContains("$1", "$2"), []interface{}{doc, `{"tags":["y"]}`}, "1"},
+		{"SELECT " + d.JSONContains("$1", "$2"), []interface{}{doc, `{"name":"b"}`}, "0"},
+		{"SELECT " + d.JSONHasKey("$1", "$2"), []interface{}{doc, "b.c"}, "1"},
+		{"SELECT " + d.JSONHasKey("$1", "$2"), []interface{}{doc, "b"}, "0"},
+		{"SELECT " + d.JSONRemoveKey("$1", "$2"), []interface{}{doc, "b.c"}, `{"name":"a","tags":["x","y"]}`},
+		{"SELECT " + d.JSONRemoveKey("$1", "$2"), []interface{}{doc, "missing"}, `{"name":"a","tags":["x","y"],"b.c":1}`},
+		{"SELECT " + d.Cast("$1", "date"), []interface{}{"2024-10-01"}, "2024-10-01"},
+		{"SELECT " + d.Cast("$1", "date") + " IS NULL", []interface{}{nil}, "1"},
+		{"SELECT 'B' < 'a' " + d.ByteOrder(), nil, "1"},
+		{"SELECT count(*) FROM students WHERE id = $1" + d.ForUpdate(), []interface{}{1}, "0"},
+	} {
+		var got sql.NullString
+		if err := db.QueryRow(tc.query, tc.args...).Scan(&got); err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+		} else if got.String != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.query, got.String, tc.want)
+		}
+	}
+}
+
+func TestSQLiteNamesUniqueConstraintsLikePostgres(t *testing.T) {
+	db, err := Connect("sqlite://"+filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	mustExec := func(query string, args ...interface{}) {
+		t.Helper()
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustExec("INSERT INTO students (name, age, email) VALUES ('A', 20, 'a@example.com')")
+	mustExec("INSERT INTO courses (code, title) VALUES ('C1', 'Course')")
+	mustExec("INSERT INTO enrollments (student_id, course_id) VALUES (1, 1)")
+
+	for query, want := range map[string]string{
+		// An expression index, named by SQLite.
+		"INSERT INTO students (name, age, email) VALUES ('B', 20, 'A@EXAMPLE.COM')": "students_email_lower_idx",
+		// A table constraint, looked up by its columns.
+		"INSERT INTO enrollments (student_id, course_id) VALUES (1, 1)": "enrollments_student_id_course_id_key",
+		// A primary key.
+		"INSERT INTO students (id, name, age, email) VALUES (1, 'C', 20, 'c@example.com')": "students_pkey",
+	} {
+		_, err := db.ExecContext(ctx, query)
+		var ce *ConstraintError
+		if !errors.As(err, &ce) || ce.Code != UniqueViolation || ce.Constraint != want {
+			t.Errorf("%s: got %v, want a unique violation of %s", query, err, want)
+		}
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT INTO enrollments (student_id, course_id) VALUES (1, 99)")
+	var ce *ConstraintError
+	if !errors.As(err, &ce) || ce.Code != ForeignKeyViolation {
+		t.Errorf("expected a foreign key violation, got %v", err)
+	}
+}
+
+func TestRowLevelSecurityNeedsPostgres(t *testing.T) {
+	db, err := Connect("sqlite://"+filepath.Join(t.TempDir(), "test.db"), Options{RowLevelSecurity: true})
+	if err == nil {
+		db.Close()
+		t.Fatal("expected SQLite with row-level security to be rejected")
+	}
+	if !strings.Contains(err.Error(), "row-level security needs Postgres") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
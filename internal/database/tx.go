@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+)
+
+// QueryTimeout bounds each repository call made through WithQueryTimeout.
+// Zero means queries are only bounded by their caller's context. It is set
+// once at startup.
+var QueryTimeout = 5 * time.Second
+
+// WithQueryTimeout derives the context for one repository call from ctx,
+// usually the request's, so that the call stops when the client goes away or
+// QueryTimeout passes, whichever is first.
+func WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, QueryTimeout)
+}
+
+// Tx is a transaction begun by WithTx or WithSnapshot. Unlike a *sql.Tx,
+// it can tell DialectOf which database it runs on.
+type Tx struct {
+	*sql.Tx
+	db *sql.DB
+}
+
+// Driver returns the driver of the transaction's database.
+func (tx *Tx) Driver() driver.Driver {
+	return tx.db.Driver()
+}
+
+// WithTx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise. The transaction is rolled back if ctx ends first.
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *Tx) error) error {
+	sqlTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	tx := &Tx{sqlTx, db}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// WithSnapshot runs fn in a read-only transaction that sees the database as
+// it was when fn first read it, however many queries fn makes and however
+// long it takes. The transaction is rolled back afterwards.
+func WithSnapshot(ctx context.Context, db *sql.DB, fn func(tx *Tx) error) error {
+	sqlTx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer sqlTx.Rollback()
+	return fn(&Tx{sqlTx, db})
+}
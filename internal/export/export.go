@@ -0,0 +1,322 @@
+// Package export writes students in the formats other systems consume:
+// JSON, newline-delimited JSON, CSV and TSV. Every format is written one
+// record at a time so that exports can be streamed.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// Formats.
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatTSV    = "tsv"
+)
+
+var contentTypes = map[string]string{
+	FormatJSON:   "application/json",
+	FormatNDJSON: "application/x-ndjson",
+	FormatCSV:    "text/csv",
+	FormatTSV:    "text/tab-separated-values",
+}
+
+// ContentType is the media type of a format.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Negotiate picks the format for an Accept header, preferring the media
+// types with the highest quality and falling back to JSON when none of them
+// is supported.
+func Negotiate(accept string) string {
+	best, bestQ := FormatJSON, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		for format, ct := range contentTypes {
+			if mediaType == ct && q > bestQ {
+				best, bestQ = format, q
+			}
+		}
+	}
+	return best
+}
+
+// FormatFromRequest reads the format from the format query parameter or,
+// failing that, the Accept header.
+func FormatFromRequest(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if _, ok := contentTypes[format]; !ok {
+			return "", apperror.BadRequest("format must be json, ndjson, csv or tsv")
+		}
+		return format, nil
+	}
+	return Negotiate(r.Header.Get("Accept")), nil
+}
+
+// Record is a student as exported, optionally with its summary.
+type Record struct {
+	models.Student
+	Summary      string `json:"summary,omitempty"`
+	SummaryError string `json:"summary_error,omitempty"`
+}
+
+// Writer writes records in one format. Close must be called after the last
+// record to finish the output.
+type Writer interface {
+	Write(rec Record) error
+	// Flush writes out any buffered records.
+	Flush() error
+	Close() error
+}
+
+// NewWriter returns a writer for the format. Tabular formats get a column
+// for each custom field, and summary columns if withSummaries is set.
+func NewWriter(w io.Writer, format string, fields []models.CustomField, withSummaries bool) Writer {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}
+	case FormatCSV, FormatTSV:
+		cw := csv.NewWriter(w)
+		if format == FormatTSV {
+			cw.Comma = '\t'
+		}
+		return &tableWriter{w: cw, fields: fields, withSummaries: withSummaries}
+	}
+	return &jsonWriter{w: w}
+}
+
+// WriteAll writes students to an HTTP response in the format.
+func WriteAll(w http.ResponseWriter, format string, students []models.Student, fields []models.CustomField) error {
+	w.Header().Set("Content-Type", ContentType(format))
+	ew := NewWriter(w, format, fields, false)
+	for _, s := range students {
+		if err := ew.Write(Record{Student: s}); err != nil {
+			return err
+		}
+	}
+	return ew.Close()
+}
+
+// flushEvery is the number of records streamed between flushes of the
+// response.
+const flushEvery = 100
+
+// StreamWriter writes an export download to an HTTP response, flushing it
+// as it goes so that clients receive records while the rest are read. The
+// response headers are only set once the first record is written, so an
+// error before then can still be answered with a problem response.
+type StreamWriter struct {
+	w             http.ResponseWriter
+	format        string
+	withSummaries bool
+	ew            Writer
+	count         int
+}
+
+// Stream returns a StreamWriter for w.
+func Stream(w http.ResponseWriter, format string, fields []models.CustomField, withSummaries bool) *StreamWriter {
+	return &StreamWriter{w: w, format: format, withSummaries: withSummaries, ew: NewWriter(w, format, fields, withSummaries)}
+}
+
+// Started reports whether anything has been written to the response.
+func (sw *StreamWriter) Started() bool {
+	return sw.count > 0
+}
+
+func (sw *StreamWriter) start() {
+	sw.w.Header().Set("Content-Type", ContentType(sw.format))
+	sw.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=students.%s", sw.format))
+}
+
+func (sw *StreamWriter) Write(rec Record) error {
+	if sw.count == 0 {
+		sw.start()
+	}
+	sw.count++
+	if err := sw.ew.Write(rec); err != nil {
+		return err
+	}
+	// Summaries take seconds each, so every one is sent as soon as it is
+	// ready.
+	if sw.withSummaries || sw.count%flushEvery == 0 {
+		return sw.Flush()
+	}
+	return nil
+}
+
+func (sw *StreamWriter) Flush() error {
+	if err := sw.ew.Flush(); err != nil {
+		return err
+	}
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func (sw *StreamWriter) Close() error {
+	if sw.count == 0 {
+		sw.start()
+	}
+	return sw.ew.Close()
+}
+
+// SummaryError describes why a student's summary could not be generated,
+// without exposing internal errors.
+func SummaryError(studentID int, err error) string {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Kind == apperror.KindInternal {
+		log.Printf("exporting summary of student %d: %v", studentID, err)
+		return "the summary could not be generated"
+	}
+	return appErr.Message
+}
+
+// jsonWriter streams a JSON array.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (jw *jsonWriter) Write(rec Record) error {
+	sep := ","
+	if jw.count == 0 {
+		sep = "["
+	}
+	jw.count++
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(jw.w, "%s%s", sep, data)
+	return err
+}
+
+func (jw *jsonWriter) Flush() error {
+	return nil
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.count == 0 {
+		_, err := io.WriteString(jw.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(jw.w, "]\n")
+	return err
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(rec Record) error {
+	return nw.enc.Encode(rec)
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+// tableWriter writes CSV or TSV with a header row. Custom fields use their
+// names as headings, so that an export can be imported again.
+type tableWriter struct {
+	w             *csv.Writer
+	fields        []models.CustomField
+	withSummaries bool
+	wroteHeader   bool
+}
+
+func (tw *tableWriter) header() []string {
+	header := []string{"id", "name", "age", "email", "created_at", "updated_at", "deleted_at"}
+	for _, f := range tw.fields {
+		header = append(header, f.Name)
+	}
+	if tw.withSummaries {
+		header = append(header, "summary", "summary_error")
+	}
+	return header
+}
+
+func (tw *tableWriter) Write(rec Record) error {
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		if err := tw.w.Write(tw.header()); err != nil {
+			return err
+		}
+	}
+
+	s := rec.Student
+	row := []string{strconv.Itoa(s.ID), s.Name, strconv.Itoa(s.Age), s.Email,
+		formatTime(&s.CreatedAt), formatTime(&s.UpdatedAt), formatTime(s.DeletedAt)}
+	for _, f := range tw.fields {
+		row = append(row, formatValue(s.Attributes[f.Name]))
+	}
+	if tw.withSummaries {
+		row = append(row, rec.Summary, rec.SummaryError)
+	}
+	return tw.w.Write(row)
+}
+
+func (tw *tableWriter) Flush() error {
+	tw.w.Flush()
+	return tw.w.Error()
+}
+
+func (tw *tableWriter) Close() error {
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		if err := tw.w.Write(tw.header()); err != nil {
+			return err
+		}
+	}
+	tw.w.Flush()
+	return tw.w.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatValue writes an attribute value the way the importer reads it back.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
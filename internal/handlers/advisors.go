@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetStudentAdvisors(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var advisors []models.StudentAdvisor
+	err = h.read(r.Context(), func(db *sql.DB) error {
+		if _, err := models.GetStudent(r.Context(), db, id); err != nil {
+			return err
+		}
+		advisors, err = models.GetStudentAdvisors(r.Context(), db, id)
+		return err
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(advisors)
+}
+
+// AssignAdvisor assigns the student to the advisor in the body, who may
+// then see and change the student's records.
+func (h *Handler) AssignAdvisor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var a models.StudentAdvisor
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	a.StudentID = id
+	a.AssignedBy = middleware.Actor(r)
+
+	if err := a.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := a.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+func (h *Handler) UnassignAdvisor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	if err := models.DeleteStudentAdvisor(r.Context(), h.DB, id, mux.Vars(r)["advisor"]); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateAPIKey generates a key. The response is the only place the key
+// itself appears; only its hash is stored.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var key models.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := key.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	secret, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	key.Prefix = prefix
+	key.CreatedBy = middleware.Actor(r)
+	if err := key.Create(r.Context(), h.DB, hash); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.NewAPIKey{APIKey: key, Key: secret})
+}
+
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := models.GetAPIKeys(r.Context(), h.DB)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (h *Handler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid API key ID"))
+		return
+	}
+
+	if err := models.DeleteAPIKey(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetStudentAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	dr, err := models.ParseDateRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	records, err := models.GetStudentAttendance(r.Context(), h.DB, id, dr)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(records)
+}
+
+// GetAttendanceStats reports attendance percentage, streaks and chronic
+// absence over an optional from/to range.
+func (h *Handler) GetAttendanceStats(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	dr, err := models.ParseDateRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	records, err := models.GetStudentAttendance(r.Context(), h.DB, id, dr)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.CalculateAttendanceStats(records, dr))
+}
+
+func (h *Handler) RecordAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var record models.AttendanceRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	record.StudentID = id
+
+	if err := record.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := record.Save(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(record)
+}
+
+func (h *Handler) DeleteAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	if err := models.DeleteAttendance(r.Context(), h.DB, id, mux.Vars(r)["date"]); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecordClassAttendance saves one day's attendance for several students of a
+// course. Either every record is saved or none are.
+func (h *Handler) RecordClassAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+		return
+	}
+
+	var submission models.ClassAttendance
+	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := submission.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	records := submission.AttendanceRecords(courseID)
+	err = database.WithTx(r.Context(), h.DB, func(tx *database.Tx) error {
+		if _, err := models.GetCourse(r.Context(), tx, courseID); err != nil {
+			return err
+		}
+		// Advisors may only take the attendance of their own students.
+		if advisor := auth.AdvisorScope(r.Context()); advisor != "" {
+			for _, record := range records {
+				assigned, err := models.IsAssigned(r.Context(), tx, advisor, record.StudentID)
+				if err != nil {
+					return err
+				}
+				if !assigned {
+					return apperror.Forbidden("student %d is not assigned to you", record.StudentID)
+				}
+			}
+		}
+		for i := range records {
+			if err := records[i].Save(r.Context(), tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(records)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) CreateCourse(w http.ResponseWriter, r *http.Request) {
+	var course models.Course
+	if err := json.NewDecoder(r.Body).Decode(&course); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := course.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := course.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(course)
+}
+
+func (h *Handler) GetAllCourses(w http.ResponseWriter, r *http.Request) {
+	courses, err := models.GetAllCourses(r.Context(), h.DB)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(courses)
+}
+
+func (h *Handler) GetCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+		return
+	}
+
+	course, err := models.GetCourse(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(course)
+}
+
+func (h *Handler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+		return
+	}
+
+	var course models.Course
+	if err := json.NewDecoder(r.Body).Decode(&course); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := course.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := course.Update(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(course)
+}
+
+func (h *Handler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+		return
+	}
+
+	if err := models.DeleteCourse(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetStudentEnrollments(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	enrollments, err := models.GetStudentEnrollments(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(enrollments)
+}
+
+func (h *Handler) CreateEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var enrollment models.Enrollment
+	if err := json.NewDecoder(r.Body).Decode(&enrollment); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	enrollment.StudentID = id
+
+	if err := enrollment.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := enrollment.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+func (h *Handler) UpdateEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, courseID, err := enrollmentIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	var enrollment models.Enrollment
+	if err := json.NewDecoder(r.Body).Decode(&enrollment); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	enrollment.StudentID = id
+	enrollment.CourseID = courseID
+
+	if err := enrollment.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	updated, err := models.UpdateEnrollmentStatus(r.Context(), h.DB, id, courseID, enrollment.Status)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (h *Handler) DeleteEnrollment(w http.ResponseWriter, r *http.Request) {
+	id, courseID, err := enrollmentIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := models.DeleteEnrollment(r.Context(), h.DB, id, courseID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// enrollmentIDs parses the student and course IDs of an enrollment route.
+func enrollmentIDs(r *http.Request) (int, int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid student ID")
+	}
+	courseID, err := strconv.Atoi(mux.Vars(r)["courseId"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid course ID")
+	}
+	return id, courseID, nil
+}
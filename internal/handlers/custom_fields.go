@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	var field models.CustomField
+	if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := field.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := field.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(field)
+}
+
+func (h *Handler) GetCustomFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := models.GetCustomFields(r.Context(), h.DB)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(fields)
+}
+
+func (h *Handler) GetCustomField(w http.ResponseWriter, r *http.Request) {
+	field, err := models.GetCustomField(r.Context(), h.DB, mux.Vars(r)["name"])
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(field)
+}
+
+// UpdateCustomField replaces the definition of the field named in the path.
+// Values students already have are checked against the new rules the next
+// time those students are updated.
+func (h *Handler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var field models.CustomField
+	if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	field.Name = name
+
+	if err := field.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := field.Update(r.Context(), h.DB, name); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(field)
+}
+
+func (h *Handler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	err := database.WithTx(r.Context(), h.DB, func(tx *database.Tx) error {
+		return models.DeleteCustomField(r.Context(), tx, mux.Vars(r)["name"])
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
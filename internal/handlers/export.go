@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/ai"
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/export"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// ExportStudents streams every student matching the listing filters as a
+// download. Students are read a page at a time, so the export never holds
+// the whole table in memory.
+func (h *Handler) ExportStudents(w http.ResponseWriter, r *http.Request) {
+	format, err := export.FormatFromRequest(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	withSummaries, includeNotes, err := exportSummaries(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	fields, err := models.GetCustomFields(r.Context(), h.DB)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	filter, err := studentFilter(r, fields)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	sw := export.Stream(w, format, fields, withSummaries)
+	err = models.EachStudent(r.Context(), h.DB, filter, func(student models.Student) error {
+		rec := export.Record{Student: student}
+		if withSummaries {
+			rec.Summary, rec.SummaryError = h.exportSummary(r.Context(), student, includeNotes)
+		}
+		return sw.Write(rec)
+	})
+	if err == nil {
+		err = sw.Close()
+	}
+	if err != nil {
+		if !sw.Started() {
+			apperror.Write(w, r, err)
+			return
+		}
+		// The status has been sent, so the client can only notice the
+		// truncated output.
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+}
+
+// exportSummaries reads whether an export includes summaries and, if so,
+// their mode. Only principals who may generate summaries can ask for them.
+func exportSummaries(r *http.Request) (withSummaries, includeNotes bool, err error) {
+	if v := r.URL.Query().Get("summaries"); v != "" {
+		if withSummaries, err = strconv.ParseBool(v); err != nil {
+			return false, false, apperror.BadRequest("summaries must be a boolean")
+		}
+	}
+	if withSummaries {
+		if err := auth.Check(r.Context(), auth.GenerateSummaries); err != nil {
+			return false, false, err
+		}
+	}
+	includeNotes, err = summaryIncludesNotes(r)
+	return withSummaries, includeNotes, err
+}
+
+// exportSummary generates a student's summary, or describes why it could
+// not be, so that one failure does not end the export.
+func (h *Handler) exportSummary(ctx context.Context, student models.Student, includeNotes bool) (string, string) {
+	var sc ai.SummaryContext
+	err := h.read(ctx, func(db *sql.DB) error {
+		var err error
+		sc, err = summaryContext(ctx, db, student, includeNotes)
+		return err
+	})
+	if err != nil {
+		return "", export.SummaryError(student.ID, err)
+	}
+	summary, err := ai.GenerateStudentSummary(ctx, sc)
+	if err != nil {
+		return "", export.SummaryError(student.ID, err)
+	}
+	return summary, ""
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetStudentGrades(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	grades, err := models.GetStudentGrades(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(grades)
+}
+
+func (h *Handler) CreateGrade(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var grade models.Grade
+	if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	grade.StudentID = id
+
+	if err := grade.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := grade.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(grade)
+}
+
+func (h *Handler) GetGrade(w http.ResponseWriter, r *http.Request) {
+	id, gradeID, err := gradeIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	grade, err := models.GetGrade(r.Context(), h.DB, id, gradeID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(grade)
+}
+
+func (h *Handler) UpdateGrade(w http.ResponseWriter, r *http.Request) {
+	id, gradeID, err := gradeIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	var grade models.Grade
+	if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := grade.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := grade.Update(r.Context(), h.DB, id, gradeID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(grade)
+}
+
+func (h *Handler) DeleteGrade(w http.ResponseWriter, r *http.Request) {
+	id, gradeID, err := gradeIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := models.DeleteGrade(r.Context(), h.DB, id, gradeID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStudentPerformance reports per-term GPA, cumulative GPA and the GPA
+// trend.
+func (h *Handler) GetStudentPerformance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	grades, err := models.GetStudentGrades(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.CalculatePerformance(grades))
+}
+
+// gradeIDs parses the student and grade IDs of a grade route.
+func gradeIDs(r *http.Request) (int, int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid student ID")
+	}
+	gradeID, err := strconv.Atoi(mux.Vars(r)["gradeId"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid grade ID")
+	}
+	return id, gradeID, nil
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/ai"
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) CreateGuardian(w http.ResponseWriter, r *http.Request) {
+	var guardian models.Guardian
+	if err := json.NewDecoder(r.Body).Decode(&guardian); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := guardian.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := guardian.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(guardian)
+}
+
+func (h *Handler) GetAllGuardians(w http.ResponseWriter, r *http.Request) {
+	guardians, err := models.GetAllGuardians(r.Context(), h.DB)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(guardians)
+}
+
+func (h *Handler) GetGuardian(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+		return
+	}
+
+	guardian, err := models.GetGuardian(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(guardian)
+}
+
+func (h *Handler) UpdateGuardian(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+		return
+	}
+
+	var guardian models.Guardian
+	if err := json.NewDecoder(r.Body).Decode(&guardian); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := guardian.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := guardian.Update(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(guardian)
+}
+
+func (h *Handler) DeleteGuardian(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+		return
+	}
+
+	if err := models.DeleteGuardian(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetGuardianStudents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+		return
+	}
+
+	if _, err := models.GetGuardian(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	links, err := models.GetGuardianStudents(r.Context(), h.DB, id, auth.AdvisorScope(r.Context()))
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(links)
+}
+
+func (h *Handler) GetStudentGuardians(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	links, err := models.GetStudentGuardians(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(links)
+}
+
+func (h *Handler) LinkGuardian(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var link models.StudentGuardian
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	link.StudentID = id
+
+	if err := link.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := link.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *Handler) UpdateGuardianLink(w http.ResponseWriter, r *http.Request) {
+	id, guardianID, err := guardianLinkIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	var link models.StudentGuardian
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	link.StudentID = id
+	link.GuardianID = guardianID
+
+	if err := link.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := link.Update(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *Handler) UnlinkGuardian(w http.ResponseWriter, r *http.Request) {
+	id, guardianID, err := guardianLinkIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := models.DeleteStudentGuardian(r.Context(), h.DB, id, guardianID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGuardianSummary generates an update on the student for one of their
+// guardians, in the guardian's preferred language.
+func (h *Handler) GetGuardianSummary(w http.ResponseWriter, r *http.Request) {
+	id, guardianID, err := guardianLinkIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	var sc ai.SummaryContext
+	var link *models.StudentGuardian
+	err = h.read(r.Context(), func(db *sql.DB) error {
+		student, err := models.GetStudent(r.Context(), db, id)
+		if err != nil {
+			return err
+		}
+		if link, err = models.GetStudentGuardian(r.Context(), db, id, guardianID); err != nil {
+			return err
+		}
+		sc, err = summaryContext(r.Context(), db, *student, false)
+		return err
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	summary, err := ai.GenerateGuardianSummary(r.Context(), sc, *link)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"summary":  summary,
+		"language": link.Guardian.PreferredLanguage,
+	})
+}
+
+// guardianLinkIDs parses the student and guardian IDs of a guardian link
+// route.
+func guardianLinkIDs(r *http.Request) (int, int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid student ID")
+	}
+	guardianID, err := strconv.Atoi(mux.Vars(r)["guardianId"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid guardian ID")
+	}
+	return id, guardianID, nil
+}
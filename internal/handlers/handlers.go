@@ -1,40 +1,88 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AashishKumar-3002/FealtyX/internal/ai"
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/export"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
 	"github.com/AashishKumar-3002/FealtyX/internal/models"
 
 	"github.com/gorilla/mux"
 )
 
 type Handler struct {
+	// DB is the primary database, which takes all writes.
 	DB *sql.DB
+	// Cluster, if set, spreads reads that can tolerate replication lag
+	// over the read replicas.
+	Cluster *database.Cluster
+	// Health, if set, is reported by GetMetrics.
+	Health *database.Health
 }
 
 func NewHandler(db *sql.DB) *Handler {
 	return &Handler{DB: db}
 }
 
+// read runs fn against a read replica, or the primary if there are none.
+func (h *Handler) read(ctx context.Context, fn func(db *sql.DB) error) error {
+	if h.Cluster == nil {
+		return fn(h.DB)
+	}
+	return h.Cluster.Read(ctx, fn)
+}
+
+// record writes an audit entry for a change made by r as part of tx.
+func record(tx *database.Tx, r *http.Request, action models.AuditAction, before, after *models.Student) error {
+	return models.NewAuditEntry(action, before, after,
+		middleware.Actor(r), middleware.RequestIDFromContext(r.Context())).Create(r.Context(), tx)
+}
+
+// validateStudent checks the student's fields and its attributes against
+// the custom field definitions.
+func (h *Handler) validateStudent(ctx context.Context, student *models.Student) error {
+	if err := student.Validate(); err != nil {
+		return err
+	}
+	fields, err := models.GetCustomFields(ctx, h.DB)
+	if err != nil {
+		return err
+	}
+	return student.ValidateAttributes(fields)
+}
+
 func (h *Handler) CreateStudent(w http.ResponseWriter, r *http.Request) {
 	var student models.Student
 	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
 		return
 	}
 
-	if err := student.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := h.validateStudent(r.Context(), &student); err != nil {
+		apperror.Write(w, r, err)
 		return
 	}
 
-	if err := student.Create(h.DB); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	err := database.WithTx(r.Context(), h.DB, func(tx *database.Tx) error {
+		if err := student.Create(r.Context(), tx); err != nil {
+			return err
+		}
+		return record(tx, r, models.AuditCreate, nil, &student)
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
 		return
 	}
 
@@ -43,51 +91,202 @@ func (h *Handler) CreateStudent(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetAllStudents(w http.ResponseWriter, r *http.Request) {
-	students, err := models.GetAllStudents(h.DB)
+	if email := r.URL.Query().Get("email"); email != "" {
+		h.getStudentsByEmail(w, r, email)
+		return
+	}
+
+	page, err := models.ParseStudentPage(r.URL.Query())
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	var fields []models.CustomField
+	var students []models.Student
+	var next *models.StudentCursor
+	err = h.read(r.Context(), func(db *sql.DB) error {
+		var err error
+		if fields, err = models.GetCustomFields(r.Context(), db); err != nil {
+			return err
+		}
+		filter, err := studentFilter(r, fields)
+		if err != nil {
+			return err
+		}
+		students, next, err = models.ListStudents(r.Context(), db, filter, page)
+		return err
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apperror.Write(w, r, err)
 		return
 	}
 
+	setNextLink(w, r, next)
+	writeStudents(w, r, students, fields)
+}
+
+// studentFilter reads the include_deleted, range and attribute filters of
+// a student listing. Advisors only see the students assigned to them.
+func studentFilter(r *http.Request, fields []models.CustomField) (models.StudentFilter, error) {
+	filter := models.StudentFilter{Advisor: auth.AdvisorScope(r.Context())}
+	if v := r.URL.Query().Get("include_deleted"); v != "" {
+		var err error
+		if filter.IncludeDeleted, err = strconv.ParseBool(v); err != nil {
+			return filter, apperror.BadRequest("include_deleted must be a boolean")
+		}
+	}
+	if err := filter.ParseRanges(r.URL.Query()); err != nil {
+		return filter, err
+	}
+	var err error
+	filter.Attributes, err = models.ParseAttributeFilters(r.URL.Query(), fields)
+	return filter, err
+}
+
+// setNextLink points the client at the next page of a listing, if there
+// is one, with a Link header that repeats the request's query with the
+// cursor in after.
+func setNextLink(w http.ResponseWriter, r *http.Request, next *models.StudentCursor) {
+	if next == nil {
+		return
+	}
+	u := *r.URL
+	q := u.Query()
+	q.Set("after", next.Encode())
+	u.RawQuery = q.Encode()
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", u.RequestURI()))
+}
+
+// writeStudents writes a student listing as JSON or, if the client asks for
+// it, in one of the export formats.
+func writeStudents(w http.ResponseWriter, r *http.Request, students []models.Student, fields []models.CustomField) {
+	if format := export.Negotiate(r.Header.Get("Accept")); format != export.FormatJSON {
+		if err := export.WriteAll(w, format, students, fields); err != nil {
+			log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		}
+		return
+	}
 	json.NewEncoder(w).Encode(students)
 }
 
+// getStudentsByEmail answers GET /students?email= with a list holding the
+// matching student, or an empty list.
+func (h *Handler) getStudentsByEmail(w http.ResponseWriter, r *http.Request, email string) {
+	var students []models.Student
+	var fields []models.CustomField
+	err := h.read(r.Context(), func(db *sql.DB) error {
+		students = []models.Student{}
+		student, err := models.GetStudentByEmail(r.Context(), db, email)
+		switch {
+		case err == nil:
+			visible := true
+			if advisor := auth.AdvisorScope(r.Context()); advisor != "" {
+				if visible, err = models.IsAssigned(r.Context(), db, advisor, student.ID); err != nil {
+					return err
+				}
+			}
+			if visible {
+				students = append(students, *student)
+			}
+		case !apperror.Is(err, apperror.KindNotFound):
+			return err
+		}
+		fields, err = models.GetCustomFields(r.Context(), db)
+		return err
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	writeStudents(w, r, students, fields)
+}
+
 func (h *Handler) GetStudent(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
 		return
 	}
 
-	student, err := models.GetStudent(h.DB, id)
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			apperror.Write(w, r, apperror.BadRequest("as_of must be an RFC 3339 timestamp"))
+			return
+		}
+		var student *models.Student
+		err = h.read(r.Context(), func(db *sql.DB) error {
+			student, err = models.GetStudentAsOf(r.Context(), db, id, at)
+			return err
+		})
+		if err != nil {
+			apperror.Write(w, r, err)
+			return
+		}
+		json.NewEncoder(w).Encode(student)
+		return
+	}
+
+	var student *models.Student
+	err = h.read(r.Context(), func(db *sql.DB) error {
+		student, err = models.GetStudent(r.Context(), db, id)
+		return err
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		apperror.Write(w, r, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(student)
 }
 
+func (h *Handler) GetStudentHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	history, err := models.GetStudentHistory(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(history)
+}
+
 func (h *Handler) UpdateStudent(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
 		return
 	}
 
 	var student models.Student
 	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
 		return
 	}
 
-	if err := student.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := h.validateStudent(r.Context(), &student); err != nil {
+		apperror.Write(w, r, err)
 		return
 	}
 
-	if err := student.Update(h.DB, id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	err = database.WithTx(r.Context(), h.DB, func(tx *database.Tx) error {
+		before, err := models.LockStudent(r.Context(), tx, id)
+		if err != nil {
+			return err
+		}
+		if err := student.Update(r.Context(), tx, id); err != nil {
+			return err
+		}
+		return record(tx, r, models.AuditUpdate, before, &student)
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
 		return
 	}
 
@@ -97,7 +296,7 @@ func (h *Handler) UpdateStudent(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteStudentByIds(w http.ResponseWriter, r *http.Request) {
 	idsParam := r.URL.Query().Get("ids")
 	if idsParam == "" {
-		http.Error(w, "No IDs provided", http.StatusBadRequest)
+		apperror.Write(w, r, apperror.BadRequest("no IDs provided"))
 		return
 	}
 	idsStrings := strings.Split(idsParam, ",")
@@ -105,12 +304,12 @@ func (h *Handler) DeleteStudentByIds(w http.ResponseWriter, r *http.Request) {
 	for _, idStr := range idsStrings {
 		id, err := strconv.Atoi(strings.TrimSpace(idStr))
 		if err != nil {
-			http.Error(w, "Invalid ID format", http.StatusBadRequest)
+			apperror.Write(w, r, apperror.BadRequest("invalid ID format"))
 			return
 		}
-		deletedId, err := models.DeleteStudent(h.DB, id)
+		deletedId, err := h.deleteStudent(r, id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apperror.Write(w, r, err)
 			return
 		}
 		if deletedId == id {
@@ -123,39 +322,176 @@ func (h *Handler) DeleteStudentByIds(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// deleteStudent soft deletes a student and audits it. Like
+// models.DeleteStudent it returns 0 if there was no live student to delete.
+func (h *Handler) deleteStudent(r *http.Request, id int) (int, error) {
+	var deletedId int
+	err := database.WithTx(r.Context(), h.DB, func(tx *database.Tx) error {
+		var err error
+		if deletedId, err = models.DeleteStudent(r.Context(), tx, id); err != nil || deletedId == 0 {
+			return err
+		}
+		after, err := models.LockStudent(r.Context(), tx, id)
+		if err != nil {
+			return err
+		}
+		before := *after
+		before.DeletedAt = nil
+		return record(tx, r, models.AuditDelete, &before, after)
+	})
+	return deletedId, err
+}
+
 func (h *Handler) DeleteStudent(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
 		return
 	}
 
-	if _ , err := models.DeleteStudent(h.DB, id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	deletedId, err := h.deleteStudent(r, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+	if deletedId != id {
+		apperror.Write(w, r, apperror.NotFound("student %d not found", id))
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) RestoreStudent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var student *models.Student
+	err = database.WithTx(r.Context(), h.DB, func(tx *database.Tx) error {
+		before, err := models.LockStudent(r.Context(), tx, id)
+		if err != nil {
+			return err
+		}
+		if student, err = models.RestoreStudent(r.Context(), tx, id); err != nil {
+			return err
+		}
+		return record(tx, r, models.AuditRestore, before, student)
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(student)
+}
+
+// GetStudentSummary generates a summary of the student. With ?mode=notes
+// the advisor notes are condensed into it as well.
 func (h *Handler) GetStudentSummary(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
 		return
 	}
 
-	student, err := models.GetStudent(h.DB, id)
+	includeNotes, err := summaryIncludesNotes(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		apperror.Write(w, r, err)
 		return
 	}
 
-	summary, err := ai.GenerateStudentSummary(*student)
+	var sc ai.SummaryContext
+	err = h.read(r.Context(), func(db *sql.DB) error {
+		student, err := models.GetStudent(r.Context(), db, id)
+		if err != nil {
+			return err
+		}
+		sc, err = summaryContext(r.Context(), db, *student, includeNotes)
+		return err
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	summary, err := ai.GenerateStudentSummary(r.Context(), sc)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apperror.Write(w, r, err)
 		return
 	}
 
+	// The summary is kept for search; failing to do so should not cost
+	// the client the summary it asked for.
+	saved := models.StudentSummary{StudentID: id, Summary: summary}
+	if err := saved.Save(r.Context(), h.DB); err != nil {
+		log.Printf("Error saving the summary of student %d: %v", id, err)
+	}
+
 	json.NewEncoder(w).Encode(map[string]string{"summary": summary})
 }
+
+// SearchStudents answers GET /students/search?q= with the live students
+// whose name and email, latest summary or one of whose notes hold every
+// word of q, best first, with the matches highlighted.
+func (h *Handler) SearchStudents(w http.ResponseWriter, r *http.Request) {
+	q, limit, err := models.ParseSearchQuery(r.URL.Query())
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	var results []models.SearchResult
+	err = h.read(r.Context(), func(db *sql.DB) error {
+		results, err = models.SearchStudents(r.Context(), db, q, limit, auth.AdvisorScope(r.Context()))
+		return err
+	})
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(results)
+}
+
+// summaryIncludesNotes reads the summary mode: "standard" (the default) or
+// "notes".
+func summaryIncludesNotes(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("mode") {
+	case "", "standard":
+		return false, nil
+	case "notes":
+		return true, nil
+	}
+	return false, apperror.BadRequest("mode must be standard or notes")
+}
+
+// summaryContext gathers the student's related records for the summary
+// prompt.
+func summaryContext(ctx context.Context, db models.DBTX, student models.Student, includeNotes bool) (ai.SummaryContext, error) {
+	enrollments, err := models.GetStudentEnrollments(ctx, db, student.ID)
+	if err != nil {
+		return ai.SummaryContext{}, err
+	}
+	grades, err := models.GetStudentGrades(ctx, db, student.ID)
+	if err != nil {
+		return ai.SummaryContext{}, err
+	}
+	attendance, err := models.GetStudentAttendance(ctx, db, student.ID, models.DateRange{})
+	if err != nil {
+		return ai.SummaryContext{}, err
+	}
+	fields, err := models.GetCustomFields(ctx, db)
+	if err != nil {
+		return ai.SummaryContext{}, err
+	}
+	sc := ai.SummaryContext{Student: student, CustomFields: fields, Enrollments: enrollments, Grades: grades, Attendance: attendance}
+	if includeNotes {
+		if sc.Notes, err = models.GetStudentNotes(ctx, db, student.ID); err != nil {
+			return ai.SummaryContext{}, err
+		}
+	}
+	return sc, nil
+}
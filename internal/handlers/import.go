@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/importer"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+)
+
+// ImportStudents creates or updates students from a CSV roster in the
+// request body.
+func (h *Handler) ImportStudents(w http.ResponseWriter, r *http.Request) {
+	opts, err := importer.OptionsFromRequest(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	target := &importer.PostgresTarget{
+		DB:        h.DB,
+		Actor:     middleware.Actor(r),
+		RequestID: middleware.RequestIDFromContext(r.Context()),
+		Context:   r.Context(),
+	}
+	report, err := importer.Run(r.Body, target, opts)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+)
+
+// databaseMetrics describes the database connection for GET /metrics.
+type databaseMetrics struct {
+	Dialect string                 `json:"dialect"`
+	Health  *database.HealthStatus `json:"health,omitempty"`
+	Pool    database.PoolStats     `json:"pool"`
+	// Replicas is empty unless reads are spread over read replicas.
+	Replicas []database.ReplicaStatus `json:"replicas,omitempty"`
+}
+
+// GetMetrics reports the connection pool statistics and the outcome of the
+// latest health check of the database and of each read replica.
+func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	m := databaseMetrics{
+		Dialect: database.DialectOf(h.DB).String(),
+		Pool:    database.Stats(h.DB),
+	}
+	if h.Health != nil {
+		status := h.Health.Status()
+		m.Health = &status
+	}
+	if h.Cluster != nil {
+		m.Replicas = h.Cluster.Replicas()
+	}
+
+	json.NewEncoder(w).Encode(map[string]databaseMetrics{"database": m})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetStudentNotes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	if _, err := models.GetStudent(r.Context(), h.DB, id); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	notes, err := models.GetStudentNotes(r.Context(), h.DB, id)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(notes)
+}
+
+func (h *Handler) CreateNote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+		return
+	}
+
+	var note models.Note
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+	note.StudentID = id
+	note.Author = middleware.Actor(r)
+
+	if err := note.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := note.Create(r.Context(), h.DB); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(note)
+}
+
+func (h *Handler) GetNote(w http.ResponseWriter, r *http.Request) {
+	id, noteID, err := noteIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	note, err := models.GetNote(r.Context(), h.DB, id, noteID)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(note)
+}
+
+func (h *Handler) UpdateNote(w http.ResponseWriter, r *http.Request) {
+	id, noteID, err := noteIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	var note models.Note
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := note.Validate(); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := note.Update(r.Context(), h.DB, id, noteID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(note)
+}
+
+func (h *Handler) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	id, noteID, err := noteIDs(r)
+	if err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	if err := models.DeleteNote(r.Context(), h.DB, id, noteID); err != nil {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// noteIDs parses the student and note IDs of a note route.
+func noteIDs(r *http.Request) (int, int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid student ID")
+	}
+	noteID, err := strconv.Atoi(mux.Vars(r)["noteId"])
+	if err != nil {
+		return 0, 0, apperror.BadRequest("invalid note ID")
+	}
+	return id, noteID, nil
+}
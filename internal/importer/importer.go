@@ -0,0 +1,395 @@
+// Package importer loads student rosters from CSV files into either storage
+// backend.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// Strategies for rows whose email belongs to an existing student.
+const (
+	// OnDuplicateFail reports the row as failed.
+	OnDuplicateFail = "fail"
+	// OnDuplicateSkip leaves the existing student alone.
+	OnDuplicateSkip = "skip"
+	// OnDuplicateUpdate overwrites the existing student's mapped fields.
+	OnDuplicateUpdate = "update"
+)
+
+// Row statuses.
+const (
+	StatusCreated = "created"
+	StatusUpdated = "updated"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// attributePrefix marks a mapping to a custom field, as in
+// attributes.house.
+const attributePrefix = "attributes."
+
+// Target is where imported students are written.
+type Target interface {
+	CustomFields() ([]models.CustomField, error)
+	// FindByEmail returns the live student with the email, or nil.
+	FindByEmail(email string) (*models.Student, error)
+	Create(student models.Student) (models.Student, error)
+	Update(id int, student models.Student) (models.Student, error)
+}
+
+// Options control an import.
+type Options struct {
+	// DryRun validates every row and reports what would happen without
+	// writing anything.
+	DryRun bool
+	// OnDuplicate is one of the OnDuplicate strategies; empty means fail.
+	OnDuplicate string
+	// Mapping maps CSV column names to student fields: name, age, email or
+	// attributes.<custom field>. Columns that are not mapped are matched to
+	// the field of the same name, ignoring case.
+	Mapping map[string]string
+}
+
+// Report describes the outcome of an import.
+type Report struct {
+	DryRun      bool   `json:"dry_run"`
+	OnDuplicate string `json:"on_duplicate"`
+	// Columns maps each CSV column that was used to its field.
+	Columns        map[string]string `json:"columns"`
+	IgnoredColumns []string          `json:"ignored_columns"`
+
+	Total   int `json:"total"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+	// Rows lists every row that was not simply created, so that the report
+	// stays small for large, clean files.
+	Rows []RowResult `json:"rows"`
+}
+
+// RowResult is the outcome of one CSV row.
+type RowResult struct {
+	// Line is the line of the row in the file, counting the header as 1.
+	Line      int                   `json:"line"`
+	Email     string                `json:"email,omitempty"`
+	Status    string                `json:"status"`
+	StudentID int                   `json:"student_id,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	Errors    []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// Run reads CSV rows from r one at a time and writes each valid row to the
+// target. Problems with individual rows are recorded in the report; an error
+// is returned only if the header is unusable or the input cannot be read.
+func Run(r io.Reader, target Target, opts Options) (*Report, error) {
+	if opts.OnDuplicate == "" {
+		opts.OnDuplicate = OnDuplicateFail
+	}
+	switch opts.OnDuplicate {
+	case OnDuplicateFail, OnDuplicateSkip, OnDuplicateUpdate:
+	default:
+		return nil, apperror.BadRequest("on_duplicate must be fail, skip or update")
+	}
+
+	fields, err := target.CustomFields()
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, apperror.BadRequest("the CSV file is empty")
+	}
+	if err != nil {
+		return nil, apperror.BadRequest("invalid CSV header: %v", err)
+	}
+	report := &Report{DryRun: opts.DryRun, OnDuplicate: opts.OnDuplicate, Rows: []RowResult{}}
+	columns, err := mapColumns(header, opts.Mapping, fields, report)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &importer{target: target, opts: opts, fields: fields, columns: columns, seen: make(map[string]int)}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var result RowResult
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			result = RowResult{Line: parseErr.StartLine, Status: StatusFailed, Error: parseErr.Err.Error()}
+		case err != nil:
+			return nil, err
+		default:
+			result = imp.row(record)
+			result.Line, _ = reader.FieldPos(0)
+		}
+
+		report.Total++
+		switch result.Status {
+		case StatusCreated:
+			report.Created++
+			continue
+		case StatusUpdated:
+			report.Updated++
+		case StatusSkipped:
+			report.Skipped++
+		case StatusFailed:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return report, nil
+}
+
+// column is a CSV column mapped to a student field. field is nil for the
+// built-in fields.
+type column struct {
+	index int
+	name  string
+	field *models.CustomField
+}
+
+// mapColumns resolves the header against the mapping and records the result
+// in the report.
+func mapColumns(header []string, mapping map[string]string, fields []models.CustomField, report *Report) ([]column, error) {
+	byName := make(map[string]*models.CustomField, len(fields))
+	for i := range fields {
+		byName[fields[i].Name] = &fields[i]
+	}
+
+	mapped := make(map[string]string, len(mapping))
+	for col, target := range mapping {
+		mapped[strings.ToLower(strings.TrimSpace(col))] = target
+	}
+
+	report.Columns = make(map[string]string)
+	report.IgnoredColumns = []string{}
+	used := make(map[string]string)
+	var columns []column
+	for i, heading := range header {
+		// Spreadsheet programs often start the file with a byte order mark.
+		heading = strings.TrimSpace(strings.TrimPrefix(heading, "\ufeff"))
+		target, explicit := mapped[strings.ToLower(heading)]
+		if !explicit {
+			target = strings.ToLower(heading)
+		}
+
+		c := column{index: i, name: target}
+		switch {
+		case target == "name" || target == "age" || target == "email":
+		case strings.HasPrefix(target, attributePrefix) && byName[strings.TrimPrefix(target, attributePrefix)] != nil:
+			c.field = byName[strings.TrimPrefix(target, attributePrefix)]
+		case !explicit && byName[target] != nil:
+			c.field = byName[target]
+			c.name = attributePrefix + target
+		case explicit:
+			return nil, apperror.BadRequest("column %q is mapped to unknown field %q", heading, target)
+		default:
+			report.IgnoredColumns = append(report.IgnoredColumns, heading)
+			continue
+		}
+
+		if other, ok := used[c.name]; ok {
+			return nil, apperror.BadRequest("columns %q and %q both map to %s", other, heading, c.name)
+		}
+		used[c.name] = heading
+		report.Columns[heading] = c.name
+		columns = append(columns, c)
+	}
+
+	var missing []string
+	for _, name := range []string{"name", "age", "email"} {
+		if _, ok := used[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperror.BadRequest("no column is mapped to %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+type importer struct {
+	target  Target
+	opts    Options
+	fields  []models.CustomField
+	columns []column
+	// seen maps the emails of rows already imported to their student IDs,
+	// so that duplicates within the file are handled like duplicates of
+	// existing students, even in a dry run.
+	seen map[string]int
+}
+
+// row imports one record.
+func (imp *importer) row(record []string) RowResult {
+	var student models.Student
+	var errs []apperror.FieldError
+	set := make(map[string]bool)
+	for _, c := range imp.columns {
+		value := ""
+		if c.index < len(record) {
+			value = strings.TrimSpace(record[c.index])
+		}
+		if value == "" {
+			continue
+		}
+		set[c.name] = true
+
+		switch {
+		case c.field != nil:
+			v, err := c.field.ParseFilterValue(value)
+			if err != nil {
+				errs = append(errs, apperror.FieldError{Field: c.name, Rule: "type", Message: err.Error()})
+				continue
+			}
+			if student.Attributes == nil {
+				student.Attributes = make(map[string]interface{})
+			}
+			student.Attributes[c.field.Name] = v
+		case c.name == "name":
+			student.Name = value
+		case c.name == "email":
+			student.Email = value
+		case c.name == "age":
+			age, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, apperror.FieldError{Field: "age", Rule: "type", Message: "age must be a whole number"})
+				continue
+			}
+			student.Age = age
+		}
+	}
+	result := RowResult{Email: student.Email}
+	if len(errs) > 0 {
+		return imp.failed(result, apperror.Validation("row is invalid", errs))
+	}
+
+	existing, err := imp.existing(student.Email)
+	if err != nil {
+		return imp.failed(result, err)
+	}
+	if existing != nil {
+		switch imp.opts.OnDuplicate {
+		case OnDuplicateSkip:
+			result.Status = StatusSkipped
+			result.StudentID = existing.ID
+			return result
+		case OnDuplicateFail:
+			result.StudentID = existing.ID
+			return imp.failed(result, apperror.Conflict("a student with this email already exists"))
+		}
+		student = merge(*existing, student, set)
+	}
+
+	if err := student.Validate(); err != nil {
+		return imp.failed(result, err)
+	}
+	if err := student.ValidateAttributes(imp.fields); err != nil {
+		return imp.failed(result, err)
+	}
+
+	result.Status = StatusCreated
+	if existing != nil {
+		result.Status = StatusUpdated
+		result.StudentID = existing.ID
+	}
+	if !imp.opts.DryRun {
+		if existing != nil {
+			_, err = imp.target.Update(existing.ID, student)
+		} else {
+			student, err = imp.target.Create(student)
+			result.StudentID = student.ID
+		}
+		if err != nil {
+			return imp.failed(result, err)
+		}
+	}
+	imp.seen[strings.ToLower(student.Email)] = result.StudentID
+	return result
+}
+
+// existing finds the student a row's email belongs to, either already
+// stored or imported earlier in the file.
+func (imp *importer) existing(email string) (*models.Student, error) {
+	if email == "" {
+		return nil, nil
+	}
+	student, err := imp.target.FindByEmail(email)
+	if err != nil || student != nil {
+		return student, err
+	}
+	if id, ok := imp.seen[strings.ToLower(email)]; ok {
+		// Only reachable in a dry run, where nothing was written.
+		return &models.Student{ID: id, Email: email}, nil
+	}
+	return nil, nil
+}
+
+// merge overwrites the fields of existing that the row set.
+func merge(existing, row models.Student, set map[string]bool) models.Student {
+	if set["name"] {
+		existing.Name = row.Name
+	}
+	if set["age"] {
+		existing.Age = row.Age
+	}
+	if set["email"] {
+		existing.Email = row.Email
+	}
+	attrs := make(map[string]interface{}, len(existing.Attributes)+len(row.Attributes))
+	for name, value := range existing.Attributes {
+		attrs[name] = value
+	}
+	for name, value := range row.Attributes {
+		attrs[name] = value
+	}
+	existing.Attributes = attrs
+	return existing
+}
+
+// failed records err on the result. Validation errors keep their field
+// errors; internal errors are not exposed.
+func (imp *importer) failed(result RowResult, err error) RowResult {
+	result.Status = StatusFailed
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Kind == apperror.KindInternal {
+		log.Printf("importing %s: %v", result.Email, err)
+		result.Error = "the row could not be saved"
+		return result
+	}
+	result.Error = appErr.Message
+	result.Errors = appErr.Fields
+	return result
+}
+
+// ParseMapping parses column mappings written as column=field.
+func ParseMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		col, field, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(col) == "" || strings.TrimSpace(field) == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, want column=field", pair)
+		}
+		mapping[strings.TrimSpace(col)] = strings.TrimSpace(field)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,64 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// PostgresTarget imports into the Postgres backend, auditing each change as
+// made by actor.
+type PostgresTarget struct {
+	DB        *sql.DB
+	Actor     string
+	RequestID string
+	// Context bounds the import's queries, usually to the request's
+	// lifetime; nil means context.Background().
+	Context context.Context
+}
+
+func (t *PostgresTarget) ctx() context.Context {
+	if t.Context == nil {
+		return context.Background()
+	}
+	return t.Context
+}
+
+func (t *PostgresTarget) CustomFields() ([]models.CustomField, error) {
+	return models.GetCustomFields(t.ctx(), t.DB)
+}
+
+func (t *PostgresTarget) FindByEmail(email string) (*models.Student, error) {
+	student, err := models.GetStudentByEmail(t.ctx(), t.DB, email)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return nil, nil
+	}
+	return student, err
+}
+
+func (t *PostgresTarget) Create(student models.Student) (models.Student, error) {
+	err := database.WithTx(t.ctx(), t.DB, func(tx *database.Tx) error {
+		if err := student.Create(t.ctx(), tx); err != nil {
+			return err
+		}
+		return models.NewAuditEntry(models.AuditCreate, nil, &student, t.Actor, t.RequestID).Create(t.ctx(), tx)
+	})
+	return student, err
+}
+
+func (t *PostgresTarget) Update(id int, student models.Student) (models.Student, error) {
+	err := database.WithTx(t.ctx(), t.DB, func(tx *database.Tx) error {
+		before, err := models.LockStudent(t.ctx(), tx, id)
+		if err != nil {
+			return err
+		}
+		if err := student.Update(t.ctx(), tx, id); err != nil {
+			return err
+		}
+		return models.NewAuditEntry(models.AuditUpdate, before, &student, t.Actor, t.RequestID).Create(t.ctx(), tx)
+	})
+	return student, err
+}
@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+)
+
+// MappingParamPrefix marks query parameters that map a CSV column to a
+// field, as in ?map.Full%20Name=name.
+const MappingParamPrefix = "map."
+
+// OptionsFromRequest checks that r carries a CSV body and reads the import
+// options from its query string: dry_run, on_duplicate and map.<column>.
+func OptionsFromRequest(r *http.Request) (Options, error) {
+	var opts Options
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/csv" {
+		return opts, apperror.BadRequest("the request body must be text/csv")
+	}
+
+	query := r.URL.Query()
+	if v := query.Get("dry_run"); v != "" {
+		if opts.DryRun, err = strconv.ParseBool(v); err != nil {
+			return opts, apperror.BadRequest("dry_run must be a boolean")
+		}
+	}
+	opts.OnDuplicate = query.Get("on_duplicate")
+	for param, values := range query {
+		if col, ok := strings.CutPrefix(param, MappingParamPrefix); ok {
+			if opts.Mapping == nil {
+				opts.Mapping = make(map[string]string)
+			}
+			opts.Mapping[col] = values[len(values)-1]
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/middleware"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/gorilla/mux"
+)
+
+func (a *API) GetStudentAdvisors(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetStudentAdvisors(id))
+}
+
+// AssignAdvisor assigns the student to the advisor in the body, who may
+// then see and change the student's records.
+func (a *API) AssignAdvisor(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    var assignment models.StudentAdvisor
+    if err := json.NewDecoder(r.Body).Decode(&assignment); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    assignment.StudentID = id
+    assignment.AssignedBy = middleware.Actor(r)
+
+    if err := assignment.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    created, err := a.storage.AssignAdvisor(assignment)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(created)
+}
+
+func (a *API) UnassignAdvisor(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if err := a.storage.UnassignAdvisor(id, mux.Vars(r)["advisor"]); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
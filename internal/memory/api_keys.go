@@ -0,0 +1,74 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/auth"
+    "github.com/AashishKumar-3002/FealtyX/internal/middleware"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/AashishKumar-3002/FealtyX/internal/tenant"
+    "github.com/gorilla/mux"
+)
+
+// CreateAPIKey generates a key. The response is the only place the key
+// itself appears; only its hash is stored.
+func (a *API) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+    var key models.APIKey
+    if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := key.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    secret, prefix, hash, err := auth.NewAPIKey()
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+    key.Prefix = prefix
+    key.CreatedBy = middleware.Actor(r)
+    key.Tenant = tenant.ID(r.Context())
+    createdKey, err := a.storage.CreateAPIKey(key, hash)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(models.NewAPIKey{APIKey: createdKey, Key: secret})
+}
+
+// GetAPIKeys lists the keys of the request's tenant, which are the only
+// ones in its storage.
+func (a *API) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+    keys := a.storage.GetAPIKeys()
+    for i := range keys {
+        keys[i].Tenant = tenant.ID(r.Context())
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(keys)
+}
+
+func (a *API) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid API key ID"))
+        return
+    }
+
+    if err := a.storage.DeleteAPIKey(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
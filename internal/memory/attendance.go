@@ -0,0 +1,143 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/auth"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/gorilla/mux"
+)
+
+func (a *API) GetStudentAttendance(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    dr, err := models.ParseDateRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetAttendance(id, dr))
+}
+
+// GetAttendanceStats reports attendance percentage, streaks and chronic
+// absence over an optional from/to range.
+func (a *API) GetAttendanceStats(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    dr, err := models.ParseDateRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(models.CalculateAttendanceStats(a.storage.GetAttendance(id, dr), dr))
+}
+
+func (a *API) RecordAttendance(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    var record models.AttendanceRecord
+    if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    record.StudentID = id
+
+    if err := record.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    saved, err := a.storage.SaveAttendance([]models.AttendanceRecord{record})
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(saved[0])
+}
+
+func (a *API) DeleteAttendance(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if err := a.storage.DeleteAttendance(id, mux.Vars(r)["date"]); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// RecordClassAttendance saves one day's attendance for several students of a
+// course. Either every record is saved or none are.
+func (a *API) RecordClassAttendance(w http.ResponseWriter, r *http.Request) {
+    courseID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+        return
+    }
+
+    var submission models.ClassAttendance
+    if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := submission.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    records := submission.AttendanceRecords(courseID)
+    // Advisors may only take the attendance of their own students.
+    if advisor := auth.AdvisorScope(r.Context()); advisor != "" {
+        for _, record := range records {
+            if !a.storage.IsAssigned(advisor, record.StudentID) {
+                apperror.Write(w, r, apperror.Forbidden("student %d is not assigned to you", record.StudentID))
+                return
+            }
+        }
+    }
+
+    saved, err := a.storage.SaveAttendance(records)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(saved)
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/gorilla/mux"
+)
+
+func (a *API) CreateCourse(w http.ResponseWriter, r *http.Request) {
+    var course models.Course
+    if err := json.NewDecoder(r.Body).Decode(&course); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := course.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    createdCourse, err := a.storage.CreateCourse(course)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(createdCourse)
+}
+
+func (a *API) GetAllCourses(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetAllCourses())
+}
+
+func (a *API) GetCourseByID(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+        return
+    }
+
+    course, err := a.storage.GetCourse(id)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(course)
+}
+
+func (a *API) UpdateCourse(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+        return
+    }
+
+    var course models.Course
+    if err := json.NewDecoder(r.Body).Decode(&course); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := course.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    updatedCourse, err := a.storage.UpdateCourse(id, course)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updatedCourse)
+}
+
+func (a *API) DeleteCourse(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid course ID"))
+        return
+    }
+
+    if err := a.storage.DeleteCourse(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) GetStudentEnrollments(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetEnrollments(id))
+}
+
+func (a *API) CreateEnrollment(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    var enrollment models.Enrollment
+    if err := json.NewDecoder(r.Body).Decode(&enrollment); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    enrollment.StudentID = id
+
+    if err := enrollment.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    createdEnrollment, err := a.storage.Enroll(enrollment)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(createdEnrollment)
+}
+
+func (a *API) UpdateEnrollment(w http.ResponseWriter, r *http.Request) {
+    id, courseID, err := enrollmentIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    var enrollment models.Enrollment
+    if err := json.NewDecoder(r.Body).Decode(&enrollment); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    enrollment.StudentID = id
+    enrollment.CourseID = courseID
+
+    if err := enrollment.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    updatedEnrollment, err := a.storage.UpdateEnrollmentStatus(id, courseID, enrollment.Status)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updatedEnrollment)
+}
+
+func (a *API) DeleteEnrollment(w http.ResponseWriter, r *http.Request) {
+    id, courseID, err := enrollmentIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    if err := a.storage.DeleteEnrollment(id, courseID); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// enrollmentIDs parses the student and course IDs of an enrollment route.
+func enrollmentIDs(r *http.Request) (int, int, error) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid student ID")
+    }
+    courseID, err := strconv.Atoi(mux.Vars(r)["courseId"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid course ID")
+    }
+    return id, courseID, nil
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/gorilla/mux"
+)
+
+func (a *API) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+    var field models.CustomField
+    if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := field.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    createdField, err := a.storage.CreateCustomField(field)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(createdField)
+}
+
+func (a *API) GetCustomFields(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetCustomFields())
+}
+
+func (a *API) GetCustomField(w http.ResponseWriter, r *http.Request) {
+    field, err := a.storage.GetCustomField(mux.Vars(r)["name"])
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(field)
+}
+
+// UpdateCustomField replaces the definition of the field named in the path.
+// Values students already have are checked against the new rules the next
+// time those students are updated.
+func (a *API) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+    name := mux.Vars(r)["name"]
+
+    var field models.CustomField
+    if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    field.Name = name
+
+    if err := field.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    updatedField, err := a.storage.UpdateCustomField(name, field)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updatedField)
+}
+
+func (a *API) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+    if err := a.storage.DeleteCustomField(mux.Vars(r)["name"]); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
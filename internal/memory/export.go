@@ -0,0 +1,67 @@
+package api
+
+import (
+    "log"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/ai"
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/auth"
+    "github.com/AashishKumar-3002/FealtyX/internal/export"
+)
+
+// ExportStudents streams every student matching the listing filters as a
+// download. The students are a snapshot taken when the export starts, so
+// changes made while it is written do not show up halfway through.
+func (a *API) ExportStudents(w http.ResponseWriter, r *http.Request) {
+    format, err := export.FormatFromRequest(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+    var withSummaries bool
+    if v := r.URL.Query().Get("summaries"); v != "" {
+        if withSummaries, err = strconv.ParseBool(v); err != nil {
+            apperror.Write(w, r, apperror.BadRequest("summaries must be a boolean"))
+            return
+        }
+    }
+    if withSummaries {
+        // Only principals who may generate summaries can ask for them.
+        if err := auth.Check(r.Context(), auth.GenerateSummaries); err != nil {
+            apperror.Write(w, r, err)
+            return
+        }
+    }
+    includeNotes, err := summaryIncludesNotes(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    fields := a.storage.GetCustomFields()
+    filter, err := studentFilter(r, fields)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    sw := export.Stream(w, format, fields, withSummaries)
+    for _, student := range a.storage.GetAll(filter) {
+        rec := export.Record{Student: student}
+        if withSummaries {
+            rec.Summary, err = ai.GenerateStudentSummary(r.Context(), a.summaryContext(student, includeNotes))
+            if err != nil {
+                rec.SummaryError = export.SummaryError(student.ID, err)
+            }
+        }
+        if err := sw.Write(rec); err != nil {
+            log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+            return
+        }
+    }
+    if err := sw.Close(); err != nil {
+        log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+    }
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/gorilla/mux"
+)
+
+func (a *API) GetStudentGrades(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetGrades(id))
+}
+
+func (a *API) CreateGrade(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    var grade models.Grade
+    if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    grade.StudentID = id
+
+    if err := grade.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    createdGrade, err := a.storage.CreateGrade(grade)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(createdGrade)
+}
+
+func (a *API) GetGrade(w http.ResponseWriter, r *http.Request) {
+    id, gradeID, err := gradeIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    grade, err := a.storage.GetGrade(id, gradeID)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(grade)
+}
+
+func (a *API) UpdateGrade(w http.ResponseWriter, r *http.Request) {
+    id, gradeID, err := gradeIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    var grade models.Grade
+    if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := grade.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    updatedGrade, err := a.storage.UpdateGrade(id, gradeID, grade)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updatedGrade)
+}
+
+func (a *API) DeleteGrade(w http.ResponseWriter, r *http.Request) {
+    id, gradeID, err := gradeIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    if err := a.storage.DeleteGrade(id, gradeID); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStudentPerformance reports per-term GPA, cumulative GPA and the GPA
+// trend.
+func (a *API) GetStudentPerformance(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(models.CalculatePerformance(a.storage.GetGrades(id)))
+}
+
+// gradeIDs parses the student and grade IDs of a grade route.
+func gradeIDs(r *http.Request) (int, int, error) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid student ID")
+    }
+    gradeID, err := strconv.Atoi(mux.Vars(r)["gradeId"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid grade ID")
+    }
+    return id, gradeID, nil
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/ai"
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/auth"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/gorilla/mux"
+)
+
+func (a *API) CreateGuardian(w http.ResponseWriter, r *http.Request) {
+    var guardian models.Guardian
+    if err := json.NewDecoder(r.Body).Decode(&guardian); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := guardian.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    createdGuardian, err := a.storage.CreateGuardian(guardian)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(createdGuardian)
+}
+
+func (a *API) GetAllGuardians(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetAllGuardians())
+}
+
+func (a *API) GetGuardian(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+        return
+    }
+
+    guardian, err := a.storage.GetGuardian(id)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(guardian)
+}
+
+func (a *API) UpdateGuardian(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+        return
+    }
+
+    var guardian models.Guardian
+    if err := json.NewDecoder(r.Body).Decode(&guardian); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := guardian.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    updatedGuardian, err := a.storage.UpdateGuardian(id, guardian)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updatedGuardian)
+}
+
+func (a *API) DeleteGuardian(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+        return
+    }
+
+    if err := a.storage.DeleteGuardian(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) GetGuardianStudents(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid guardian ID"))
+        return
+    }
+
+    if _, err := a.storage.GetGuardian(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetGuardianStudents(id, auth.AdvisorScope(r.Context())))
+}
+
+func (a *API) GetStudentGuardians(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetStudentGuardians(id))
+}
+
+func (a *API) LinkGuardian(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    var link models.StudentGuardian
+    if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    link.StudentID = id
+
+    if err := link.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    createdLink, err := a.storage.LinkGuardian(link)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(createdLink)
+}
+
+func (a *API) UpdateGuardianLink(w http.ResponseWriter, r *http.Request) {
+    id, guardianID, err := guardianLinkIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    var link models.StudentGuardian
+    if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    link.StudentID = id
+    link.GuardianID = guardianID
+
+    if err := link.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    updatedLink, err := a.storage.UpdateGuardianLink(link)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updatedLink)
+}
+
+func (a *API) UnlinkGuardian(w http.ResponseWriter, r *http.Request) {
+    id, guardianID, err := guardianLinkIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    if err := a.storage.UnlinkGuardian(id, guardianID); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGuardianSummary generates an update on the student for one of their
+// guardians, in the guardian's preferred language.
+func (a *API) GetGuardianSummary(w http.ResponseWriter, r *http.Request) {
+    id, guardianID, err := guardianLinkIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    student, err := a.storage.GetByID(id)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    link, err := a.storage.GetStudentGuardian(id, guardianID)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    summary, err := ai.GenerateGuardianSummary(r.Context(), a.summaryContext(student, false), link)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]string{
+        "summary":  summary,
+        "language": link.Guardian.PreferredLanguage,
+    })
+}
+
+// guardianLinkIDs parses the student and guardian IDs of a guardian link
+// route.
+func guardianLinkIDs(r *http.Request) (int, int, error) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid student ID")
+    }
+    guardianID, err := strconv.Atoi(mux.Vars(r)["guardianId"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid guardian ID")
+    }
+    return id, guardianID, nil
+}
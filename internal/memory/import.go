@@ -0,0 +1,60 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/importer"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// ImportStudents creates or updates students from a CSV roster in the
+// request body.
+func (a *API) ImportStudents(w http.ResponseWriter, r *http.Request) {
+    opts, err := importer.OptionsFromRequest(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    report, err := importer.Run(r.Body, importTarget{api: a, r: r}, opts)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(report)
+}
+
+// importTarget writes imported students to storage, auditing them as
+// changes made by r.
+type importTarget struct {
+    api *API
+    r   *http.Request
+}
+
+func (t importTarget) CustomFields() ([]models.CustomField, error) {
+    return t.api.storage.GetCustomFields(), nil
+}
+
+func (t importTarget) FindByEmail(email string) (*models.Student, error) {
+    student, err := t.api.storage.GetByEmail(email)
+    if apperror.Is(err, apperror.KindNotFound) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &student, nil
+}
+
+func (t importTarget) Create(student models.Student) (models.Student, error) {
+    return t.api.storage.Create(student, actor(t.r))
+}
+
+func (t importTarget) Update(id int, student models.Student) (models.Student, error) {
+    _, updated, err := t.api.storage.Update(id, student, actor(t.r))
+    return updated, err
+}
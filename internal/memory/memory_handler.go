@@ -1,41 +1,60 @@
 package api
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"strconv"
-
-	"github.com/AashishKumar-3002/FealtyX/internal/ai"
-	"github.com/AashishKumar-3002/FealtyX/internal/models"
-	"github.com/AashishKumar-3002/FealtyX/internal/storage"
-	"github.com/gorilla/mux"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/ai"
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/auth"
+    "github.com/AashishKumar-3002/FealtyX/internal/export"
+    "github.com/AashishKumar-3002/FealtyX/internal/middleware"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/AashishKumar-3002/FealtyX/internal/storage"
+    "github.com/gorilla/mux"
 )
 
 type API struct {
     storage *storage.Storage
 }
 
-func NewAPI(storage *storage.Storage) *API {
-    return &API{storage: storage}
+func NewAPI(store *storage.Storage) *API {
+    return &API{storage: store}
+}
+
+// actor identifies the caller of r in the audit entries of its changes.
+func actor(r *http.Request) storage.Actor {
+    return storage.Actor{Name: middleware.Actor(r), RequestID: middleware.RequestIDFromContext(r.Context())}
+}
+
+// validateStudent checks the student's fields and its attributes against
+// the custom field definitions.
+func (a *API) validateStudent(student *models.Student) error {
+    if err := student.Validate(); err != nil {
+        return err
+    }
+    return student.ValidateAttributes(a.storage.GetCustomFields())
 }
 
 func (a *API) CreateStudent(w http.ResponseWriter, r *http.Request) {
     var student models.Student
     if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
         return
     }
 
-    if err := validateStudent(student); err != nil {
-        http.Error(w, err.Error(), http.StatusBadRequest)
+    if err := a.validateStudent(&student); err != nil {
+        apperror.Write(w, r, err)
         return
     }
 
-    createdStudent, err := a.storage.Create(student)
+    createdStudent, err := a.storage.Create(student, actor(r))
     if err != nil {
-        http.Error(w, "Failed to create student", http.StatusInternalServerError)
+        apperror.Write(w, r, err)
         return
     }
 
@@ -45,21 +64,117 @@ func (a *API) CreateStudent(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) GetAllStudents(w http.ResponseWriter, r *http.Request) {
-    students := a.storage.GetAll()
+    if email := r.URL.Query().Get("email"); email != "" {
+        a.getStudentsByEmail(w, r, email)
+        return
+    }
+
+    page, err := models.ParseStudentPage(r.URL.Query())
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+    fields := a.storage.GetCustomFields()
+    filter, err := studentFilter(r, fields)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    students, next := a.storage.List(filter, page)
+    setNextLink(w, r, next)
+    writeStudents(w, r, students, fields)
+}
+
+// studentFilter reads the include_deleted, range and attribute filters of
+// a student listing. Advisors only see the students assigned to them.
+func studentFilter(r *http.Request, fields []models.CustomField) (models.StudentFilter, error) {
+    filter := models.StudentFilter{Advisor: auth.AdvisorScope(r.Context())}
+    if v := r.URL.Query().Get("include_deleted"); v != "" {
+        var err error
+        if filter.IncludeDeleted, err = strconv.ParseBool(v); err != nil {
+            return filter, apperror.BadRequest("include_deleted must be a boolean")
+        }
+    }
+    if err := filter.ParseRanges(r.URL.Query()); err != nil {
+        return filter, err
+    }
+    var err error
+    filter.Attributes, err = models.ParseAttributeFilters(r.URL.Query(), fields)
+    return filter, err
+}
+
+// setNextLink points the client at the next page of a listing, if there
+// is one, with a Link header that repeats the request's query with the
+// cursor in after.
+func setNextLink(w http.ResponseWriter, r *http.Request, next *models.StudentCursor) {
+    if next == nil {
+        return
+    }
+    u := *r.URL
+    q := u.Query()
+    q.Set("after", next.Encode())
+    u.RawQuery = q.Encode()
+    w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", u.RequestURI()))
+}
+
+// writeStudents writes a student listing as JSON or, if the client asks for
+// it, in one of the export formats.
+func writeStudents(w http.ResponseWriter, r *http.Request, students []models.Student, fields []models.CustomField) {
+    if format := export.Negotiate(r.Header.Get("Accept")); format != export.FormatJSON {
+        if err := export.WriteAll(w, format, students, fields); err != nil {
+            log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+        }
+        return
+    }
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(students)
 }
 
+// getStudentsByEmail answers GET /students?email= with a list holding the
+// matching student, or an empty list.
+func (a *API) getStudentsByEmail(w http.ResponseWriter, r *http.Request, email string) {
+    students := []models.Student{}
+    student, err := a.storage.GetByEmail(email)
+    switch {
+    case err == nil:
+        if advisor := auth.AdvisorScope(r.Context()); advisor == "" || a.storage.IsAssigned(advisor, student.ID) {
+            students = append(students, student)
+        }
+    case !apperror.Is(err, apperror.KindNotFound):
+        apperror.Write(w, r, err)
+        return
+    }
+
+    writeStudents(w, r, students, a.storage.GetCustomFields())
+}
+
 func (a *API) GetStudentByID(w http.ResponseWriter, r *http.Request) {
     id, err := strconv.Atoi(mux.Vars(r)["id"])
     if err != nil {
-        http.Error(w, "Invalid student ID", http.StatusBadRequest)
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+        at, err := time.Parse(time.RFC3339, asOf)
+        if err != nil {
+            apperror.Write(w, r, apperror.BadRequest("as_of must be an RFC 3339 timestamp"))
+            return
+        }
+        student, err := a.storage.AsOf(id, at)
+        if err != nil {
+            apperror.Write(w, r, err)
+            return
+        }
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(student)
         return
     }
 
     student, err := a.storage.GetByID(id)
     if err != nil {
-        http.Error(w, "Student not found", http.StatusNotFound)
+        apperror.Write(w, r, err)
         return
     }
 
@@ -67,27 +182,44 @@ func (a *API) GetStudentByID(w http.ResponseWriter, r *http.Request) {
     json.NewEncoder(w).Encode(student)
 }
 
+func (a *API) GetStudentHistory(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+    // Like the SQL backend, answer 404 for a student this tenant does not
+    // hold rather than an empty history.
+    if _, err := a.storage.GetIncludingDeleted(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.History(id))
+}
+
 func (a *API) UpdateStudent(w http.ResponseWriter, r *http.Request) {
     id, err := strconv.Atoi(mux.Vars(r)["id"])
     if err != nil {
-        http.Error(w, "Invalid student ID", http.StatusBadRequest)
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
         return
     }
 
     var student models.Student
     if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
-        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
         return
     }
 
-    if err := validateStudent(student); err != nil {
-        http.Error(w, err.Error(), http.StatusBadRequest)
+    if err := a.validateStudent(&student); err != nil {
+        apperror.Write(w, r, err)
         return
     }
 
-    updatedStudent, err := a.storage.Update(id, student)
+    _, updatedStudent, err := a.storage.Update(id, student, actor(r))
     if err != nil {
-        http.Error(w, "Student not found", http.StatusNotFound)
+        apperror.Write(w, r, err)
         return
     }
 
@@ -98,52 +230,110 @@ func (a *API) UpdateStudent(w http.ResponseWriter, r *http.Request) {
 func (a *API) DeleteStudent(w http.ResponseWriter, r *http.Request) {
     id, err := strconv.Atoi(mux.Vars(r)["id"])
     if err != nil {
-        http.Error(w, "Invalid student ID", http.StatusBadRequest)
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
         return
     }
 
-    if err := a.storage.Delete(id); err != nil {
-        http.Error(w, "Student not found", http.StatusNotFound)
+    if _, err := a.storage.Delete(id, actor(r)); err != nil {
+        apperror.Write(w, r, err)
         return
     }
 
     w.WriteHeader(http.StatusNoContent)
 }
 
+func (a *API) RestoreStudent(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    _, student, err := a.storage.Restore(id, actor(r))
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(student)
+}
+
+// GenerateStudentSummary generates a summary of the student. With
+// ?mode=notes the advisor notes are condensed into it as well.
 func (a *API) GenerateStudentSummary(w http.ResponseWriter, r *http.Request) {
     id, err := strconv.Atoi(mux.Vars(r)["id"])
     if err != nil {
-        http.Error(w, "Invalid student ID", http.StatusBadRequest)
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
         return
     }
 
     student, err := a.storage.GetByID(id)
     if err != nil {
-        http.Error(w, "Student not found", http.StatusNotFound)
+        apperror.Write(w, r, err)
         return
     }
 
-    summary, err := ai.GenerateStudentSummary(student)
+    includeNotes, err := summaryIncludesNotes(r)
     if err != nil {
-        log.Printf("Error generating summary: %v", err)
-        http.Error(w, "Failed to generate summary", http.StatusInternalServerError)
+        apperror.Write(w, r, err)
         return
     }
 
+    summary, err := ai.GenerateStudentSummary(r.Context(), a.summaryContext(student, includeNotes))
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    // The summary is kept for search; failing to do so should not cost
+    // the client the summary it asked for.
+    if _, err := a.storage.SaveSummary(models.StudentSummary{StudentID: id, Summary: summary}); err != nil {
+        log.Printf("Error saving the summary of student %d: %v", id, err)
+    }
+
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(map[string]string{"summary": summary})
 }
 
-func validateStudent(student models.Student) error {
-    if student.Name == "" {
-        return fmt.Errorf("name is required")
+// SearchStudents answers GET /students/search?q= with the live students
+// whose name and email, latest summary or one of whose notes hold every
+// word of q, best first, with the matches highlighted.
+func (a *API) SearchStudents(w http.ResponseWriter, r *http.Request) {
+    q, limit, err := models.ParseSearchQuery(r.URL.Query())
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
     }
-    if student.Age <= 0 {
-        return fmt.Errorf("age must be positive")
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.Search(q, limit, auth.AdvisorScope(r.Context())))
+}
+
+// summaryIncludesNotes reads the summary mode: "standard" (the default) or
+// "notes".
+func summaryIncludesNotes(r *http.Request) (bool, error) {
+    switch r.URL.Query().Get("mode") {
+    case "", "standard":
+        return false, nil
+    case "notes":
+        return true, nil
+    }
+    return false, apperror.BadRequest("mode must be standard or notes")
+}
+
+// summaryContext gathers the student's related records for the summary
+// prompt.
+func (a *API) summaryContext(student models.Student, includeNotes bool) ai.SummaryContext {
+    sc := ai.SummaryContext{
+        Student:      student,
+        CustomFields: a.storage.GetCustomFields(),
+        Enrollments:  a.storage.GetEnrollments(student.ID),
+        Grades:       a.storage.GetGrades(student.ID),
+        Attendance:   a.storage.GetAttendance(student.ID, models.DateRange{}),
     }
-    if student.Email == "" {
-        return fmt.Errorf("email is required")
+    if includeNotes {
+        sc.Notes = a.storage.GetNotes(student.ID)
     }
-    // You can add more validation rules here
-    return nil
+    return sc
 }
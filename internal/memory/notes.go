@@ -0,0 +1,132 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/middleware"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/gorilla/mux"
+)
+
+func (a *API) GetStudentNotes(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    if _, err := a.storage.GetByID(id); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(a.storage.GetNotes(id))
+}
+
+func (a *API) CreateNote(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid student ID"))
+        return
+    }
+
+    var note models.Note
+    if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+    note.StudentID = id
+    note.Author = middleware.Actor(r)
+
+    if err := note.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    createdNote, err := a.storage.CreateNote(note)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(createdNote)
+}
+
+func (a *API) GetNote(w http.ResponseWriter, r *http.Request) {
+    id, noteID, err := noteIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    note, err := a.storage.GetNote(id, noteID)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(note)
+}
+
+func (a *API) UpdateNote(w http.ResponseWriter, r *http.Request) {
+    id, noteID, err := noteIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    var note models.Note
+    if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+        apperror.Write(w, r, apperror.BadRequest("invalid request body"))
+        return
+    }
+
+    if err := note.Validate(); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    updatedNote, err := a.storage.UpdateNote(id, noteID, note.Body)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updatedNote)
+}
+
+func (a *API) DeleteNote(w http.ResponseWriter, r *http.Request) {
+    id, noteID, err := noteIDs(r)
+    if err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    if err := a.storage.DeleteNote(id, noteID); err != nil {
+        apperror.Write(w, r, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// noteIDs parses the student and note IDs of a note route.
+func noteIDs(r *http.Request) (int, int, error) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid student ID")
+    }
+    noteID, err := strconv.Atoi(mux.Vars(r)["noteId"])
+    if err != nil {
+        return 0, 0, apperror.BadRequest("invalid note ID")
+    }
+    return id, noteID, nil
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/AashishKumar-3002/FealtyX/internal/storage"
+    "github.com/AashishKumar-3002/FealtyX/internal/tenant"
+)
+
+// Tenants keeps an API, with its own storage, for each
+// tenant, so that a request can only reach the records of the tenant it
+// acts for.
+type Tenants struct {
+    open   func(id string) (*storage.Storage, error)
+    stored func() ([]string, error)
+
+    // mutex only guards the map: tenants are opened outside it, so that
+    // opening one does not hold up requests for the others.
+    mutex sync.RWMutex
+    apis  map[string]*tenantAPI
+
+    storedOnce sync.Once
+    storedErr  error
+}
+
+// tenantAPI is the API of a tenant, once its storage is open.
+type tenantAPI struct {
+    // opened is closed when api or err is set.
+    opened chan struct{}
+    api    *API
+    err    error
+}
+
+// NewTenants returns a registry that opens the storage of a tenant with
+// open the first time the tenant is used. stored lists the tenants that
+// already have storage, which are all opened before API keys are first
+// looked up, since a key may belong to any of them; it may be nil if none
+// do.
+func NewTenants(open func(id string) (*storage.Storage, error), stored func() ([]string, error)) *Tenants {
+    return &Tenants{open: open, stored: stored, apis: map[string]*tenantAPI{}}
+}
+
+// API returns the API of the tenant with the given ID. Requests for a
+// tenant that is being opened wait for it; if it fails to open, the next
+// request tries again.
+func (t *Tenants) API(id string) (*API, error) {
+    t.mutex.RLock()
+    ta, ok := t.apis[id]
+    t.mutex.RUnlock()
+    if !ok {
+        t.mutex.Lock()
+        if ta, ok = t.apis[id]; !ok {
+            ta = &tenantAPI{opened: make(chan struct{})}
+            t.apis[id] = ta
+        }
+        t.mutex.Unlock()
+        if !ok {
+            t.openAPI(id, ta)
+        }
+    }
+
+    <-ta.opened
+    return ta.api, ta.err
+}
+
+func (t *Tenants) openAPI(id string, ta *tenantAPI) {
+    defer close(ta.opened)
+    store, err := t.open(id)
+    if err != nil {
+        ta.err = err
+        t.mutex.Lock()
+        delete(t.apis, id)
+        t.mutex.Unlock()
+        return
+    }
+    ta.api = NewAPI(store)
+}
+
+// OpenStored opens every tenant that already has storage. Only the first
+// call does so; later ones return its error.
+func (t *Tenants) OpenStored() error {
+    t.storedOnce.Do(func() {
+        if t.stored == nil {
+            return
+        }
+        ids, err := t.stored()
+        if err != nil {
+            t.storedErr = err
+            return
+        }
+        var errs []error
+        for _, id := range ids {
+            if _, err := t.API(id); err != nil {
+                errs = append(errs, fmt.Errorf("tenant %s: %w", id, err))
+            }
+        }
+        t.storedErr = errors.Join(errs...)
+    })
+    return t.storedErr
+}
+
+// Handle serves each request with handler on the API of the request's
+// tenant.
+func (t *Tenants) Handle(handler func(*API, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        a, err := t.API(tenant.ID(r.Context()))
+        if err != nil {
+            apperror.Write(w, r, err)
+            return
+        }
+        handler(a, w, r)
+    }
+}
+
+// each calls fn with every tenant opened so far, in the order of their IDs,
+// and joins the errors it returns. Tenants still being opened are left out.
+func (t *Tenants) each(fn func(id string, a *API) error) error {
+    t.mutex.RLock()
+    apis := make(map[string]*API, len(t.apis))
+    ids := make([]string, 0, len(t.apis))
+    for id, ta := range t.apis {
+        select {
+        case <-ta.opened:
+            if ta.err == nil {
+                apis[id] = ta.api
+                ids = append(ids, id)
+            }
+        default:
+        }
+    }
+    t.mutex.RUnlock()
+
+    sort.Strings(ids)
+    var errs []error
+    for _, id := range ids {
+        if err := fn(id, apis[id]); err != nil {
+            errs = append(errs, fmt.Errorf("tenant %s: %w", id, err))
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// GetAPIKeyByHash finds the key with the given hash among the keys of
+// every tenant, and sets the tenant it belongs to. The tenants with
+// storage are opened first; every other one was opened to create its keys.
+func (t *Tenants) GetAPIKeyByHash(hash string) (models.APIKey, error) {
+    if err := t.OpenStored(); err != nil {
+        return models.APIKey{}, err
+    }
+    var found *models.APIKey
+    t.each(func(id string, a *API) error {
+        if key, err := a.storage.GetAPIKeyByHash(hash); err == nil {
+            key.Tenant = id
+            found = &key
+        }
+        return nil
+    })
+    if found == nil {
+        return models.APIKey{}, apperror.NotFound("API key not found")
+    }
+    return *found, nil
+}
+
+// IsAssigned reports whether a student of the tenant of ctx is assigned to
+// advisor.
+func (t *Tenants) IsAssigned(ctx context.Context, advisor string, studentID int) (bool, error) {
+    a, err := t.API(tenant.ID(ctx))
+    if err != nil {
+        return false, err
+    }
+    return a.storage.IsAssigned(advisor, studentID), nil
+}
+
+// Purge purges the students deleted before the given time from every
+// tenant.
+func (t *Tenants) Purge(before time.Time) (int64, error) {
+    var purged int64
+    err := t.each(func(id string, a *API) error {
+        n, err := a.storage.Purge(before)
+        purged += n
+        return err
+    })
+    return purged, err
+}
+
+// Snapshot compacts the write-ahead log of every tenant.
+func (t *Tenants) Snapshot() error {
+    return t.each(func(id string, a *API) error {
+        return a.storage.Snapshot()
+    })
+}
+
+// Close closes the storage of every tenant.
+func (t *Tenants) Close() error {
+    return t.each(func(id string, a *API) error {
+        return a.storage.Close()
+    })
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/backendtest"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/AashishKumar-3002/FealtyX/internal/storage"
+)
+
+func TestTenantsOpenEachTenantOnceWithoutBlockingOthers(t *testing.T) {
+    release := make(chan struct{})
+    var mu sync.Mutex
+    opens := map[string]int{}
+    tenants := NewTenants(func(id string) (*storage.Storage, error) {
+        mu.Lock()
+        opens[id]++
+        mu.Unlock()
+        if id == "slow" {
+            <-release
+        }
+        return storage.NewStorage(), nil
+    }, nil)
+
+    var wg sync.WaitGroup
+    apis := make([]*API, 3)
+    for i := range apis {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            apis[i], _ = tenants.API("slow")
+        }()
+    }
+
+    // Another tenant opens while the slow one is still opening.
+    done := make(chan struct{})
+    go func() {
+        tenants.API("fast")
+        close(done)
+    }()
+    select {
+    case <-done:
+    case <-time.After(5 * time.Second):
+        t.Fatal("opening a tenant waited for another tenant to open")
+    }
+
+    close(release)
+    wg.Wait()
+    if apis[0] == nil || apis[1] != apis[0] || apis[2] != apis[0] {
+        t.Errorf("expected every request to get the same API, got %v", apis)
+    }
+    if opens["slow"] != 1 || opens["fast"] != 1 {
+        t.Errorf("expected each tenant to be opened once, got %v", opens)
+    }
+}
+
+func TestTenantsRetryAFailedOpen(t *testing.T) {
+    fail := true
+    tenants := NewTenants(func(id string) (*storage.Storage, error) {
+        if fail {
+            return nil, errors.New("disk on fire")
+        }
+        return storage.NewStorage(), nil
+    }, nil)
+
+    if _, err := tenants.API("north"); err == nil {
+        t.Fatal("expected the failed open to be returned")
+    }
+    fail = false
+    if a, err := tenants.API("north"); err != nil || a == nil {
+        t.Errorf("expected the tenant to open on the next request, got %v", err)
+    }
+}
+
+func TestGetAPIKeyByHashOpensStoredTenants(t *testing.T) {
+    stores := map[string]*storage.Storage{"default": storage.NewStorage(), "north": storage.NewStorage()}
+    if _, err := stores["north"].CreateAPIKey(models.APIKey{Name: "registrar", Role: "admin"}, "hash"); err != nil {
+        t.Fatal(err)
+    }
+    tenants := NewTenants(func(id string) (*storage.Storage, error) {
+        return stores[id], nil
+    }, func() ([]string, error) {
+        return []string{"default", "north"}, nil
+    })
+
+    // Only the default tenant has been used so far.
+    if _, err := tenants.API("default"); err != nil {
+        t.Fatal(err)
+    }
+    key, err := tenants.GetAPIKeyByHash("hash")
+    if err != nil || key.Name != "registrar" || key.Tenant != "north" {
+        t.Errorf("expected the north tenant's key, got %+v, %v", key, err)
+    }
+}
+
+func TestTenantsAreIsolated(t *testing.T) {
+    tenants := NewTenants(func(string) (*storage.Storage, error) {
+        return storage.NewStorage(), nil
+    }, nil)
+    backendtest.TenantIsolation(t, backendtest.TenantRoutes{
+        Create: tenants.Handle((*API).CreateStudent), Get: tenants.Handle((*API).GetStudentByID),
+        Update: tenants.Handle((*API).UpdateStudent), Delete: tenants.Handle((*API).DeleteStudent),
+        History: tenants.Handle((*API).GetStudentHistory), Export: tenants.Handle((*API).ExportStudents),
+        Search: tenants.Handle((*API).SearchStudents), ImportStudents: tenants.Handle((*API).ImportStudents),
+    })
+}
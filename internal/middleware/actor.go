@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+)
+
+const (
+	actorHeader    = "X-Actor"
+	anonymousActor = "anonymous"
+)
+
+// Actor identifies who is making the request for the audit log: the
+// authenticated principal or, with authentication turned off, the X-Actor
+// header.
+func Actor(r *http.Request) string {
+	if p := auth.FromContext(r.Context()); p != nil {
+		return p.Subject
+	}
+	if actor := r.Header.Get(actorHeader); actor != "" {
+		return actor
+	}
+	return anonymousActor
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+)
+
+const (
+	// lastWriteCookie holds when the client last wrote, in Unix milliseconds.
+	lastWriteCookie = "fealtyx_last_write"
+	// lastWriteHeader carries the same time, for clients that do not keep
+	// cookies: writes answer with it, and reads may send it back.
+	lastWriteHeader = "X-Last-Write"
+)
+
+// ReadYourWrites sends the reads of write requests, and of requests made
+// within window of the client's last write, to the primary database rather
+// than a replica that may not have caught up. Clients are recognized by a
+// cookie set on each write, or by sending back the X-Last-Write header of
+// their last write's response.
+func ReadYourWrites(window time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			switch {
+			case !readOnly(r.Method):
+				lastWrite := strconv.FormatInt(now.UnixMilli(), 10)
+				w.Header().Set(lastWriteHeader, lastWrite)
+				http.SetCookie(w, &http.Cookie{
+					Name:     lastWriteCookie,
+					Value:    lastWrite,
+					Path:     "/",
+					MaxAge:   int(window.Seconds()) + 1,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+				r = r.WithContext(database.WithPrimary(r.Context()))
+			case wroteSince(r, now.Add(-window)):
+				r = r.WithContext(database.WithPrimary(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func readOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// wroteSince reports whether r's header or cookie says the client wrote
+// after t.
+func wroteSince(r *http.Request, t time.Time) bool {
+	lastWrite := r.Header.Get(lastWriteHeader)
+	if lastWrite == "" {
+		cookie, err := r.Cookie(lastWriteCookie)
+		if err != nil {
+			return false
+		}
+		lastWrite = cookie.Value
+	}
+	ms, err := strconv.ParseInt(lastWrite, 10, 64)
+	return err == nil && time.UnixMilli(ms).After(t)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	var primary bool
+	handler := ReadYourWrites(2 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primary = database.PrimaryRequired(r.Context())
+	}))
+	serve := func(method, cookie, header string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/students", nil)
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: lastWriteCookie, Value: cookie})
+		}
+		if header != "" {
+			r.Header.Set(lastWriteHeader, header)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+	ago := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(-d).UnixMilli(), 10)
+	}
+
+	rr := serve(http.MethodPost, "", "")
+	cookies := rr.Result().Cookies()
+	if !primary || len(cookies) != 1 || cookies[0].Name != lastWriteCookie || cookies[0].MaxAge != 3 {
+		t.Fatalf("expected a write to use the primary and set the cookie, got %v, %v", primary, cookies)
+	}
+	ms, err := strconv.ParseInt(cookies[0].Value, 10, 64)
+	if err != nil || time.Since(time.UnixMilli(ms)) > time.Second {
+		t.Errorf("expected the cookie to hold the time of the write, got %q", cookies[0].Value)
+	}
+	if header := rr.Header().Get(lastWriteHeader); header != cookies[0].Value {
+		t.Errorf("expected the %s header to match the cookie, got %q", lastWriteHeader, header)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		cookie, header string
+		primary        bool
+	}{
+		{"no cookie", "", "", false},
+		{"the write just made", cookies[0].Value, "", true},
+		{"a write within the window", ago(1500 * time.Millisecond), "", true},
+		{"a write before the window", ago(3 * time.Second), "", false},
+		{"an unreadable cookie", "yesterday", "", false},
+		{"a header within the window", "", ago(1500 * time.Millisecond), true},
+		{"a header before the window", "", ago(3 * time.Second), false},
+		{"a header overriding an old cookie", ago(3 * time.Second), ago(time.Second), true},
+	} {
+		rr := serve(http.MethodGet, tc.cookie, tc.header)
+		if primary != tc.primary {
+			t.Errorf("read after %s: primary %v, want %v", tc.name, primary, tc.primary)
+		}
+		if len(rr.Result().Cookies()) != 0 {
+			t.Errorf("read after %s set a cookie", tc.name)
+		}
+	}
+}
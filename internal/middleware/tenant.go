@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/gorilla/mux"
+)
+
+// Tenant resolves the tenant each request acts for and passes it down in
+// the request context. A principal that belongs to a tenant acts for it,
+// and the X-Tenant-ID header may only repeat it. The bootstrap admin key,
+// and anyone while authentication is off, pick a tenant with the header
+// and get the default one without it. Tenant goes after the
+// authenticator's middleware.
+func Tenant(dir *tenant.Directory) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t, err := resolveTenant(dir, r)
+			if err != nil {
+				apperror.Write(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(tenant.WithTenant(r.Context(), t)))
+		})
+	}
+}
+
+func resolveTenant(dir *tenant.Directory, r *http.Request) (tenant.Tenant, error) {
+	id := r.Header.Get(tenant.Header)
+	if p := auth.FromContext(r.Context()); p != nil && p.Tenant != "" {
+		if id != "" && id != p.Tenant {
+			return tenant.Tenant{}, apperror.Forbidden("you may not act for tenant %s", id)
+		}
+		id = p.Tenant
+	}
+	if id == "" {
+		id = tenant.Default
+	}
+	return dir.Lookup(id)
+}
+
+// StudentLookup reports whether a student, deleted or not, belongs to the
+// tenant of ctx.
+type StudentLookup func(ctx context.Context, studentID int) (bool, error)
+
+// TenantStudents answers requests for the student of another tenant with
+// 404, as if there were no such student, for backends that keep the
+// students of every tenant together. It goes after Tenant, on a router, so
+// that the route has been matched.
+func TenantStudents(owned StudentLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var template string
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ = route.GetPathTemplate()
+			}
+			id, err := strconv.Atoi(mux.Vars(r)["id"])
+			if err != nil || !strings.HasPrefix(template, "/students/{id") {
+				// Not a student route, or the handler rejects the ID.
+				next.ServeHTTP(w, r)
+				return
+			}
+			ok, err := owned(r.Context(), id)
+			if err == nil && !ok {
+				err = apperror.NotFound("student %d not found", id)
+			}
+			if err != nil {
+				apperror.Write(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
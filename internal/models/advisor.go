@@ -0,0 +1,96 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+// StudentAdvisor assigns a student to an advisor, who may then see and
+// change the student's records. Advisor is the subject the advisor
+// authenticates as: "api-key:" followed by a key's name, or the sub claim of
+// a token.
+type StudentAdvisor struct {
+	StudentID  int       `json:"student_id"`
+	Advisor    string    `json:"advisor" validate:"required,max=200"`
+	AssignedBy string    `json:"assigned_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (a *StudentAdvisor) Validate() error {
+	return validationError("advisor assignment is invalid", validator.Validate(a))
+}
+
+const studentAdvisorColumns = "student_id, advisor, assigned_by, created_at"
+
+func scanStudentAdvisor(row rowScanner) (StudentAdvisor, error) {
+	var a StudentAdvisor
+	err := row.Scan(&a.StudentID, &a.Advisor, &a.AssignedBy, &a.CreatedAt)
+	return a, err
+}
+
+func (a *StudentAdvisor) Create(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO student_advisors (student_id, advisor, assigned_by)
+		VALUES ($1, $2, $3) RETURNING created_at`,
+		a.StudentID, a.Advisor, a.AssignedBy).Scan(&a.CreatedAt)
+	return translateError(err)
+}
+
+// GetStudentAdvisors lists the advisors a student is assigned to, in the
+// order they were assigned.
+func GetStudentAdvisors(ctx context.Context, db DBTX, studentID int) ([]StudentAdvisor, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+studentAdvisorColumns+` FROM student_advisors
+		WHERE student_id = $1 ORDER BY created_at, advisor`, studentID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	advisors := []StudentAdvisor{}
+	for rows.Next() {
+		a, err := scanStudentAdvisor(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		advisors = append(advisors, a)
+	}
+	return advisors, translateError(rows.Err())
+}
+
+// IsAssigned reports whether the student is assigned to the advisor.
+func IsAssigned(ctx context.Context, db DBTX, advisor string, studentID int) (bool, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var assigned bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM student_advisors
+		WHERE student_id = $1 AND advisor = $2)`, studentID, advisor).Scan(&assigned)
+	return assigned, translateError(err)
+}
+
+func DeleteStudentAdvisor(ctx context.Context, db DBTX, studentID int, advisor string) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM student_advisors WHERE student_id = $1 AND advisor = $2", studentID, advisor)
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("student %d is not assigned to %s", studentID, advisor)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+// APIKey is a stored API key. Only a hash of the key itself is kept, so it
+// cannot be shown again after it is created.
+type APIKey struct {
+	ID   int    `json:"id"`
+	Name string `json:"name" validate:"required,max=100"`
+	// Prefix is the start of the key, to tell keys apart.
+	Prefix string `json:"prefix"`
+	// Role is what requests made with the key may do: admin, advisor or
+	// viewer.
+	Role string `json:"role" validate:"oneof=admin advisor viewer"`
+	// Tenant is the tenant the key acts for: the one it was created for.
+	Tenant    string    `json:"tenant"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewAPIKey is a key that was just created, along with the key itself.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Validate checks the key after defaulting its role to viewer, so that a
+// key can do no more than read unless it is given a role.
+func (k *APIKey) Validate() error {
+	if k.Role == "" {
+		k.Role = "viewer"
+	}
+	return validationError("API key is invalid", validator.Validate(k))
+}
+
+const apiKeyColumns = "id, name, prefix, role, tenant_id, created_by, created_at"
+
+func scanAPIKey(row rowScanner) (APIKey, error) {
+	var k APIKey
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Role, &k.Tenant, &k.CreatedBy, &k.CreatedAt)
+	return k, err
+}
+
+// Create stores the key under the hash it is looked up by, for the tenant
+// of ctx.
+func (k *APIKey) Create(ctx context.Context, db DBTX, hash string) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	k.Tenant = tenant.ID(ctx)
+	err := db.QueryRowContext(ctx, `INSERT INTO api_keys (tenant_id, name, prefix, key_hash, role, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		k.Tenant, k.Name, k.Prefix, hash, k.Role, k.CreatedBy).Scan(&k.ID, &k.CreatedAt)
+	return translateError(err)
+}
+
+// GetAPIKeys lists the keys of the tenant of ctx.
+func GetAPIKeys(ctx context.Context, db DBTX) ([]APIKey, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE tenant_id = $1 ORDER BY id", tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, translateError(rows.Err())
+}
+
+// GetAPIKeyByHash finds the key with the given hash, whatever its tenant,
+// since the tenant of a request is only known once its key is.
+func GetAPIKeyByHash(ctx context.Context, db DBTX, hash string) (APIKey, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	k, err := scanAPIKey(db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1", hash))
+	if err == sql.ErrNoRows {
+		return APIKey{}, apperror.NotFound("API key not found")
+	}
+	return k, translateError(err)
+}
+
+// DeleteAPIKey revokes a key; requests made with it fail from then on.
+func DeleteAPIKey(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM api_keys WHERE id = $1 AND tenant_id = $2", id, tenant.ID(ctx))
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("API key %d not found", id)
+	}
+	return nil
+}
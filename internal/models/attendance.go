@@ -0,0 +1,192 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+// DateLayout is the format of attendance dates.
+const DateLayout = "2006-01-02"
+
+// Attendance statuses.
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceLate    = "late"
+	AttendanceExcused = "excused"
+)
+
+// AttendanceRecord is a student's attendance on one day. CourseID is set
+// when the record was submitted for a class.
+type AttendanceRecord struct {
+	ID        int       `json:"id"`
+	StudentID int       `json:"student_id"`
+	CourseID  *int      `json:"course_id,omitempty"`
+	Date      string    `json:"date" validate:"required,datetime=2006-01-02"`
+	Status    string    `json:"status" validate:"required,oneof=present absent late excused"`
+	Note      string    `json:"note" validate:"max=500"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ClassAttendance is a bulk submission of one day's attendance for a course.
+type ClassAttendance struct {
+	Date    string `json:"date" validate:"required,datetime=2006-01-02"`
+	Records []struct {
+		StudentID int    `json:"student_id" validate:"required"`
+		Status    string `json:"status" validate:"required,oneof=present absent late excused"`
+		Note      string `json:"note" validate:"max=500"`
+	} `json:"records" validate:"required,min=1,dive"`
+}
+
+func (a *AttendanceRecord) Validate() error {
+	return validationError("attendance record is invalid", validator.Validate(a))
+}
+
+func (c *ClassAttendance) Validate() error {
+	return validationError("class attendance is invalid", validator.Validate(c))
+}
+
+// AttendanceRecords expands the submission into one record per student.
+func (c *ClassAttendance) AttendanceRecords(courseID int) []AttendanceRecord {
+	records := make([]AttendanceRecord, len(c.Records))
+	for i, r := range c.Records {
+		records[i] = AttendanceRecord{
+			StudentID: r.StudentID,
+			CourseID:  &courseID,
+			Date:      c.Date,
+			Status:    r.Status,
+			Note:      r.Note,
+		}
+	}
+	return records
+}
+
+// DateRange is an inclusive range of dates. A zero bound is open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseDateRange parses the from and to query parameters.
+func ParseDateRange(from, to string) (DateRange, error) {
+	var dr DateRange
+	var err error
+	if from != "" {
+		if dr.From, err = time.Parse(DateLayout, from); err != nil {
+			return dr, apperror.BadRequest("from must be a date like 2024-09-01")
+		}
+	}
+	if to != "" {
+		if dr.To, err = time.Parse(DateLayout, to); err != nil {
+			return dr, apperror.BadRequest("to must be a date like 2024-09-01")
+		}
+	}
+	if !dr.From.IsZero() && !dr.To.IsZero() && dr.To.Before(dr.From) {
+		return dr, apperror.BadRequest("to must not be before from")
+	}
+	return dr, nil
+}
+
+// Contains reports whether the date, in DateLayout, falls in the range.
+func (dr DateRange) Contains(date string) bool {
+	return (dr.From.IsZero() || date >= dr.From.Format(DateLayout)) &&
+		(dr.To.IsZero() || date <= dr.To.Format(DateLayout))
+}
+
+const attendanceColumns = "id, student_id, course_id, date, status, note, created_at, updated_at"
+
+func scanAttendance(row rowScanner) (AttendanceRecord, error) {
+	var a AttendanceRecord
+	var courseID sql.NullInt64
+	var date time.Time
+	err := row.Scan(&a.ID, &a.StudentID, &courseID, &date, &a.Status, &a.Note, &a.CreatedAt, &a.UpdatedAt)
+	if courseID.Valid {
+		id := int(courseID.Int64)
+		a.CourseID = &id
+	}
+	a.Date = date.Format(DateLayout)
+	return a, err
+}
+
+// Save records attendance for a live student, replacing any record for the
+// same day. The course, if any, must be of the student's tenant.
+func (a *AttendanceRecord) Save(ctx context.Context, db DBTX) error {
+	if a.CourseID != nil {
+		if err := checkCourse(ctx, db, *a.CourseID); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO attendance (student_id, course_id, date, status, note)
+		SELECT $1, $2, $3, $4, $5 WHERE EXISTS (SELECT 1 FROM students WHERE id = $1 AND tenant_id = $6 AND deleted_at IS NULL)
+		ON CONFLICT (student_id, date) DO UPDATE
+			SET course_id = EXCLUDED.course_id, status = EXCLUDED.status, note = EXCLUDED.note, updated_at = now()
+		RETURNING id, created_at, updated_at`,
+		a.StudentID, a.CourseID, a.Date, a.Status, a.Note, tenant.ID(ctx)).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("student %d not found", a.StudentID)
+	}
+	return translateError(err)
+}
+
+// GetStudentAttendance lists a student's attendance in the range, oldest
+// first.
+func GetStudentAttendance(ctx context.Context, db DBTX, studentID int, dr DateRange) ([]AttendanceRecord, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var from, to interface{}
+	if !dr.From.IsZero() {
+		from = dr.From.Format(DateLayout)
+	}
+	if !dr.To.IsZero() {
+		to = dr.To.Format(DateLayout)
+	}
+	d := database.DialectOf(db)
+	fromDate, toDate := d.Cast("$2", "date"), d.Cast("$3", "date")
+	rows, err := db.QueryContext(ctx, `SELECT `+attendanceColumns+` FROM attendance
+		WHERE student_id = $1 AND (`+fromDate+` IS NULL OR date >= `+fromDate+`) AND (`+toDate+` IS NULL OR date <= `+toDate+`)
+		ORDER BY date`, studentID, from, to)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	records := []AttendanceRecord{}
+	for rows.Next() {
+		a, err := scanAttendance(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		records = append(records, a)
+	}
+	return records, translateError(rows.Err())
+}
+
+func DeleteAttendance(ctx context.Context, db DBTX, studentID int, date string) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM attendance WHERE student_id = $1 AND date = $2", studentID, date)
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("no attendance for student %d on %s", studentID, date)
+	}
+	return nil
+}
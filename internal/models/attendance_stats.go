@@ -0,0 +1,94 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// ChronicAbsenceThreshold is the share of school days missed, excused or
+// not, at which a student is considered chronically absent.
+const ChronicAbsenceThreshold = 0.10
+
+// AttendanceStats aggregates a student's attendance over a range of days.
+type AttendanceStats struct {
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	TotalDays   int    `json:"total_days"`
+	PresentDays int    `json:"present_days"`
+	AbsentDays  int    `json:"absent_days"`
+	LateDays    int    `json:"late_days"`
+	ExcusedDays int    `json:"excused_days"`
+	// AttendanceRate is the percentage of non-excused days attended,
+	// counting late arrivals as attended.
+	AttendanceRate float64 `json:"attendance_rate"`
+	// AbsenceRate is the percentage of non-excused days missed.
+	AbsenceRate float64 `json:"absence_rate"`
+	// CurrentStreak is the number of most recent consecutive records that
+	// were attended; CurrentAbsenceStreak the number that were absences.
+	CurrentStreak        int `json:"current_streak"`
+	LongestStreak        int `json:"longest_streak"`
+	CurrentAbsenceStreak int `json:"current_absence_streak"`
+	LongestAbsenceStreak int `json:"longest_absence_streak"`
+	// ChronicallyAbsent is set when excused and unexcused absences together
+	// make up ChronicAbsenceThreshold or more of all days, the usual
+	// definition of chronic absence.
+	ChronicallyAbsent bool `json:"chronically_absent"`
+}
+
+// CalculateAttendanceStats aggregates the records that fall within the range.
+// Excused days are left out of the rates and do not break streaks, but count
+// as missed towards chronic absence.
+func CalculateAttendanceStats(records []AttendanceRecord, dr DateRange) AttendanceStats {
+	var stats AttendanceStats
+	if !dr.From.IsZero() {
+		stats.From = dr.From.Format(DateLayout)
+	}
+	if !dr.To.IsZero() {
+		stats.To = dr.To.Format(DateLayout)
+	}
+
+	inRange := make([]AttendanceRecord, 0, len(records))
+	for _, r := range records {
+		if dr.Contains(r.Date) {
+			inRange = append(inRange, r)
+		}
+	}
+	sort.Slice(inRange, func(i, j int) bool { return inRange[i].Date < inRange[j].Date })
+
+	var streak, absenceStreak int
+	for _, r := range inRange {
+		stats.TotalDays++
+		switch r.Status {
+		case AttendancePresent, AttendanceLate:
+			if r.Status == AttendancePresent {
+				stats.PresentDays++
+			} else {
+				stats.LateDays++
+			}
+			streak++
+			absenceStreak = 0
+		case AttendanceAbsent:
+			stats.AbsentDays++
+			absenceStreak++
+			streak = 0
+		case AttendanceExcused:
+			stats.ExcusedDays++
+		}
+		stats.LongestStreak = max(stats.LongestStreak, streak)
+		stats.LongestAbsenceStreak = max(stats.LongestAbsenceStreak, absenceStreak)
+	}
+	stats.CurrentStreak = streak
+	stats.CurrentAbsenceStreak = absenceStreak
+
+	if counted := stats.TotalDays - stats.ExcusedDays; counted > 0 {
+		attended := float64(stats.PresentDays + stats.LateDays)
+		stats.AttendanceRate = math.Round(attended/float64(counted)*10000) / 100
+		absenceRate := float64(stats.AbsentDays) / float64(counted)
+		stats.AbsenceRate = math.Round(absenceRate*10000) / 100
+	}
+	if stats.TotalDays > 0 {
+		missed := float64(stats.AbsentDays + stats.ExcusedDays)
+		stats.ChronicallyAbsent = missed/float64(stats.TotalDays) >= ChronicAbsenceThreshold
+	}
+	return stats
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// FieldChange is the before and after value of one changed field.
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditEntry is an immutable record of one change to a student.
+type AuditEntry struct {
+	ID        int64                  `json:"id"`
+	StudentID int                    `json:"student_id"`
+	Action    AuditAction            `json:"action"`
+	Actor     string                 `json:"actor"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Before    *Student               `json:"before,omitempty"`
+	After     *Student               `json:"after,omitempty"`
+	Changes   map[string]FieldChange `json:"changes"`
+}
+
+// NewAuditEntry describes a change from before to after. Either may be nil.
+func NewAuditEntry(action AuditAction, before, after *Student, actor, requestID string) *AuditEntry {
+	e := &AuditEntry{
+		Action:    action,
+		Actor:     actor,
+		RequestID: requestID,
+		Timestamp: time.Now().UTC(),
+		Before:    before,
+		After:     after,
+		Changes:   DiffStudents(before, after),
+	}
+	if after != nil {
+		e.StudentID = after.ID
+	} else if before != nil {
+		e.StudentID = before.ID
+	}
+	return e
+}
+
+// DiffStudents returns the user-visible fields that differ between before
+// and after. A nil student counts as having every field unset.
+func DiffStudents(before, after *Student) map[string]FieldChange {
+	fields := func(s *Student) map[string]interface{} {
+		if s == nil {
+			return map[string]interface{}{}
+		}
+		m := map[string]interface{}{"name": s.Name, "age": s.Age, "email": s.Email}
+		if s.DeletedAt != nil {
+			m["deleted_at"] = *s.DeletedAt
+		}
+		return m
+	}
+	b, a := fields(before), fields(after)
+
+	changes := make(map[string]FieldChange)
+	for _, name := range []string{"name", "age", "email", "deleted_at"} {
+		if b[name] != a[name] {
+			changes[name] = FieldChange{From: b[name], To: a[name]}
+		}
+	}
+
+	// Custom field values are reported one by one as attributes.<name>.
+	attributes := func(s *Student) map[string]interface{} {
+		if s == nil {
+			return nil
+		}
+		return s.Attributes
+	}
+	ba, aa := attributes(before), attributes(after)
+	for name, from := range ba {
+		if to, ok := aa[name]; !ok || to != from {
+			changes["attributes."+name] = FieldChange{From: from, To: aa[name]}
+		}
+	}
+	for name, to := range aa {
+		if _, ok := ba[name]; !ok {
+			changes["attributes."+name] = FieldChange{From: nil, To: to}
+		}
+	}
+	return changes
+}
+
+func (e *AuditEntry) Create(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	before, err := json.Marshal(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := json.Marshal(e.After)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return err
+	}
+	return db.QueryRowContext(ctx, `INSERT INTO audit_log (student_id, action, actor, request_id, created_at, before, after, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		e.StudentID, e.Action, e.Actor, e.RequestID, e.Timestamp, before, after, changes).Scan(&e.ID)
+}
+
+const auditColumns = "id, student_id, action, actor, request_id, created_at, before, after, changes"
+
+func scanAuditEntry(row rowScanner) (AuditEntry, error) {
+	var e AuditEntry
+	var before, after, changes []byte
+	if err := row.Scan(&e.ID, &e.StudentID, &e.Action, &e.Actor, &e.RequestID, &e.Timestamp, &before, &after, &changes); err != nil {
+		return e, err
+	}
+	// JSON null leaves the pointers nil.
+	if err := json.Unmarshal(before, &e.Before); err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(after, &e.After); err != nil {
+		return e, err
+	}
+	return e, json.Unmarshal(changes, &e.Changes)
+}
+
+// GetStudentHistory lists every audit entry for a student, oldest first.
+func GetStudentHistory(ctx context.Context, db DBTX, studentID int) ([]AuditEntry, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+auditColumns+" FROM audit_log WHERE student_id = $1 ORDER BY created_at, id", studentID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, translateError(rows.Err())
+}
+
+// GetStudentAsOf reconstructs a student as it was at the given time from the
+// audit log.
+func GetStudentAsOf(ctx context.Context, db DBTX, studentID int, at time.Time) (*Student, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	e, err := scanAuditEntry(db.QueryRowContext(ctx, "SELECT "+auditColumns+` FROM audit_log
+		WHERE student_id = $1 AND created_at <= $2 ORDER BY created_at DESC, id DESC LIMIT 1`, studentID, at))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("student %d did not exist at %s", studentID, at.Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return StudentFromAudit(e, at)
+}
+
+// StudentFromAudit returns the student recorded by the latest entry at or
+// before the given time, or not found if the student was deleted then.
+func StudentFromAudit(latest AuditEntry, at time.Time) (*Student, error) {
+	if latest.After == nil || latest.After.IsDeleted() {
+		return nil, apperror.NotFound("student %d did not exist at %s", latest.StudentID, at.Format(time.RFC3339))
+	}
+	return latest.After, nil
+}
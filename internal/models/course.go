@@ -0,0 +1,232 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+type Course struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code" validate:"required,max=20"`
+	Title       string `json:"title" validate:"required,max=200"`
+	Description string `json:"description" validate:"max=2000"`
+	Credits     int    `json:"credits" validate:"gte=0,lte=30"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Enrollment statuses.
+const (
+	EnrollmentActive    = "active"
+	EnrollmentCompleted = "completed"
+	EnrollmentDropped   = "dropped"
+)
+
+// Enrollment links a student to a course. Course is filled in when
+// enrollments are listed.
+type Enrollment struct {
+	ID         int       `json:"id"`
+	StudentID  int       `json:"student_id"`
+	CourseID   int       `json:"course_id" validate:"required"`
+	Status     string    `json:"status" validate:"omitempty,oneof=active completed dropped"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Course     *Course   `json:"course,omitempty"`
+}
+
+func (c *Course) Validate() error {
+	return validationError("course is invalid", validator.Validate(c))
+}
+
+// Validate checks the enrollment and defaults its status to active.
+func (e *Enrollment) Validate() error {
+	if err := validationError("enrollment is invalid", validator.Validate(e)); err != nil {
+		return err
+	}
+	if e.Status == "" {
+		e.Status = EnrollmentActive
+	}
+	return nil
+}
+
+const courseColumns = "id, code, title, description, credits, created_at, updated_at"
+
+func scanCourse(row rowScanner) (Course, error) {
+	var c Course
+	err := row.Scan(&c.ID, &c.Code, &c.Title, &c.Description, &c.Credits, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+func (c *Course) Create(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO courses (tenant_id, code, title, description, credits) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`,
+		tenant.ID(ctx), c.Code, c.Title, c.Description, c.Credits).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	return translateError(err)
+}
+
+func GetAllCourses(ctx context.Context, db DBTX) ([]Course, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+courseColumns+" FROM courses WHERE tenant_id = $1 ORDER BY id", tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	courses := []Course{}
+	for rows.Next() {
+		c, err := scanCourse(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		courses = append(courses, c)
+	}
+	return courses, translateError(rows.Err())
+}
+
+func GetCourse(ctx context.Context, db DBTX, id int) (*Course, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	c, err := scanCourse(db.QueryRowContext(ctx, "SELECT "+courseColumns+" FROM courses WHERE id = $1 AND tenant_id = $2", id, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("course %d not found", id)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &c, nil
+}
+
+func (c *Course) Update(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `UPDATE courses SET code = $1, title = $2, description = $3, credits = $4, updated_at = now()
+		WHERE id = $5 AND tenant_id = $6 RETURNING created_at, updated_at`,
+		c.Code, c.Title, c.Description, c.Credits, id, tenant.ID(ctx)).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("course %d not found", id)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	c.ID = id
+	return nil
+}
+
+// DeleteCourse removes a course and, through the foreign key, every
+// enrollment in it.
+func DeleteCourse(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM courses WHERE id = $1 AND tenant_id = $2", id, tenant.ID(ctx))
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("course %d not found", id)
+	}
+	return nil
+}
+
+// Create enrolls a live student in a course of the same tenant.
+func (e *Enrollment) Create(ctx context.Context, db DBTX) error {
+	if err := checkCourse(ctx, db, e.CourseID); err != nil {
+		return err
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO enrollments (student_id, course_id, status)
+		SELECT $1, $2, $3 WHERE EXISTS (SELECT 1 FROM students WHERE id = $1 AND tenant_id = $4 AND deleted_at IS NULL)
+		RETURNING id, enrolled_at, updated_at`,
+		e.StudentID, e.CourseID, e.Status, tenant.ID(ctx)).Scan(&e.ID, &e.EnrolledAt, &e.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("student %d not found", e.StudentID)
+	}
+	return translateError(err)
+}
+
+// GetStudentEnrollments lists a student's enrollments with their courses.
+func GetStudentEnrollments(ctx context.Context, db DBTX, studentID int) ([]Enrollment, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `SELECT e.id, e.student_id, e.course_id, e.status, e.enrolled_at, e.updated_at,
+			c.id, c.code, c.title, c.description, c.credits, c.created_at, c.updated_at
+		FROM enrollments e JOIN courses c ON c.id = e.course_id
+		WHERE e.student_id = $1 ORDER BY e.enrolled_at, e.id`, studentID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	enrollments := []Enrollment{}
+	for rows.Next() {
+		var e Enrollment
+		var c Course
+		err := rows.Scan(&e.ID, &e.StudentID, &e.CourseID, &e.Status, &e.EnrolledAt, &e.UpdatedAt,
+			&c.ID, &c.Code, &c.Title, &c.Description, &c.Credits, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		e.Course = &c
+		enrollments = append(enrollments, e)
+	}
+	return enrollments, translateError(rows.Err())
+}
+
+// UpdateEnrollmentStatus changes the status of a student's enrollment in a
+// course.
+func UpdateEnrollmentStatus(ctx context.Context, db DBTX, studentID, courseID int, status string) (*Enrollment, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	e := Enrollment{StudentID: studentID, CourseID: courseID, Status: status}
+	err := db.QueryRowContext(ctx, `UPDATE enrollments SET status = $1, updated_at = now()
+		WHERE student_id = $2 AND course_id = $3 RETURNING id, enrolled_at, updated_at`,
+		status, studentID, courseID).Scan(&e.ID, &e.EnrolledAt, &e.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("student %d is not enrolled in course %d", studentID, courseID)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &e, nil
+}
+
+func DeleteEnrollment(ctx context.Context, db DBTX, studentID, courseID int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM enrollments WHERE student_id = $1 AND course_id = $2", studentID, courseID)
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("student %d is not enrolled in course %d", studentID, courseID)
+	}
+	return nil
+}
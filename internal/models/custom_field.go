@@ -0,0 +1,416 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+// Custom field types.
+const (
+	FieldString  = "string"
+	FieldInteger = "integer"
+	FieldNumber  = "number"
+	FieldBoolean = "boolean"
+	FieldDate    = "date"
+	FieldEnum    = "enum"
+)
+
+// CustomField is an admin-defined student attribute. Values are stored in
+// Student.Attributes under the field's name.
+type CustomField struct {
+	ID       int        `json:"id"`
+	Name     string     `json:"name" validate:"required,max=64"`
+	Label    string     `json:"label" validate:"max=200"`
+	Type     string     `json:"type" validate:"required,oneof=string integer number boolean date enum"`
+	Required bool       `json:"required"`
+	Rules    FieldRules `json:"rules"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FieldRules constrain the values of a custom field. Min and Max bound the
+// value of integer and number fields and the length of string fields;
+// Pattern applies to string fields and Options lists the values of an enum.
+type FieldRules struct {
+	Min     *float64 `json:"min,omitempty"`
+	Max     *float64 `json:"max,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	Options []string `json:"options,omitempty"`
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Validate checks the definition itself, including that its rules make
+// sense for its type.
+func (f *CustomField) Validate() error {
+	if err := validationError("custom field is invalid", validator.Validate(f)); err != nil {
+		return err
+	}
+
+	var errs validator.Errors
+	fail := func(field, rule, message string) {
+		errs = append(errs, validator.FieldError{Field: field, Rule: rule, Message: message})
+	}
+	if !fieldNamePattern.MatchString(f.Name) {
+		fail("name", "fieldname", "name must start with a lowercase letter and contain only lowercase letters, digits and underscores")
+	}
+	ranged := f.Type == FieldString || f.Type == FieldInteger || f.Type == FieldNumber
+	if !ranged && (f.Rules.Min != nil || f.Rules.Max != nil) {
+		fail("rules.min", "type", fmt.Sprintf("min and max do not apply to %s fields", f.Type))
+	}
+	if f.Rules.Min != nil && f.Rules.Max != nil && *f.Rules.Min > *f.Rules.Max {
+		fail("rules.max", "gtefield", "max must not be less than min")
+	}
+	if f.Rules.Pattern != "" {
+		if f.Type != FieldString {
+			fail("rules.pattern", "type", "pattern only applies to string fields")
+		} else if _, err := regexp.Compile(f.Rules.Pattern); err != nil {
+			fail("rules.pattern", "regexp", "pattern must be a valid regular expression")
+		}
+	}
+	switch {
+	case f.Type == FieldEnum && len(f.Rules.Options) == 0:
+		fail("rules.options", "required", "enum fields must list their options")
+	case f.Type != FieldEnum && len(f.Rules.Options) > 0:
+		fail("rules.options", "type", "options only apply to enum fields")
+	}
+	if len(errs) > 0 {
+		return validationError("custom field is invalid", errs)
+	}
+	return nil
+}
+
+// DisplayName is the label of the field, or its name if it has none.
+func (f CustomField) DisplayName() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.Name
+}
+
+// ValidateAttributes checks the student's attributes against the custom
+// field definitions. Null values are dropped, so a field can be cleared by
+// setting it to null.
+func (s *Student) ValidateAttributes(fields []CustomField) error {
+	byName := make(map[string]CustomField, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	var errs validator.Errors
+	names := make([]string, 0, len(s.Attributes))
+	for name := range s.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := s.Attributes[name]
+		if value == nil {
+			delete(s.Attributes, name)
+			continue
+		}
+		f, ok := byName[name]
+		if !ok {
+			errs = append(errs, validator.FieldError{Field: "attributes." + name, Rule: "unknown",
+				Message: fmt.Sprintf("%s is not a custom field", name)})
+			continue
+		}
+		if fe := f.check(value); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	for _, f := range fields {
+		if _, ok := s.Attributes[f.Name]; f.Required && !ok {
+			errs = append(errs, validator.FieldError{Field: "attributes." + f.Name, Rule: "required",
+				Message: fmt.Sprintf("%s is required", f.DisplayName())})
+		}
+	}
+	if len(errs) > 0 {
+		return validationError("student attributes are invalid", errs)
+	}
+	if len(s.Attributes) == 0 {
+		s.Attributes = nil
+	}
+	return nil
+}
+
+// check validates one value of the field, as decoded from JSON.
+func (f CustomField) check(value interface{}) *validator.FieldError {
+	fail := func(rule, format string, args ...interface{}) *validator.FieldError {
+		return &validator.FieldError{Field: "attributes." + f.Name, Rule: rule,
+			Message: f.DisplayName() + " " + fmt.Sprintf(format, args...)}
+	}
+	inRange := func(n float64, unit string) *validator.FieldError {
+		if f.Rules.Min != nil && n < *f.Rules.Min {
+			return fail("min", "must be at least %g%s", *f.Rules.Min, unit)
+		}
+		if f.Rules.Max != nil && n > *f.Rules.Max {
+			return fail("max", "must be at most %g%s", *f.Rules.Max, unit)
+		}
+		return nil
+	}
+
+	switch f.Type {
+	case FieldString:
+		s, ok := value.(string)
+		if !ok {
+			return fail("type", "must be a string")
+		}
+		if fe := inRange(float64(utf8.RuneCountInString(s)), " characters long"); fe != nil {
+			return fe
+		}
+		if f.Rules.Pattern != "" && !regexp.MustCompile(f.Rules.Pattern).MatchString(s) {
+			return fail("pattern", "must match %s", f.Rules.Pattern)
+		}
+	case FieldInteger, FieldNumber:
+		n, ok := value.(float64)
+		if !ok {
+			return fail("type", "must be a number")
+		}
+		if f.Type == FieldInteger && n != math.Trunc(n) {
+			return fail("type", "must be a whole number")
+		}
+		return inRange(n, "")
+	case FieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return fail("type", "must be true or false")
+		}
+	case FieldDate:
+		s, ok := value.(string)
+		if _, err := time.Parse(DateLayout, s); !ok || err != nil {
+			return fail("date", "must be a date in YYYY-MM-DD format")
+		}
+	case FieldEnum:
+		s, _ := value.(string)
+		for _, option := range f.Rules.Options {
+			if s == option {
+				return nil
+			}
+		}
+		return fail("oneof", "must be one of %v", f.Rules.Options)
+	}
+	return nil
+}
+
+// ParseFilterValue converts a query string value into the form the field's
+// values take in Student.Attributes, so that it can be compared with them.
+func (f CustomField) ParseFilterValue(raw string) (interface{}, error) {
+	switch f.Type {
+	case FieldInteger:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, apperror.BadRequest("%s must be a whole number", f.Name)
+		}
+		return float64(n), nil
+	case FieldNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, apperror.BadRequest("%s must be a number", f.Name)
+		}
+		return n, nil
+	case FieldBoolean:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, apperror.BadRequest("%s must be a boolean", f.Name)
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// AttributeFilterPrefix marks query parameters that filter students by a
+// custom field, as in ?attr.house=red.
+const AttributeFilterPrefix = "attr."
+
+// ParseAttributeFilters reads the attribute filters from a query string.
+func ParseAttributeFilters(query url.Values, fields []CustomField) (map[string]interface{}, error) {
+	var attrs map[string]interface{}
+	for param, values := range query {
+		name, ok := strings.CutPrefix(param, AttributeFilterPrefix)
+		if !ok {
+			continue
+		}
+		var field *CustomField
+		for i := range fields {
+			if fields[i].Name == name {
+				field = &fields[i]
+			}
+		}
+		if field == nil {
+			return nil, apperror.BadRequest("%s is not a custom field", name)
+		}
+		value, err := field.ParseFilterValue(values[len(values)-1])
+		if err != nil {
+			return nil, err
+		}
+		if attrs == nil {
+			attrs = make(map[string]interface{})
+		}
+		attrs[name] = value
+	}
+	return attrs, nil
+}
+
+// MatchesAttributes reports whether the student has every one of the given
+// attribute values.
+func (s *Student) MatchesAttributes(attrs map[string]interface{}) bool {
+	for name, want := range attrs {
+		if got, ok := s.Attributes[name]; !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+const customFieldColumns = "id, name, label, type, required, rules, created_at, updated_at"
+
+func scanCustomField(row rowScanner) (CustomField, error) {
+	var f CustomField
+	var rules []byte
+	if err := row.Scan(&f.ID, &f.Name, &f.Label, &f.Type, &f.Required, &rules, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return f, err
+	}
+	return f, json.Unmarshal(rules, &f.Rules)
+}
+
+func (f *CustomField) Create(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rules, err := json.Marshal(f.Rules)
+	if err != nil {
+		return err
+	}
+	err = db.QueryRowContext(ctx, `INSERT INTO custom_fields (tenant_id, name, label, type, required, rules) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`,
+		tenant.ID(ctx), f.Name, f.Label, f.Type, f.Required, rules).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+	return translateError(err)
+}
+
+func GetCustomFields(ctx context.Context, db DBTX) ([]CustomField, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+customFieldColumns+" FROM custom_fields WHERE tenant_id = $1 ORDER BY id", tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	fields := []CustomField{}
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		fields = append(fields, f)
+	}
+	return fields, translateError(rows.Err())
+}
+
+func GetCustomField(ctx context.Context, db DBTX, name string) (*CustomField, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	f, err := scanCustomField(db.QueryRowContext(ctx, "SELECT "+customFieldColumns+" FROM custom_fields WHERE tenant_id = $1 AND name = $2",
+		tenant.ID(ctx), name))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("custom field %s not found", name)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &f, nil
+}
+
+// Update changes the label, required flag and rules of the named field. The
+// type cannot change, since existing values would no longer fit it.
+func (f *CustomField) Update(ctx context.Context, db DBTX, name string) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rules, err := json.Marshal(f.Rules)
+	if err != nil {
+		return err
+	}
+	var existingType string
+	err = db.QueryRowContext(ctx, `UPDATE custom_fields SET label = $1, required = $2, rules = $3, updated_at = now()
+		WHERE tenant_id = $4 AND name = $5 AND type = $6 RETURNING id, created_at, updated_at`,
+		f.Label, f.Required, rules, tenant.ID(ctx), name, f.Type).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+	if err == sql.ErrNoRows {
+		err = db.QueryRowContext(ctx, "SELECT type FROM custom_fields WHERE tenant_id = $1 AND name = $2",
+			tenant.ID(ctx), name).Scan(&existingType)
+		if err == sql.ErrNoRows {
+			return apperror.NotFound("custom field %s not found", name)
+		}
+		if err != nil {
+			return translateError(err)
+		}
+		return apperror.Conflict("custom field %s is of type %s and its type cannot be changed", name, existingType)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	f.Name = name
+	return nil
+}
+
+// DeleteCustomField removes a field definition and its value from every
+// student of the tenant, including soft-deleted ones.
+func DeleteCustomField(ctx context.Context, db DBTX, name string) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM custom_fields WHERE tenant_id = $1 AND name = $2", tenant.ID(ctx), name)
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("custom field %s not found", name)
+	}
+	d := database.DialectOf(db)
+	_, err = db.ExecContext(ctx, "UPDATE students SET attributes = "+d.JSONRemoveKey("attributes", "$1")+
+		" WHERE tenant_id = $2 AND "+d.JSONHasKey("attributes", "$1"), name, tenant.ID(ctx))
+	return translateError(err)
+}
+
+// attributesJSON encodes attributes for the JSONB column, which holds an
+// empty object rather than null when there are none.
+func attributesJSON(attrs map[string]interface{}) ([]byte, error) {
+	if len(attrs) == 0 {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(attrs)
+}
+
+// scanAttributes decodes the JSONB column, leaving attrs nil when empty.
+func scanAttributes(data []byte, attrs *map[string]interface{}) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if len(m) > 0 {
+		*attrs = m
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+)
+
+// DBTX is implemented by both *sql.DB and *database.Tx so that queries can
+// be grouped into a transaction by the caller. Driver lets queries pick
+// their SQL with database.DialectOf.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Driver() driver.Driver
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// constraintErrors describes each constraint in terms an API client
+// understands.
+var constraintErrors = map[string]func() error{
+	"students_email_key": func() error {
+		return apperror.Conflict("a student with this email already exists")
+	},
+	"students_email_lower_idx": func() error {
+		return apperror.Conflict("a student with this email already exists")
+	},
+	"courses_code_lower_idx": func() error {
+		return apperror.Conflict("a course with this code already exists")
+	},
+	"enrollments_student_id_course_id_key": func() error {
+		return apperror.Conflict("the student is already enrolled in this course")
+	},
+	"enrollments_student_id_fkey": func() error {
+		return apperror.NotFound("student not found")
+	},
+	"enrollments_course_id_fkey": func() error {
+		return apperror.NotFound("course not found")
+	},
+	"grades_course_id_fkey": func() error {
+		return apperror.NotFound("course not found")
+	},
+	"grades_student_id_course_id_term_key": func() error {
+		return apperror.Conflict("the student already has a grade for this course and term")
+	},
+	"attendance_course_id_fkey": func() error {
+		return apperror.NotFound("course not found")
+	},
+	"student_guardians_pkey": func() error {
+		return apperror.Conflict("the guardian is already linked to this student")
+	},
+	"student_guardians_primary_idx": func() error {
+		return apperror.Conflict("the student already has a primary guardian")
+	},
+	"student_guardians_guardian_id_fkey": func() error {
+		return apperror.NotFound("guardian not found")
+	},
+	"custom_fields_name_key": func() error {
+		return apperror.Conflict("a custom field with this name already exists")
+	},
+	"custom_fields_tenant_id_name_key": func() error {
+		return apperror.Conflict("a custom field with this name already exists")
+	},
+	"student_advisors_pkey": func() error {
+		return apperror.Conflict("the student is already assigned to this advisor")
+	},
+	"student_advisors_student_id_fkey": func() error {
+		return apperror.NotFound("student not found")
+	},
+	"api_keys_name_key": func() error {
+		return apperror.Conflict("an API key with this name already exists")
+	},
+	"api_keys_tenant_id_name_key": func() error {
+		return apperror.Conflict("an API key with this name already exists")
+	},
+}
+
+// translateError maps driver errors to apperror kinds.
+func translateError(err error) error {
+	if database.Interrupted(err) {
+		return apperror.Timeout(err, "the database did not answer in time")
+	}
+	constraintErr, ok := database.AsConstraintError(err)
+	if !ok {
+		return err
+	}
+	if describe, ok := constraintErrors[constraintErr.Constraint]; ok {
+		return describe()
+	}
+	switch constraintErr.Code {
+	case database.UniqueViolation:
+		return apperror.Conflict("the record conflicts with an existing one")
+	case database.ForeignKeyViolation:
+		return apperror.NotFound("a referenced record does not exist")
+	}
+	return err
+}
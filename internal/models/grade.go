@@ -0,0 +1,184 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+// Grade is a student's result in a course for one term. Course is filled in
+// when grades are listed.
+type Grade struct {
+	ID          int       `json:"id"`
+	StudentID   int       `json:"student_id"`
+	CourseID    int       `json:"course_id" validate:"required"`
+	Term        string    `json:"term" validate:"required,term"`
+	Score       float64   `json:"score" validate:"gte=0,lte=100"`
+	LetterGrade string    `json:"letter_grade" validate:"omitempty,oneof=A+ A A- B+ B B- C+ C C- D+ D D- F"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Course      *Course   `json:"course,omitempty"`
+}
+
+// letterCutoffs maps the lowest score for each letter grade, best first.
+var letterCutoffs = []struct {
+	min    float64
+	letter string
+}{
+	{97, "A+"}, {93, "A"}, {90, "A-"},
+	{87, "B+"}, {83, "B"}, {80, "B-"},
+	{77, "C+"}, {73, "C"}, {70, "C-"},
+	{67, "D+"}, {63, "D"}, {60, "D-"},
+	{0, "F"},
+}
+
+// gradePoints is the 4.0-scale value of each letter grade.
+var gradePoints = map[string]float64{
+	"A+": 4.0, "A": 4.0, "A-": 3.7,
+	"B+": 3.3, "B": 3.0, "B-": 2.7,
+	"C+": 2.3, "C": 2.0, "C-": 1.7,
+	"D+": 1.3, "D": 1.0, "D-": 0.7,
+	"F": 0,
+}
+
+// LetterForScore converts a 0-100 score to a letter grade.
+func LetterForScore(score float64) string {
+	for _, c := range letterCutoffs {
+		if score >= c.min {
+			return c.letter
+		}
+	}
+	return "F"
+}
+
+// Points returns the grade's value on a 4.0 scale.
+func (g *Grade) Points() float64 {
+	return gradePoints[g.LetterGrade]
+}
+
+// Validate checks the grade and derives the letter grade from the score
+// when none is given.
+func (g *Grade) Validate() error {
+	if err := validationError("grade is invalid", validator.Validate(g)); err != nil {
+		return err
+	}
+	if g.LetterGrade == "" {
+		g.LetterGrade = LetterForScore(g.Score)
+	}
+	return nil
+}
+
+const gradeColumns = "g.id, g.student_id, g.course_id, g.term, g.score, g.letter_grade, g.created_at, g.updated_at, " +
+	"c.id, c.code, c.title, c.description, c.credits, c.created_at, c.updated_at"
+
+func scanGrade(row rowScanner) (Grade, error) {
+	var g Grade
+	var c Course
+	err := row.Scan(&g.ID, &g.StudentID, &g.CourseID, &g.Term, &g.Score, &g.LetterGrade, &g.CreatedAt, &g.UpdatedAt,
+		&c.ID, &c.Code, &c.Title, &c.Description, &c.Credits, &c.CreatedAt, &c.UpdatedAt)
+	g.Course = &c
+	return g, err
+}
+
+// Create records a grade for a live student in a course of the same
+// tenant.
+func (g *Grade) Create(ctx context.Context, db DBTX) error {
+	if err := checkCourse(ctx, db, g.CourseID); err != nil {
+		return err
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO grades (student_id, course_id, term, score, letter_grade)
+		SELECT $1, $2, $3, $4, $5 WHERE EXISTS (SELECT 1 FROM students WHERE id = $1 AND tenant_id = $6 AND deleted_at IS NULL)
+		RETURNING id, created_at, updated_at`,
+		g.StudentID, g.CourseID, g.Term, g.Score, g.LetterGrade, tenant.ID(ctx)).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("student %d not found", g.StudentID)
+	}
+	return translateError(err)
+}
+
+// GetStudentGrades lists a student's grades with their courses.
+func GetStudentGrades(ctx context.Context, db DBTX, studentID int) ([]Grade, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `SELECT `+gradeColumns+` FROM grades g JOIN courses c ON c.id = g.course_id
+		WHERE g.student_id = $1 ORDER BY g.term, g.id`, studentID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	grades := []Grade{}
+	for rows.Next() {
+		g, err := scanGrade(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		grades = append(grades, g)
+	}
+	return grades, translateError(rows.Err())
+}
+
+func GetGrade(ctx context.Context, db DBTX, studentID, id int) (*Grade, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	g, err := scanGrade(db.QueryRowContext(ctx, `SELECT `+gradeColumns+` FROM grades g JOIN courses c ON c.id = g.course_id
+		WHERE g.student_id = $1 AND g.id = $2`, studentID, id))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("grade %d not found", id)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &g, nil
+}
+
+func (g *Grade) Update(ctx context.Context, db DBTX, studentID, id int) error {
+	if err := checkCourse(ctx, db, g.CourseID); err != nil {
+		return err
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `UPDATE grades SET course_id = $1, term = $2, score = $3, letter_grade = $4, updated_at = now()
+		WHERE student_id = $5 AND id = $6 RETURNING created_at, updated_at`,
+		g.CourseID, g.Term, g.Score, g.LetterGrade, studentID, id).Scan(&g.CreatedAt, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("grade %d not found", id)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	g.ID = id
+	g.StudentID = studentID
+	return nil
+}
+
+func DeleteGrade(ctx context.Context, db DBTX, studentID, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM grades WHERE student_id = $1 AND id = $2", studentID, id)
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("grade %d not found", id)
+	}
+	return nil
+}
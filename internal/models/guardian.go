@@ -0,0 +1,304 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+// Ways a guardian prefers to be contacted.
+const (
+	ContactEmail = "email"
+	ContactPhone = "phone"
+	ContactSMS   = "sms"
+)
+
+// DefaultLanguage is used for guardians who have not chosen a language.
+const DefaultLanguage = "en"
+
+// Guardian is a parent or other contact who can be linked to any number of
+// students.
+type Guardian struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name" validate:"required,personname"`
+	Email string `json:"email" validate:"required_if=PreferredContact email,omitempty,email"`
+	// Phone is in E.164 form, such as +15551234567.
+	Phone             string `json:"phone" validate:"required_if=PreferredContact phone,required_if=PreferredContact sms,omitempty,e164"`
+	PreferredContact  string `json:"preferred_contact" validate:"oneof=email phone sms"`
+	PreferredLanguage string `json:"preferred_language" validate:"bcp47_language_tag"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StudentGuardian links a student to a guardian. Guardian is filled in when
+// a student's guardians are listed and Student when a guardian's students
+// are.
+type StudentGuardian struct {
+	StudentID    int       `json:"student_id"`
+	GuardianID   int       `json:"guardian_id" validate:"required"`
+	Relationship string    `json:"relationship" validate:"required,max=50"`
+	Primary      bool      `json:"primary"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Guardian     *Guardian `json:"guardian,omitempty"`
+	Student      *Student  `json:"student,omitempty"`
+}
+
+// Validate checks the guardian after filling in its defaults: contact by
+// email if it has an address and by phone otherwise, in DefaultLanguage.
+func (g *Guardian) Validate() error {
+	if g.PreferredContact == "" {
+		g.PreferredContact = ContactEmail
+		if g.Email == "" && g.Phone != "" {
+			g.PreferredContact = ContactPhone
+		}
+	}
+	if g.PreferredLanguage == "" {
+		g.PreferredLanguage = DefaultLanguage
+	}
+	return validationError("guardian is invalid", validator.Validate(g))
+}
+
+func (l *StudentGuardian) Validate() error {
+	return validationError("guardian link is invalid", validator.Validate(l))
+}
+
+const guardianColumns = "id, name, email, phone, preferred_contact, preferred_language, created_at, updated_at"
+
+func scanGuardian(row rowScanner) (Guardian, error) {
+	var g Guardian
+	err := row.Scan(&g.ID, &g.Name, &g.Email, &g.Phone, &g.PreferredContact, &g.PreferredLanguage, &g.CreatedAt, &g.UpdatedAt)
+	return g, err
+}
+
+func (g *Guardian) Create(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO guardians (tenant_id, name, email, phone, preferred_contact, preferred_language)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`,
+		tenant.ID(ctx), g.Name, g.Email, g.Phone, g.PreferredContact, g.PreferredLanguage).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt)
+	return translateError(err)
+}
+
+func GetAllGuardians(ctx context.Context, db DBTX) ([]Guardian, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+guardianColumns+" FROM guardians WHERE tenant_id = $1 ORDER BY id", tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	guardians := []Guardian{}
+	for rows.Next() {
+		g, err := scanGuardian(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		guardians = append(guardians, g)
+	}
+	return guardians, translateError(rows.Err())
+}
+
+func GetGuardian(ctx context.Context, db DBTX, id int) (*Guardian, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	g, err := scanGuardian(db.QueryRowContext(ctx, "SELECT "+guardianColumns+" FROM guardians WHERE id = $1 AND tenant_id = $2", id, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("guardian %d not found", id)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &g, nil
+}
+
+func (g *Guardian) Update(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `UPDATE guardians SET name = $1, email = $2, phone = $3, preferred_contact = $4,
+			preferred_language = $5, updated_at = now()
+		WHERE id = $6 AND tenant_id = $7 RETURNING created_at, updated_at`,
+		g.Name, g.Email, g.Phone, g.PreferredContact, g.PreferredLanguage, id, tenant.ID(ctx)).Scan(&g.CreatedAt, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("guardian %d not found", id)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	g.ID = id
+	return nil
+}
+
+// DeleteGuardian removes a guardian and, through the foreign key, its links
+// to students.
+func DeleteGuardian(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM guardians WHERE id = $1 AND tenant_id = $2", id, tenant.ID(ctx))
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("guardian %d not found", id)
+	}
+	return nil
+}
+
+// Create links a live student to a guardian of the same tenant.
+func (l *StudentGuardian) Create(ctx context.Context, db DBTX) error {
+	if err := checkGuardian(ctx, db, l.GuardianID); err != nil {
+		return err
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO student_guardians (student_id, guardian_id, relationship, is_primary)
+		SELECT $1, $2, $3, $4 WHERE EXISTS (SELECT 1 FROM students WHERE id = $1 AND tenant_id = $5 AND deleted_at IS NULL)
+		RETURNING created_at, updated_at`,
+		l.StudentID, l.GuardianID, l.Relationship, l.Primary, tenant.ID(ctx)).Scan(&l.CreatedAt, &l.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("student %d not found", l.StudentID)
+	}
+	return translateError(err)
+}
+
+const studentGuardianColumns = "l.student_id, l.guardian_id, l.relationship, l.is_primary, l.created_at, l.updated_at"
+
+// GetStudentGuardians lists a student's guardians, primary first.
+func GetStudentGuardians(ctx context.Context, db DBTX, studentID int) ([]StudentGuardian, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `SELECT `+studentGuardianColumns+`,
+			g.id, g.name, g.email, g.phone, g.preferred_contact, g.preferred_language, g.created_at, g.updated_at
+		FROM student_guardians l JOIN guardians g ON g.id = l.guardian_id
+		WHERE l.student_id = $1 ORDER BY l.is_primary DESC, l.created_at, g.id`, studentID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	links := []StudentGuardian{}
+	for rows.Next() {
+		l, err := scanStudentGuardian(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		links = append(links, l)
+	}
+	return links, translateError(rows.Err())
+}
+
+// GetStudentGuardian returns the link between a student and a guardian,
+// with the guardian.
+func GetStudentGuardian(ctx context.Context, db DBTX, studentID, guardianID int) (*StudentGuardian, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	l, err := scanStudentGuardian(db.QueryRowContext(ctx, `SELECT `+studentGuardianColumns+`,
+			g.id, g.name, g.email, g.phone, g.preferred_contact, g.preferred_language, g.created_at, g.updated_at
+		FROM student_guardians l JOIN guardians g ON g.id = l.guardian_id
+		WHERE l.student_id = $1 AND l.guardian_id = $2`, studentID, guardianID))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("guardian %d is not linked to student %d", guardianID, studentID)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &l, nil
+}
+
+func scanStudentGuardian(row rowScanner) (StudentGuardian, error) {
+	var l StudentGuardian
+	var g Guardian
+	err := row.Scan(&l.StudentID, &l.GuardianID, &l.Relationship, &l.Primary, &l.CreatedAt, &l.UpdatedAt,
+		&g.ID, &g.Name, &g.Email, &g.Phone, &g.PreferredContact, &g.PreferredLanguage, &g.CreatedAt, &g.UpdatedAt)
+	l.Guardian = &g
+	return l, err
+}
+
+// GetGuardianStudents lists the live students a guardian is linked to,
+// among those assigned to advisor if it is set.
+func GetGuardianStudents(ctx context.Context, db DBTX, guardianID int, advisor string) ([]StudentGuardian, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `SELECT `+studentGuardianColumns+`,
+			s.id, s.name, s.age, s.email, s.attributes, s.created_at, s.updated_at
+		FROM student_guardians l JOIN students s ON s.id = l.student_id
+		WHERE l.guardian_id = $1 AND s.tenant_id = $3 AND s.deleted_at IS NULL
+			AND ($2 = '' OR s.id IN (SELECT student_id FROM student_advisors WHERE advisor = $2))
+		ORDER BY s.id`, guardianID, advisor, tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	links := []StudentGuardian{}
+	for rows.Next() {
+		var l StudentGuardian
+		var s Student
+		var attributes []byte
+		err := rows.Scan(&l.StudentID, &l.GuardianID, &l.Relationship, &l.Primary, &l.CreatedAt, &l.UpdatedAt,
+			&s.ID, &s.Name, &s.Age, &s.Email, &attributes, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		if err := scanAttributes(attributes, &s.Attributes); err != nil {
+			return nil, translateError(err)
+		}
+		l.Student = &s
+		links = append(links, l)
+	}
+	return links, translateError(rows.Err())
+}
+
+// Update changes the relationship and primary flag of the link between a
+// student and a guardian.
+func (l *StudentGuardian) Update(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `UPDATE student_guardians SET relationship = $1, is_primary = $2, updated_at = now()
+		WHERE student_id = $3 AND guardian_id = $4 RETURNING created_at, updated_at`,
+		l.Relationship, l.Primary, l.StudentID, l.GuardianID).Scan(&l.CreatedAt, &l.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("guardian %d is not linked to student %d", l.GuardianID, l.StudentID)
+	}
+	return translateError(err)
+}
+
+func DeleteStudentGuardian(ctx context.Context, db DBTX, studentID, guardianID int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM student_guardians WHERE student_id = $1 AND guardian_id = $2", studentID, guardianID)
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("guardian %d is not linked to student %d", guardianID, studentID)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
+)
+
+// Note is a free-form advisor note about a student. Author is set from the
+// request, not the body.
+type Note struct {
+	ID        int       `json:"id"`
+	StudentID int       `json:"student_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body" validate:"required,max=10000"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (n *Note) Validate() error {
+	return validationError("note is invalid", validator.Validate(n))
+}
+
+const noteColumns = "id, student_id, author, body, created_at, updated_at"
+
+func scanNote(row rowScanner) (Note, error) {
+	var n Note
+	err := row.Scan(&n.ID, &n.StudentID, &n.Author, &n.Body, &n.CreatedAt, &n.UpdatedAt)
+	return n, err
+}
+
+// Create adds a note to a live student.
+func (n *Note) Create(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO notes (student_id, author, body)
+		SELECT $1, $2, $3 WHERE EXISTS (SELECT 1 FROM students WHERE id = $1 AND deleted_at IS NULL)
+		RETURNING id, created_at, updated_at`,
+		n.StudentID, n.Author, n.Body).Scan(&n.ID, &n.CreatedAt, &n.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("student %d not found", n.StudentID)
+	}
+	return translateError(err)
+}
+
+// GetStudentNotes lists a student's notes, oldest first.
+func GetStudentNotes(ctx context.Context, db DBTX, studentID int) ([]Note, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+noteColumns+" FROM notes WHERE student_id = $1 ORDER BY created_at, id", studentID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	notes := []Note{}
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, translateError(rows.Err())
+}
+
+func GetNote(ctx context.Context, db DBTX, studentID, id int) (*Note, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := scanNote(db.QueryRowContext(ctx, "SELECT "+noteColumns+" FROM notes WHERE student_id = $1 AND id = $2", studentID, id))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("note %d not found", id)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &n, nil
+}
+
+// Update changes the note's body, keeping its original author.
+func (n *Note) Update(ctx context.Context, db DBTX, studentID, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `UPDATE notes SET body = $1, updated_at = now()
+		WHERE student_id = $2 AND id = $3 RETURNING author, created_at, updated_at`,
+		n.Body, studentID, id).Scan(&n.Author, &n.CreatedAt, &n.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("note %d not found", id)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	n.ID = id
+	n.StudentID = studentID
+	return nil
+}
+
+func DeleteNote(ctx context.Context, db DBTX, studentID, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM notes WHERE student_id = $1 AND id = $2", studentID, id)
+	if err != nil {
+		return translateError(err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if count == 0 {
+		return apperror.NotFound("note %d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Trend directions reported in Performance.
+const (
+	TrendImproving    = "improving"
+	TrendDeclining    = "declining"
+	TrendStable       = "stable"
+	TrendInsufficient = "insufficient_data"
+)
+
+// trendThreshold is the GPA change per term below which a trend is stable.
+const trendThreshold = 0.1
+
+// TermPerformance is a student's credit-weighted results for one term.
+type TermPerformance struct {
+	Term         string  `json:"term"`
+	GPA          float64 `json:"gpa"`
+	AverageScore float64 `json:"average_score"`
+	Credits      int     `json:"credits"`
+	Courses      int     `json:"courses"`
+}
+
+// Performance summarizes a student's academic standing across terms.
+type Performance struct {
+	Terms         []TermPerformance `json:"terms"`
+	CumulativeGPA float64           `json:"cumulative_gpa"`
+	TotalCredits  int               `json:"total_credits"`
+	Trend         string            `json:"trend"`
+	// TrendSlope is the average change in term GPA from one term to the next.
+	TrendSlope float64 `json:"trend_slope"`
+}
+
+var seasonOrder = map[string]int{"winter": 1, "spring": 2, "summer": 3, "fall": 4}
+
+// termSortKey orders terms such as 2024-spring before 2024-fall. Numbered
+// terms (2024-1) sort by number within the year.
+func termSortKey(term string) (int, int) {
+	parts := strings.SplitN(term, "-", 2)
+	year, _ := strconv.Atoi(parts[0])
+	if len(parts) < 2 {
+		return year, 0
+	}
+	if n, ok := seasonOrder[parts[1]]; ok {
+		return year, n
+	}
+	n, _ := strconv.Atoi(parts[1])
+	return year, n
+}
+
+// gradeWeight is the number of credits a grade counts for. Courses without
+// credits count as one so that they still contribute.
+func gradeWeight(g Grade) float64 {
+	if g.Course != nil && g.Course.Credits > 0 {
+		return float64(g.Course.Credits)
+	}
+	return 1
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// CalculatePerformance computes per-term and cumulative GPA and the GPA trend
+// from a student's grades. Grades must have their Course filled in for credit
+// weighting.
+func CalculatePerformance(grades []Grade) Performance {
+	type totals struct {
+		points, scores, weight float64
+		credits, courses       int
+	}
+	byTerm := make(map[string]*totals)
+	var all totals
+	for _, g := range grades {
+		t := byTerm[g.Term]
+		if t == nil {
+			t = &totals{}
+			byTerm[g.Term] = t
+		}
+		w := gradeWeight(g)
+		for _, acc := range []*totals{t, &all} {
+			acc.points += g.Points() * w
+			acc.scores += g.Score * w
+			acc.weight += w
+			acc.courses++
+			if g.Course != nil {
+				acc.credits += g.Course.Credits
+			}
+		}
+	}
+
+	p := Performance{Terms: []TermPerformance{}, TotalCredits: all.credits, Trend: TrendInsufficient}
+	for term, t := range byTerm {
+		p.Terms = append(p.Terms, TermPerformance{
+			Term:         term,
+			GPA:          round2(t.points / t.weight),
+			AverageScore: round2(t.scores / t.weight),
+			Credits:      t.credits,
+			Courses:      t.courses,
+		})
+	}
+	sort.Slice(p.Terms, func(i, j int) bool {
+		yi, ni := termSortKey(p.Terms[i].Term)
+		yj, nj := termSortKey(p.Terms[j].Term)
+		return yi < yj || (yi == yj && ni < nj)
+	})
+	if all.weight > 0 {
+		p.CumulativeGPA = round2(all.points / all.weight)
+	}
+
+	if len(p.Terms) >= 2 {
+		p.TrendSlope = round2(gpaSlope(p.Terms))
+		switch {
+		case p.TrendSlope > trendThreshold:
+			p.Trend = TrendImproving
+		case p.TrendSlope < -trendThreshold:
+			p.Trend = TrendDeclining
+		default:
+			p.Trend = TrendStable
+		}
+	}
+	return p
+}
+
+// gpaSlope fits a least-squares line through term GPAs indexed by term order.
+func gpaSlope(terms []TermPerformance) float64 {
+	n := float64(len(terms))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, t := range terms {
+		x := float64(i)
+		sumX += x
+		sumY += t.GPA
+		sumXY += x * t.GPA
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
@@ -0,0 +1,313 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/search"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// maxNoteHighlights is how many matching notes a result quotes.
+	maxNoteHighlights = 3
+)
+
+// StudentSummary is the latest summary generated for a student, kept so
+// that students can be searched by it.
+type StudentSummary struct {
+	StudentID   int       `json:"student_id"`
+	Summary     string    `json:"summary"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Save stores the summary in place of the student's previous one.
+func (ss *StudentSummary) Save(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, `INSERT INTO student_summaries (student_id, summary) VALUES ($1, $2)
+		ON CONFLICT (student_id) DO UPDATE SET summary = EXCLUDED.summary, generated_at = now()
+		RETURNING generated_at`,
+		ss.StudentID, ss.Summary).Scan(&ss.GeneratedAt)
+	return translateError(err)
+}
+
+// SearchResult is a student found by a full-text search.
+type SearchResult struct {
+	Student Student `json:"student"`
+	// Rank orders the results; it is only comparable within one search.
+	Rank       float64          `json:"rank"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// SearchHighlights quote the matching parts of a student with the query's
+// words wrapped in <mark> tags. Everything else is HTML-escaped.
+type SearchHighlights struct {
+	Name    string   `json:"name"`
+	Email   string   `json:"email"`
+	Summary string   `json:"summary,omitempty"`
+	Notes   []string `json:"notes,omitempty"`
+}
+
+// ParseSearchQuery reads the q and limit parameters of GET /students/search.
+func ParseSearchQuery(values url.Values) (string, int, error) {
+	q := values.Get("q")
+	if q == "" {
+		return "", 0, apperror.BadRequest("q is required")
+	}
+	limit := defaultSearchLimit
+	if v := values.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxSearchLimit {
+			return "", 0, apperror.BadRequest("limit must be between 1 and %d", maxSearchLimit)
+		}
+	}
+	return q, limit, nil
+}
+
+// IndexStudent adds a student's name and email to an in-process index.
+func IndexStudent(ix *search.Index, s Student) {
+	ix.Put(search.Key{Kind: search.KindStudent, ID: s.ID}, s.ID,
+		search.Field{Text: s.Name, Weight: search.WeightName},
+		search.Field{Text: s.Email, Weight: search.WeightEmail})
+}
+
+// IndexNote adds a note to an in-process index.
+func IndexNote(ix *search.Index, n Note) {
+	ix.Put(search.Key{Kind: search.KindNote, ID: n.ID}, n.StudentID,
+		search.Field{Text: n.Body, Weight: search.WeightNote})
+}
+
+// IndexSummary adds a student's summary to an in-process index.
+func IndexSummary(ix *search.Index, ss StudentSummary) {
+	ix.Put(search.Key{Kind: search.KindSummary, ID: ss.StudentID}, ss.StudentID,
+		search.Field{Text: ss.Summary, Weight: search.WeightSummary})
+}
+
+// NewSearchResult describes a hit of an in-process index. text returns the
+// text of one of the student's documents.
+func NewSearchResult(q search.Query, hit search.Hit, student Student, text func(search.Key) string) SearchResult {
+	result := SearchResult{
+		Student: student,
+		Rank:    hit.Rank,
+		Highlights: SearchHighlights{
+			Name:  q.Highlight(student.Name),
+			Email: q.Highlight(student.Email),
+		},
+	}
+	for _, key := range hit.Matches {
+		switch key.Kind {
+		case search.KindSummary:
+			result.Highlights.Summary = q.Fragment(text(key))
+		case search.KindNote:
+			if len(result.Highlights.Notes) < maxNoteHighlights {
+				result.Highlights.Notes = append(result.Highlights.Notes, q.Fragment(text(key)))
+			}
+		}
+	}
+	return result
+}
+
+// SearchStudents finds the live students whose name and email, latest
+// summary or one of whose notes hold every word of q, best first, among
+// those assigned to advisor if it is set. Postgres uses its full-text
+// indexes; SQLite reads the students into an in-process index for each
+// search.
+func SearchStudents(ctx context.Context, db *sql.DB, q string, limit int, advisor string) ([]SearchResult, error) {
+	if database.DialectOf(db) == database.SQLite {
+		return searchInProcess(ctx, db, search.ParseQuery(q), limit, advisor)
+	}
+
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, searchQuery, q, limit, advisor, tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var s Student
+		var attributes, notes []byte
+		var deletedAt sql.NullTime
+		var summary sql.NullString
+		var result SearchResult
+		err := rows.Scan(&s.ID, &s.Name, &s.Age, &s.Email, &attributes, &s.CreatedAt, &s.UpdatedAt, &deletedAt,
+			&result.Rank, &result.Highlights.Name, &result.Highlights.Email, &summary, &notes)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		if err := scanAttributes(attributes, &s.Attributes); err != nil {
+			return nil, err
+		}
+		if notes != nil {
+			if err := json.Unmarshal(notes, &result.Highlights.Notes); err != nil {
+				return nil, err
+			}
+		}
+		result.Student = s
+		result.Highlights.Summary = summary.String
+		results = append(results, result)
+	}
+	return results, translateError(rows.Err())
+}
+
+// escapedHTML is the SQL for column with the characters search.EscapeHTML
+// escapes replaced, so that ts_headline's output is as safe as the
+// in-process highlights.
+func escapedHTML(column string) string {
+	return fmt.Sprintf("replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')", column)
+}
+
+var (
+	wholeHeadline = fmt.Sprintf("'StartSel=%s, StopSel=%s, HighlightAll=true'", search.StartSel, search.StopSel)
+	fragHeadline  = fmt.Sprintf("'StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15'", search.StartSel, search.StopSel)
+)
+
+// tsquery is the parsed query. It is repeated rather than computed once in
+// a CTE so that the planner sees a constant it can match the GIN indexes
+// with.
+const tsquery = "plainto_tsquery('english', $1)"
+
+// searchQuery ranks the students by the sum of the ranks of their matching
+// documents and highlights the page it returns.
+var searchQuery = `WITH matches AS (
+		SELECT id AS student_id, ts_rank(search, ` + tsquery + `) AS rank FROM students WHERE search @@ ` + tsquery + `
+		UNION ALL
+		SELECT student_id, ts_rank(search, ` + tsquery + `) FROM student_summaries WHERE search @@ ` + tsquery + `
+		UNION ALL
+		SELECT student_id, ts_rank(search, ` + tsquery + `) FROM notes WHERE search @@ ` + tsquery + `
+	),
+	ranked AS (
+		SELECT m.student_id, sum(m.rank) AS rank
+		FROM matches m JOIN students s ON s.id = m.student_id AND s.deleted_at IS NULL
+		WHERE s.tenant_id = $4 AND ($3 = '' OR s.id IN (SELECT student_id FROM student_advisors WHERE advisor = $3))
+		GROUP BY m.student_id
+		ORDER BY rank DESC, m.student_id
+		LIMIT $2
+	)
+	SELECT ` + studentColumns + `, ranked.rank,
+		ts_headline('english', ` + escapedHTML("name") + `, ` + tsquery + `, ` + wholeHeadline + `),
+		ts_headline('english', ` + escapedHTML("email") + `, ` + tsquery + `, ` + wholeHeadline + `),
+		(SELECT ts_headline('english', ` + escapedHTML("ss.summary") + `, ` + tsquery + `, ` + fragHeadline + `)
+			FROM student_summaries ss WHERE ss.student_id = students.id AND ss.search @@ ` + tsquery + `),
+		(SELECT json_agg(h.headline) FROM (
+			SELECT ts_headline('english', ` + escapedHTML("n.body") + `, ` + tsquery + `, ` + fragHeadline + `) AS headline
+			FROM notes n WHERE n.student_id = students.id AND n.search @@ ` + tsquery + `
+			ORDER BY ts_rank(n.search, ` + tsquery + `) DESC, n.id
+			LIMIT ` + strconv.Itoa(maxNoteHighlights) + `) h)
+	FROM ranked JOIN students ON students.id = ranked.student_id
+	ORDER BY ranked.rank DESC, students.id`
+
+// searchInProcess searches by reading every live student, or every one
+// assigned to advisor if it is set, and their notes and summaries into an
+// in-process index.
+func searchInProcess(ctx context.Context, db *sql.DB, q search.Query, limit int, advisor string) ([]SearchResult, error) {
+	results := []SearchResult{}
+	if q.Empty() {
+		return results, nil
+	}
+
+	list, err := GetAllStudents(ctx, db, StudentFilter{Advisor: advisor})
+	if err != nil {
+		return nil, err
+	}
+	notes, err := getAllNotes(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := getAllSummaries(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	ix := search.NewIndex()
+	students := make(map[int]Student, len(list))
+	for _, s := range list {
+		students[s.ID] = s
+		IndexStudent(ix, s)
+	}
+	for _, n := range notes {
+		if _, ok := students[n.StudentID]; ok {
+			IndexNote(ix, n)
+		}
+	}
+	for _, ss := range summaries {
+		if _, ok := students[ss.StudentID]; ok {
+			IndexSummary(ix, ss)
+		}
+	}
+
+	text := func(key search.Key) string {
+		if key.Kind == search.KindNote {
+			return notes[key.ID].Body
+		}
+		return summaries[key.ID].Summary
+	}
+	for _, hit := range ix.Search(q) {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, NewSearchResult(q, hit, students[hit.Student], text))
+	}
+	return results, nil
+}
+
+// getAllNotes returns every note of the tenant's students by ID.
+func getAllNotes(ctx context.Context, db DBTX) (map[int]Note, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT "+noteColumns+" FROM notes WHERE student_id IN (SELECT id FROM students WHERE tenant_id = $1)",
+		tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	notes := make(map[int]Note)
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		notes[n.ID] = n
+	}
+	return notes, translateError(rows.Err())
+}
+
+// getAllSummaries returns the summary of each of the tenant's students by
+// student ID.
+func getAllSummaries(ctx context.Context, db DBTX) (map[int]StudentSummary, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `SELECT student_id, summary, generated_at FROM student_summaries
+		WHERE student_id IN (SELECT id FROM students WHERE tenant_id = $1)`, tenant.ID(ctx))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	summaries := make(map[int]StudentSummary)
+	for rows.Next() {
+		var ss StudentSummary
+		if err := rows.Scan(&ss.StudentID, &ss.Summary, &ss.GeneratedAt); err != nil {
+			return nil, translateError(err)
+		}
+		summaries[ss.StudentID] = ss
+	}
+	return summaries, translateError(rows.Err())
+}
@@ -1,84 +1,386 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
 	"github.com/AashishKumar-3002/FealtyX/pkg/validator"
 )
 
 type Student struct {
 	ID    int    `json:"id"`
-	Name  string `json:"name" validate:"required"`
-	Age   int    `json:"age" validate:"required,gte=1,lte=150"`
-	Email string `json:"email" validate:"required,email"`
+	Name  string `json:"name" validate:"required,personname"`
+	Age   int    `json:"age" validate:"required,agerange"`
+	Email string `json:"email" validate:"required,email,notdisposable"`
+	// Attributes holds values of the admin-defined custom fields, checked
+	// by ValidateAttributes.
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
+// Validate checks the student against the shared rule set in pkg/validator.
+// Both storage backends call it so they accept exactly the same input.
 func (s *Student) Validate() error {
-	return validator.Validate(s)
+	return validationError("student is invalid", validator.Validate(s))
+}
+
+// validationError converts validator field errors into an apperror.
+func validationError(message string, err error) error {
+	var verrs validator.Errors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	fields := make([]apperror.FieldError, len(verrs))
+	for i, fe := range verrs {
+		fields[i] = apperror.FieldError{Field: fe.Field, Rule: fe.Rule, Message: fe.Message}
+	}
+	return apperror.Validation(message, fields)
+}
+
+// IsDeleted reports whether the student has been soft deleted.
+func (s *Student) IsDeleted() bool {
+	return s.DeletedAt != nil
+}
+
+// StudentFilter narrows a student listing.
+type StudentFilter struct {
+	IncludeDeleted bool
+	// Attributes must all match, with values as returned by
+	// CustomField.ParseFilterValue.
+	Attributes map[string]interface{}
+	// MinAge and MaxAge bound the age, inclusive, unless nil.
+	MinAge, MaxAge *int
+	// NamePrefix and EmailPrefix, in lower case, match the start of the
+	// name and email ignoring case.
+	NamePrefix, EmailPrefix string
+	// Advisor, if set, keeps only the students assigned to that advisor.
+	// Matches does not check it, since it depends on the assignments.
+	Advisor string
+}
+
+// Matches reports whether the student belongs in a listing with the filter.
+func (f StudentFilter) Matches(s Student) bool {
+	return (f.IncludeDeleted || !s.IsDeleted()) && f.inRanges(s) && s.MatchesAttributes(f.Attributes)
+}
+
+const studentColumns = "id, name, age, email, attributes, created_at, updated_at, deleted_at"
+
+func scanStudent(row rowScanner) (Student, error) {
+	var s Student
+	var attributes []byte
+	var deletedAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.Name, &s.Age, &s.Email, &attributes, &s.CreatedAt, &s.UpdatedAt, &deletedAt); err != nil {
+		return s, err
+	}
+	if deletedAt.Valid {
+		s.DeletedAt = &deletedAt.Time
+	}
+	return s, scanAttributes(attributes, &s.Attributes)
+}
+
+func (s *Student) Create(ctx context.Context, db DBTX) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	attributes, err := attributesJSON(s.Attributes)
+	if err != nil {
+		return err
+	}
+	err = db.QueryRowContext(ctx, `INSERT INTO students (tenant_id, name, age, email, attributes) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`,
+		tenant.ID(ctx), s.Name, s.Age, s.Email, attributes).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	return translateError(err)
 }
 
-func (s *Student) Create(db *sql.DB) error {
-	return db.QueryRow("INSERT INTO students (name, age, email) VALUES ($1, $2, $3) RETURNING id",
-		s.Name, s.Age, s.Email).Scan(&s.ID)
+// GetAllStudents lists the students that match the filter, in ID order.
+func GetAllStudents(ctx context.Context, db DBTX, filter StudentFilter) ([]Student, error) {
+	students, _, err := ListStudents(ctx, db, filter, StudentPage{})
+	return students, err
 }
 
-func GetAllStudents(db *sql.DB) ([]Student, error) {
-	rows, err := db.Query("SELECT id, name, age, email FROM students")
+// ListStudents returns a page of the students that match the filter, and
+// the cursor for the next page, which is nil on the last one.
+func ListStudents(ctx context.Context, db DBTX, filter StudentFilter, page StudentPage) ([]Student, *StudentCursor, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query, args, err := studentQuery(database.DialectOf(db), tenant.ID(ctx), filter, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, translateError(err)
 	}
 	defer rows.Close()
 
-	var students []Student
+	students := []Student{}
 	for rows.Next() {
-		var s Student
-		if err := rows.Scan(&s.ID, &s.Name, &s.Age, &s.Email); err != nil {
-			return nil, err
+		s, err := scanStudent(rows)
+		if err != nil {
+			return nil, nil, translateError(err)
 		}
 		students = append(students, s)
 	}
-	return students, nil
+	if err := rows.Err(); err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	if page.Limit > 0 && len(students) > page.Limit {
+		students = students[:page.Limit]
+		return students, page.NextCursor(students[page.Limit-1]), nil
+	}
+	return students, nil, nil
 }
 
-func GetStudent(db *sql.DB, id int) (*Student, error) {
-	var s Student
-	err := db.QueryRow("SELECT id, name, age, email FROM students WHERE id = $1", id).
-		Scan(&s.ID, &s.Name, &s.Age, &s.Email)
+// sortExpression is the SQL for a sort field, or for ID if there is no
+// such field. Names and emails are compared byte by byte, as the in-memory
+// backend does, rather than by the database's collation; the indexes are
+// built the same way.
+func sortExpression(d database.Dialect, sort string) string {
+	switch sort {
+	case SortByName:
+		return "lower(name) " + d.ByteOrder()
+	case SortByAge:
+		return "age"
+	case SortByEmail:
+		return "lower(email) " + d.ByteOrder()
+	}
+	return "id"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// studentQuery builds the query for a page of the tenant's students
+// matching the filter.
+func studentQuery(d database.Dialect, tenantID string, filter StudentFilter, page StudentPage) (string, []interface{}, error) {
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if len(filter.Attributes) > 0 {
+		attributes, err := json.Marshal(filter.Attributes)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, attributes)
+		conditions = append(conditions, d.JSONContains("attributes", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.MinAge != nil {
+		args = append(args, *filter.MinAge)
+		conditions = append(conditions, fmt.Sprintf("age >= $%d", len(args)))
+	}
+	if filter.MaxAge != nil {
+		args = append(args, *filter.MaxAge)
+		conditions = append(conditions, fmt.Sprintf("age <= $%d", len(args)))
+	}
+	if filter.NamePrefix != "" {
+		args = append(args, likeEscaper.Replace(filter.NamePrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf(`%s LIKE $%d ESCAPE '\'`, sortExpression(d, SortByName), len(args)))
+	}
+	if filter.EmailPrefix != "" {
+		args = append(args, likeEscaper.Replace(filter.EmailPrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf(`%s LIKE $%d ESCAPE '\'`, sortExpression(d, SortByEmail), len(args)))
+	}
+	if filter.Advisor != "" {
+		args = append(args, filter.Advisor)
+		conditions = append(conditions, fmt.Sprintf("id IN (SELECT student_id FROM student_advisors WHERE advisor = $%d)", len(args)))
+	}
+
+	sortExpr := sortExpression(d, page.Sort)
+	byID := sortExpr == sortExpression(d, SortByID)
+	op, dir := ">", ""
+	if page.Desc {
+		op, dir = "<", " DESC"
+	}
+	if after := page.After; after != nil {
+		switch page.Sort {
+		case SortByName, SortByEmail:
+			args = append(args, after.Key.Str, after.Key.ID)
+		case SortByAge:
+			args = append(args, after.Key.Num, after.Key.ID)
+		default:
+			args = append(args, after.Key.ID)
+		}
+		if byID {
+			conditions = append(conditions, fmt.Sprintf("id %s $%d", op, len(args)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortExpr, op, len(args)-1, len(args)))
+		}
+	}
+
+	query := "SELECT " + studentColumns + " FROM students WHERE " + strings.Join(conditions, " AND ")
+	query += " ORDER BY " + sortExpr + dir
+	if !byID {
+		query += ", id" + dir
+	}
+	if page.Limit > 0 {
+		// One more than the page holds tells whether there is another.
+		args = append(args, page.Limit+1)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return query, args, nil
+}
+
+// exportBatchSize is the number of rows EachStudent reads at a time.
+const exportBatchSize = 500
+
+// EachStudent calls fn for every student matching the filter, in ID order.
+// The students are read a page at a time, each page resuming after the last
+// ID of the one before, so the table is never held in memory at once and
+// QueryTimeout applies to each page rather than to the whole. All pages come
+// from one snapshot, so writes made while the export runs do not show up in
+// its later pages. Iteration stops at the first error fn returns.
+func EachStudent(ctx context.Context, db *sql.DB, filter StudentFilter, fn func(Student) error) error {
+	return database.WithSnapshot(ctx, db, func(tx *database.Tx) error {
+		page := StudentPage{Sort: SortByID, Limit: exportBatchSize}
+		for {
+			students, next, err := ListStudents(ctx, tx, filter, page)
+			if err != nil {
+				return err
+			}
+			for _, s := range students {
+				if err := fn(s); err != nil {
+					return err
+				}
+			}
+			if next == nil {
+				return nil
+			}
+			page.After = next
+		}
+	})
+}
+
+func GetStudent(ctx context.Context, db DBTX, id int) (*Student, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	s, err := scanStudent(db.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL",
+		id, tenant.ID(ctx)))
 	if err == sql.ErrNoRows {
-		return nil, errors.New("student not found")
+		return nil, apperror.NotFound("student %d not found", id)
 	}
-	return &s, err
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &s, nil
 }
 
-func (s *Student) Update(db *sql.DB, id int) error {
-	res, err := db.Exec("UPDATE students SET name = $1, age = $2, email = $3 WHERE id = $4",
-		s.Name, s.Age, s.Email, id)
+// GetStudentByEmail looks a student up by email, ignoring case.
+func GetStudentByEmail(ctx context.Context, db DBTX, email string) (*Student, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	s, err := scanStudent(db.QueryRowContext(ctx, `SELECT `+studentColumns+` FROM students
+		WHERE tenant_id = $1 AND lower(email) = lower($2) AND deleted_at IS NULL`, tenant.ID(ctx), email))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("no student with email %s", email)
+	}
 	if err != nil {
-		return err
+		return nil, translateError(err)
 	}
-	count, err := res.RowsAffected()
+	return &s, nil
+}
+
+func (s *Student) Update(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	attributes, err := attributesJSON(s.Attributes)
 	if err != nil {
 		return err
 	}
-	if count == 0 {
-		return errors.New("student not found")
+	err = db.QueryRowContext(ctx, `UPDATE students SET name = $1, age = $2, email = $3, attributes = $4, updated_at = now()
+		WHERE id = $5 AND tenant_id = $6 AND deleted_at IS NULL RETURNING created_at, updated_at`,
+		s.Name, s.Age, s.Email, attributes, id, tenant.ID(ctx)).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperror.NotFound("student %d not found", id)
+	}
+	if err != nil {
+		return translateError(err)
 	}
 	s.ID = id
+	s.DeletedAt = nil
 	return nil
 }
 
-func DeleteStudent(db *sql.DB, id int) (int,error) {
-	res, err := db.Exec("DELETE FROM students WHERE id = $1", id)
+// DeleteStudent soft deletes a student. It returns 0 if no live student has
+// the given ID.
+func DeleteStudent(ctx context.Context, db DBTX, id int) (int, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "UPDATE students SET deleted_at = now() WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL",
+		id, tenant.ID(ctx))
 	if err != nil {
-		return 0 , err
+		return 0, translateError(err)
 	}
 	count, err := res.RowsAffected()
 	if err != nil {
-		return 0, err
+		return 0, translateError(err)
 	}
 	if count == 0 {
 		return 0, nil
 	}
 	return id, nil
 }
+
+// LockStudent reads a student, deleted or not, and locks its row until the
+// end of the transaction.
+func LockStudent(ctx context.Context, db DBTX, id int) (*Student, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	s, err := scanStudent(db.QueryRowContext(ctx, "SELECT "+studentColumns+" FROM students WHERE id = $1 AND tenant_id = $2"+database.DialectOf(db).ForUpdate(),
+		id, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("student %d not found", id)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &s, nil
+}
+
+// RestoreStudent undoes a soft delete. It fails with a conflict if another
+// student has taken the email in the meantime.
+func RestoreStudent(ctx context.Context, db DBTX, id int) (*Student, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	s, err := scanStudent(db.QueryRowContext(ctx, `UPDATE students SET deleted_at = NULL, updated_at = now()
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL RETURNING `+studentColumns, id, tenant.ID(ctx)))
+	if err == sql.ErrNoRows {
+		return nil, apperror.NotFound("no deleted student %d", id)
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &s, nil
+}
+
+// PurgeDeletedStudents permanently removes students of every tenant soft
+// deleted before the given time and returns how many were removed.
+func PurgeDeletedStudents(ctx context.Context, db DBTX, before time.Time) (int64, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, "DELETE FROM students WHERE deleted_at IS NOT NULL AND deleted_at < $1", before)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+)
+
+// Fields a student listing can be sorted by.
+const (
+	SortByID    = "id"
+	SortByName  = "name"
+	SortByAge   = "age"
+	SortByEmail = "email"
+)
+
+const maxStudentPageSize = 1000
+
+// SortKey is what a student is sorted by: Str for names and emails, which
+// are lowercased, or Num for ages and IDs. Ties are broken by ID.
+type SortKey struct {
+	Str string `json:"str,omitempty"`
+	Num int    `json:"num,omitempty"`
+	ID  int    `json:"id"`
+}
+
+// Compare orders keys of the same field.
+func (k SortKey) Compare(o SortKey) int {
+	if c := strings.Compare(k.Str, o.Str); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.Num, o.Num); c != 0 {
+		return c
+	}
+	return cmp.Compare(k.ID, o.ID)
+}
+
+// SortKey returns the student's key for sorting by field.
+func (s Student) SortKey(field string) SortKey {
+	switch field {
+	case SortByName:
+		return SortKey{Str: strings.ToLower(s.Name), ID: s.ID}
+	case SortByAge:
+		return SortKey{Num: s.Age, ID: s.ID}
+	case SortByEmail:
+		return SortKey{Str: strings.ToLower(s.Email), ID: s.ID}
+	}
+	return SortKey{Num: s.ID, ID: s.ID}
+}
+
+// StudentPage selects a page of a sorted student listing.
+type StudentPage struct {
+	// Sort is one of the SortBy fields, SortByID if empty.
+	Sort string
+	Desc bool
+	// After resumes the listing after the last student of the previous
+	// page.
+	After *StudentCursor
+	// Limit is the most students to return; 0 returns all of them.
+	Limit int
+}
+
+// Before reports whether a student with key k comes before one with key o
+// in the page's order.
+func (p StudentPage) Before(k, o SortKey) bool {
+	if p.Desc {
+		return k.Compare(o) > 0
+	}
+	return k.Compare(o) < 0
+}
+
+// StudentCursor marks where a page of a listing ended.
+type StudentCursor struct {
+	Sort string  `json:"sort"`
+	Desc bool    `json:"desc,omitempty"`
+	Key  SortKey `json:"key"`
+}
+
+// NextCursor returns the cursor for the page after the one ending with s.
+func (p StudentPage) NextCursor(s Student) *StudentCursor {
+	return &StudentCursor{Sort: p.Sort, Desc: p.Desc, Key: s.SortKey(p.Sort)}
+}
+
+// Encode returns the cursor as an opaque value for the after parameter.
+func (c StudentCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseStudentPage reads the sort, limit and after parameters of a student
+// listing. sort is a field, or a field preceded by "-" to sort in
+// descending order.
+func ParseStudentPage(values url.Values) (StudentPage, error) {
+	page := StudentPage{Sort: SortByID}
+	if v := values.Get("sort"); v != "" {
+		page.Sort, page.Desc = strings.CutPrefix(v, "-")
+		switch page.Sort {
+		case SortByID, SortByName, SortByAge, SortByEmail:
+		default:
+			return page, apperror.BadRequest("sort must be id, name, age or email, optionally preceded by -")
+		}
+	}
+	if v := values.Get("limit"); v != "" {
+		var err error
+		if page.Limit, err = strconv.Atoi(v); err != nil || page.Limit < 1 || page.Limit > maxStudentPageSize {
+			return page, apperror.BadRequest("limit must be between 1 and %d", maxStudentPageSize)
+		}
+	}
+	if v := values.Get("after"); v != "" {
+		b, err := base64.RawURLEncoding.DecodeString(v)
+		var cursor StudentCursor
+		if err != nil || json.Unmarshal(b, &cursor) != nil {
+			return page, apperror.BadRequest("after is not a valid cursor")
+		}
+		if cursor.Sort != page.Sort || cursor.Desc != page.Desc {
+			return page, apperror.BadRequest("after belongs to a listing with a different sort")
+		}
+		page.After = &cursor
+	}
+	return page, nil
+}
+
+// ParseRanges reads the range filters of a student listing: min_age and
+// max_age, inclusive, and name_prefix and email_prefix, which ignore case.
+func (f *StudentFilter) ParseRanges(values url.Values) error {
+	for _, bound := range []struct {
+		name string
+		dst  **int
+	}{{"min_age", &f.MinAge}, {"max_age", &f.MaxAge}} {
+		v := values.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return apperror.BadRequest("%s must be an integer", bound.name)
+		}
+		*bound.dst = &n
+	}
+	f.NamePrefix = strings.ToLower(values.Get("name_prefix"))
+	f.EmailPrefix = strings.ToLower(values.Get("email_prefix"))
+	return nil
+}
+
+// inRanges reports whether the student is within the filter's ranges.
+func (f StudentFilter) inRanges(s Student) bool {
+	return (f.MinAge == nil || s.Age >= *f.MinAge) &&
+		(f.MaxAge == nil || s.Age <= *f.MaxAge) &&
+		strings.HasPrefix(strings.ToLower(s.Name), f.NamePrefix) &&
+		strings.HasPrefix(strings.ToLower(s.Email), f.EmailPrefix)
+}
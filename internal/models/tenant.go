@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/database"
+	"github.com/AashishKumar-3002/FealtyX/internal/tenant"
+)
+
+// Students, courses, guardians, custom fields and API keys have a tenant_id
+// column, and the queries on them are confined to the tenant of their
+// context. The other tables hold the records of a student, which belong to
+// the student's tenant.
+
+// inTenant reports whether the row of table with the given ID belongs to
+// the tenant of ctx.
+func inTenant(ctx context.Context, db DBTX, table string, id int) (bool, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var ok bool
+	err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = $1 AND tenant_id = $2)",
+		id, tenant.ID(ctx)).Scan(&ok)
+	return ok, translateError(err)
+}
+
+// StudentInTenant reports whether the student, deleted or not, belongs to
+// the tenant of ctx.
+func StudentInTenant(ctx context.Context, db DBTX, id int) (bool, error) {
+	return inTenant(ctx, db, "students", id)
+}
+
+// checkCourse fails with not found unless the course belongs to the tenant
+// of ctx, so that no record refers to another tenant's course.
+func checkCourse(ctx context.Context, db DBTX, id int) error {
+	ok, err := inTenant(ctx, db, "courses", id)
+	if err == nil && !ok {
+		return apperror.NotFound("course %d not found", id)
+	}
+	return err
+}
+
+// checkGuardian is checkCourse for guardians.
+func checkGuardian(ctx context.Context, db DBTX, id int) error {
+	ok, err := inTenant(ctx, db, "guardians", id)
+	if err == nil && !ok {
+		return apperror.NotFound("guardian %d not found", id)
+	}
+	return err
+}
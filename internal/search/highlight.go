@@ -0,0 +1,67 @@
+package search
+
+import "strings"
+
+// Markers wrapped around matched terms in highlights.
+const (
+	StartSel = "<mark>"
+	StopSel  = "</mark>"
+)
+
+// fragmentWords is how many words a highlighted fragment of a long text
+// holds, and fragmentLead how many of them come before the first match.
+const (
+	fragmentWords = 35
+	fragmentLead  = 5
+)
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeHTML escapes the characters that would let a highlighted text be
+// read as markup.
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// Highlight returns text with the terms of q wrapped in StartSel and
+// StopSel and everything else HTML-escaped.
+func (q Query) Highlight(text string) string {
+	return q.highlight(text, tokenize(text), 0, len(text))
+}
+
+// Fragment is like Highlight, but cuts a long text down to the words
+// around its first match.
+func (q Query) Fragment(text string) string {
+	tokens := tokenize(text)
+	if len(tokens) <= fragmentWords {
+		return q.highlight(text, tokens, 0, len(text))
+	}
+	first := 0
+	for i, t := range tokens {
+		if q.has(t.term) {
+			first = i
+			break
+		}
+	}
+	from := max(0, min(first-fragmentLead, len(tokens)-fragmentWords))
+	to := from + fragmentWords
+	return q.highlight(text, tokens[from:to], tokens[from].start, tokens[to-1].end)
+}
+
+// highlight writes text[start:end], marking the tokens that match q.
+func (q Query) highlight(text string, tokens []token, start, end int) string {
+	var b strings.Builder
+	at := start
+	for _, t := range tokens {
+		if !q.has(t.term) {
+			continue
+		}
+		b.WriteString(EscapeHTML(text[at:t.start]))
+		b.WriteString(StartSel)
+		b.WriteString(EscapeHTML(text[t.start:t.end]))
+		b.WriteString(StopSel)
+		at = t.end
+	}
+	b.WriteString(EscapeHTML(text[at:end]))
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package search
+
+import (
+	"math"
+	"sort"
+)
+
+// Weights of the parts of a student, the same as the default weights of
+// Postgres's ts_rank for the labels A to D that the SQL backend gives them.
+const (
+	WeightName    = 1.0
+	WeightEmail   = 0.4
+	WeightSummary = 0.2
+	WeightNote    = 0.1
+)
+
+// Kind says what a document is.
+type Kind int
+
+const (
+	// KindStudent is a student's name and email.
+	KindStudent Kind = iota
+	KindSummary
+	KindNote
+)
+
+// Key identifies a document: a student, its summary or one of its notes,
+// each by its own ID.
+type Key struct {
+	Kind Kind
+	ID   int
+}
+
+// Field is some text of a document and the weight of its terms.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+type document struct {
+	student int
+	// terms maps each term to its frequency times its weight.
+	terms map[string]float64
+}
+
+// Index is an inverted index of the documents that make up students. It
+// is not safe for concurrent use while it is being changed.
+type Index struct {
+	docs     map[Key]document
+	postings map[string]map[Key]struct{}
+}
+
+func NewIndex() *Index {
+	return &Index{docs: make(map[Key]document), postings: make(map[string]map[Key]struct{})}
+}
+
+// Put indexes a document of student, replacing any earlier version of it.
+func (ix *Index) Put(key Key, student int, fields ...Field) {
+	ix.Remove(key)
+	doc := document{student: student, terms: make(map[string]float64)}
+	for _, f := range fields {
+		for _, t := range tokenize(f.Text) {
+			doc.terms[t.term] += f.Weight
+		}
+	}
+	if len(doc.terms) == 0 {
+		return
+	}
+	ix.docs[key] = doc
+	for term := range doc.terms {
+		keys := ix.postings[term]
+		if keys == nil {
+			keys = make(map[Key]struct{})
+			ix.postings[term] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// Remove drops a document from the index, if it is there.
+func (ix *Index) Remove(key Key) {
+	doc, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	delete(ix.docs, key)
+	for term := range doc.terms {
+		delete(ix.postings[term], key)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+}
+
+// Hit is a student with at least one document that matches a query.
+type Hit struct {
+	Student int
+	// Rank is the sum of the ranks of the matching documents.
+	Rank float64
+	// Matches are the matching documents, best first.
+	Matches []Key
+}
+
+// Search returns the students with documents holding every term of q,
+// best first and then by ID.
+func (ix *Index) Search(q Query) []Hit {
+	if q.Empty() {
+		return nil
+	}
+	terms := append([]string(nil), q.terms...)
+	sort.Slice(terms, func(i, j int) bool { return len(ix.postings[terms[i]]) < len(ix.postings[terms[j]]) })
+
+	type match struct {
+		key  Key
+		rank float64
+	}
+	byStudent := make(map[int][]match)
+	for key := range ix.postings[terms[0]] {
+		doc := ix.docs[key]
+		rank := 0.0
+		for _, term := range terms {
+			freq, ok := doc.terms[term]
+			if !ok {
+				rank = -1
+				break
+			}
+			rank += ix.idf(term) * freq / (1 + freq)
+		}
+		if rank >= 0 {
+			byStudent[doc.student] = append(byStudent[doc.student], match{key, rank})
+		}
+	}
+
+	hits := make([]Hit, 0, len(byStudent))
+	for student, matches := range byStudent {
+		sort.Slice(matches, func(i, j int) bool {
+			if matches[i].rank != matches[j].rank {
+				return matches[i].rank > matches[j].rank
+			}
+			return matches[i].key.ID < matches[j].key.ID
+		})
+		hit := Hit{Student: student}
+		for _, m := range matches {
+			hit.Rank += m.rank
+			hit.Matches = append(hit.Matches, m.key)
+		}
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Student < hits[j].Student
+	})
+	return hits
+}
+
+// idf weighs a term by how rare it is among the documents.
+func (ix *Index) idf(term string) float64 {
+	return math.Log(1 + float64(len(ix.docs))/float64(len(ix.postings[term])))
+}
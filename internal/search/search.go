@@ -0,0 +1,112 @@
+// Package search implements the in-process full-text search over students:
+// a tokenizer, an inverted index and highlighting of matched terms. It
+// follows what the Postgres backend does with tsvector closely enough that
+// both backends find the same students for the same query.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token is a term and where it appears in the text it was read from.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into runs of letters and digits, lowercases and
+// stems them, and leaves out stop words.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			if term := normalize(text[start:i]); term != "" {
+				tokens = append(tokens, token{term: term, start: start, end: i})
+			}
+			start = -1
+		}
+	}
+	return tokens
+}
+
+// normalize returns the term a word is indexed under, or "" for a stop
+// word.
+func normalize(word string) string {
+	word = strings.ToLower(word)
+	if stopWords[word] {
+		return ""
+	}
+	return stem(word)
+}
+
+// stem strips common English inflections, so that "grades" finds "grade"
+// and "studying" finds "study". It is much cruder than the Snowball stemmer
+// Postgres uses, but queries and documents go through the same one.
+func stem(word string) string {
+	switch n := len(word); {
+	case n > 4 && strings.HasSuffix(word, "ies"):
+		return word[:n-3] + "y"
+	case n > 4 && strings.HasSuffix(word, "sses"):
+		return word[:n-2]
+	case n > 5 && strings.HasSuffix(word, "ing"):
+		return word[:n-3]
+	case n > 4 && strings.HasSuffix(word, "ed"):
+		return word[:n-2]
+	case n > 3 && strings.HasSuffix(word, "s") &&
+		!strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:n-1]
+	}
+	return word
+}
+
+// stopWords are too common to be worth searching for.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "has": true,
+	"have": true, "he": true, "her": true, "his": true, "in": true, "is": true,
+	"it": true, "its": true, "of": true, "on": true, "or": true, "she": true,
+	"that": true, "the": true, "their": true, "they": true, "this": true,
+	"to": true, "was": true, "were": true, "will": true, "with": true,
+}
+
+// Query is a parsed search query. A document matches when it holds every
+// term of the query.
+type Query struct {
+	terms []string
+}
+
+// ParseQuery reads a query made of words; punctuation is ignored.
+func ParseQuery(q string) Query {
+	var query Query
+	seen := make(map[string]bool)
+	for _, t := range tokenize(q) {
+		if !seen[t.term] {
+			seen[t.term] = true
+			query.terms = append(query.terms, t.term)
+		}
+	}
+	return query
+}
+
+// Empty reports whether the query has no terms to search for, as when it
+// only holds stop words.
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+func (q Query) has(term string) bool {
+	for _, t := range q.terms {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
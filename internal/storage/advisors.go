@@ -0,0 +1,80 @@
+package storage
+
+import (
+    "sort"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// AssignAdvisor assigns a live student to an advisor.
+func (s *Storage) AssignAdvisor(a models.StudentAdvisor) (models.StudentAdvisor, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if student, ok := s.students.get(a.StudentID); !ok || student.IsDeleted() {
+        return models.StudentAdvisor{}, apperror.NotFound("student %d not found", a.StudentID)
+    }
+    if _, ok := s.advisors[a.StudentID][a.Advisor]; ok {
+        return models.StudentAdvisor{}, apperror.Conflict("the student is already assigned to this advisor")
+    }
+
+    a.CreatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutAdvisor, Advisor: &a}); err != nil {
+        return models.StudentAdvisor{}, err
+    }
+    return a, nil
+}
+
+// GetStudentAdvisors lists the advisors a student is assigned to, in the
+// order they were assigned.
+func (s *Storage) GetStudentAdvisors(studentID int) []models.StudentAdvisor {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    advisors := make([]models.StudentAdvisor, 0, len(s.advisors[studentID]))
+    for _, a := range s.advisors[studentID] {
+        advisors = append(advisors, a)
+    }
+    sort.Slice(advisors, func(i, j int) bool {
+        if !advisors[i].CreatedAt.Equal(advisors[j].CreatedAt) {
+            return advisors[i].CreatedAt.Before(advisors[j].CreatedAt)
+        }
+        return advisors[i].Advisor < advisors[j].Advisor
+    })
+    return advisors
+}
+
+// IsAssigned reports whether the student is assigned to the advisor.
+func (s *Storage) IsAssigned(advisor string, studentID int) bool {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    _, ok := s.advisees[advisor][studentID]
+    return ok
+}
+
+func (s *Storage) UnassignAdvisor(studentID int, advisor string) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    a, ok := s.advisors[studentID][advisor]
+    if !ok {
+        return apperror.NotFound("student %d is not assigned to %s", studentID, advisor)
+    }
+    return s.commit(change{Op: opDeleteAdvisor, Advisor: &a})
+}
+
+// unassign removes an assignment from both maps. The caller must hold the
+// mutex.
+func (s *Storage) unassign(studentID int, advisor string) {
+    delete(s.advisors[studentID], advisor)
+    if len(s.advisors[studentID]) == 0 {
+        delete(s.advisors, studentID)
+    }
+    delete(s.advisees[advisor], studentID)
+    if len(s.advisees[advisor]) == 0 {
+        delete(s.advisees, advisor)
+    }
+}
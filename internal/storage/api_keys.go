@@ -0,0 +1,84 @@
+package storage
+
+import (
+    "sort"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/auth"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// apiKey is a stored API key along with the hash it is looked up by.
+type apiKey struct {
+    models.APIKey
+    Hash string `json:"hash"`
+    // Admin is set on keys written before keys had roles, in place of the
+    // role; apply turns it into one.
+    Admin bool `json:"admin,omitempty"`
+}
+
+// legacyRole is the role of a key written before keys had roles.
+func legacyRole(admin bool) string {
+    if admin {
+        return string(auth.RoleAdmin)
+    }
+    return string(auth.RoleViewer)
+}
+
+// CreateAPIKey stores a key under the hash it is looked up by. Names are
+// unique, since they identify keys in the audit log; a storage holds the
+// keys of one tenant.
+func (s *Storage) CreateAPIKey(key models.APIKey, hash string) (models.APIKey, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    for _, other := range s.apiKeys {
+        if other.Name == key.Name {
+            return models.APIKey{}, apperror.Conflict("an API key with this name already exists")
+        }
+    }
+
+    key.ID = s.nextAPIKeyID
+    key.CreatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutAPIKey, APIKey: &apiKey{APIKey: key, Hash: hash}}); err != nil {
+        return models.APIKey{}, err
+    }
+    return key, nil
+}
+
+func (s *Storage) GetAPIKeys() []models.APIKey {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    keys := make([]models.APIKey, 0, len(s.apiKeys))
+    for _, key := range s.apiKeys {
+        keys = append(keys, key.APIKey)
+    }
+    sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+    return keys
+}
+
+// GetAPIKeyByHash finds the key with the given hash.
+func (s *Storage) GetAPIKeyByHash(hash string) (models.APIKey, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    id, ok := s.apiKeyHashes[hash]
+    if !ok {
+        return models.APIKey{}, apperror.NotFound("API key not found")
+    }
+    return s.apiKeys[id].APIKey, nil
+}
+
+// DeleteAPIKey revokes a key; requests made with it fail from then on.
+func (s *Storage) DeleteAPIKey(id int) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    key, ok := s.apiKeys[id]
+    if !ok {
+        return apperror.NotFound("API key %d not found", id)
+    }
+    return s.commit(change{Op: opDeleteAPIKey, APIKey: &key})
+}
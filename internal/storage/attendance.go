@@ -0,0 +1,89 @@
+package storage
+
+import (
+    "sort"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// SaveAttendance records attendance for live students, replacing any record
+// for the same student and day. Either every record is saved or none are.
+func (s *Storage) SaveAttendance(records []models.AttendanceRecord) ([]models.AttendanceRecord, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    for _, record := range records {
+        if student, ok := s.students.get(record.StudentID); !ok || student.IsDeleted() {
+            return nil, apperror.NotFound("student %d not found", record.StudentID)
+        }
+        if record.CourseID != nil {
+            if _, ok := s.courses[*record.CourseID]; !ok {
+                return nil, apperror.NotFound("course %d not found", *record.CourseID)
+            }
+        }
+    }
+
+    // A day can appear more than once in the batch; the last record wins,
+    // keeping the ID of the first.
+    type day struct {
+        studentID int
+        date      string
+    }
+    batch := make(map[day]models.AttendanceRecord)
+    nextID := s.nextAttendanceID
+
+    now := time.Now().UTC()
+    saved := make([]models.AttendanceRecord, len(records))
+    changes := make([]change, len(records))
+    for i, record := range records {
+        key := day{record.StudentID, record.Date}
+        existing, ok := batch[key]
+        if !ok {
+            existing, ok = s.attendance[record.StudentID][record.Date]
+        }
+        if ok {
+            record.ID = existing.ID
+            record.CreatedAt = existing.CreatedAt
+        } else {
+            record.ID = nextID
+            record.CreatedAt = now
+            nextID++
+        }
+        record.UpdatedAt = now
+        batch[key] = record
+        saved[i] = record
+        changes[i] = change{Op: opPutAttendance, Attendance: &saved[i]}
+    }
+    if err := s.commit(changes...); err != nil {
+        return nil, err
+    }
+    return saved, nil
+}
+
+// GetAttendance lists a student's attendance in the range, oldest first.
+func (s *Storage) GetAttendance(studentID int, dr models.DateRange) []models.AttendanceRecord {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    records := []models.AttendanceRecord{}
+    for date, record := range s.attendance[studentID] {
+        if dr.Contains(date) {
+            records = append(records, record)
+        }
+    }
+    sort.Slice(records, func(i, j int) bool { return records[i].Date < records[j].Date })
+    return records
+}
+
+func (s *Storage) DeleteAttendance(studentID int, date string) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    record, ok := s.attendance[studentID][date]
+    if !ok {
+        return apperror.NotFound("no attendance for student %d on %s", studentID, date)
+    }
+    return s.commit(change{Op: opDeleteAttendance, Attendance: &record})
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// Actor identifies who made a change to a student, for its audit entry.
+type Actor struct {
+    Name      string
+    RequestID string
+}
+
+// auditChange returns the change that appends an audit entry for a student
+// going from before to after. It is committed together with the change it
+// describes, so the two are logged in the same write-ahead log entry. The
+// caller must hold writeMutex.
+func (s *Storage) auditChange(action models.AuditAction, before, after *models.Student, actor Actor) change {
+    entry := models.NewAuditEntry(action, before, after, actor.Name, actor.RequestID)
+    entry.ID = s.nextAuditID
+    return change{Op: opAppendAudit, Audit: entry}
+}
+
+// History lists every audit entry for a student, oldest first.
+func (s *Storage) History(studentID int) []models.AuditEntry {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    return append([]models.AuditEntry{}, s.audit[studentID]...)
+}
+
+// AsOf reconstructs a student as it was at the given time.
+func (s *Storage) AsOf(studentID int, at time.Time) (*models.Student, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    entries := s.audit[studentID]
+    for i := len(entries) - 1; i >= 0; i-- {
+        if !entries[i].Timestamp.After(at) {
+            return models.StudentFromAudit(entries[i], at)
+        }
+    }
+    return nil, apperror.NotFound("student %d did not exist at %s", studentID, at.Format(time.RFC3339))
+}
@@ -0,0 +1,29 @@
+package storage
+
+import "github.com/AashishKumar-3002/FealtyX/internal/models"
+
+// backend runs the shared backend tests against a storage.
+type backend struct{ *Storage }
+
+func (b backend) CreateStudent(student models.Student) (models.Student, error) {
+    return b.Create(student, Actor{})
+}
+
+func (b backend) SaveSummary(summary models.StudentSummary) error {
+    _, err := b.Storage.SaveSummary(summary)
+    return err
+}
+
+func (b backend) CreateNote(note models.Note) error {
+    _, err := b.Storage.CreateNote(note)
+    return err
+}
+
+func (b backend) ListStudents(filter models.StudentFilter, page models.StudentPage) ([]models.Student, *models.StudentCursor, error) {
+    students, next := b.List(filter, page)
+    return students, next, nil
+}
+
+func (b backend) SearchStudents(q string, limit int) ([]models.SearchResult, error) {
+    return b.Search(q, limit, ""), nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+    "fmt"
+    "io"
+    "log"
+    "math/rand"
+    "testing"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+const benchStudents = 10000
+
+// BenchmarkMixed measures throughput with concurrent goroutines doing a mix
+// of point reads, listings and updates, for a storage kept in memory only
+// and for one kept on disk with each fsync policy.
+func BenchmarkMixed(b *testing.B) {
+    backends := []struct {
+        name string
+        open func(b *testing.B) *Storage
+    }{
+        {"memory", func(b *testing.B) *Storage { return NewStorage() }},
+        {"wal-always", openBench(FsyncAlways)},
+        {"wal-never", openBench(FsyncNever)},
+    }
+    for _, backend := range backends {
+        for _, writes := range []int{1, 10, 50} {
+            b.Run(fmt.Sprintf("%s/writes=%d%%", backend.name, writes), func(b *testing.B) {
+                s := backend.open(b)
+                seedBench(b, s)
+                benchmarkMixed(b, s, writes)
+            })
+        }
+    }
+}
+
+// BenchmarkReadsDuringWrites measures point reads and listings while
+// another goroutine keeps updating students, which is where readers
+// waiting for writers shows.
+func BenchmarkReadsDuringWrites(b *testing.B) {
+    for _, policy := range []FsyncPolicy{FsyncAlways, FsyncNever} {
+        b.Run("wal-"+string(policy), func(b *testing.B) {
+            s := openBench(policy)(b)
+            seedBench(b, s)
+
+            stop := make(chan struct{})
+            done := make(chan struct{})
+            go func() {
+                defer close(done)
+                for i := 0; ; i++ {
+                    select {
+                    case <-stop:
+                        return
+                    default:
+                    }
+                    id := 1 + i%benchStudents
+                    s.Update(id, benchStudent(id-1, i), Actor{})
+                }
+            }()
+            benchmarkMixed(b, s, 0)
+            close(stop)
+            <-done
+        })
+    }
+}
+
+func openBench(policy FsyncPolicy) func(b *testing.B) *Storage {
+    return func(b *testing.B) *Storage {
+        log.SetOutput(io.Discard)
+        s, err := Open(Options{Dir: b.TempDir(), Fsync: policy})
+        if err != nil {
+            b.Fatal(err)
+        }
+        b.Cleanup(func() { s.Close() })
+        return s
+    }
+}
+
+func seedBench(b *testing.B, s *Storage) {
+    for i := 0; i < benchStudents; i++ {
+        if _, err := s.Create(benchStudent(i, 0), Actor{}); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
+
+func benchStudent(i, age int) models.Student {
+    return models.Student{Name: fmt.Sprintf("Student %d", i), Age: 18 + age%10, Email: fmt.Sprintf("student%d@example.com", i)}
+}
+
+// benchmarkMixed runs b.N operations over the parallel goroutines, writes
+// percent of them updates and the rest point reads, with one read in ten a
+// page of a listing.
+func benchmarkMixed(b *testing.B, s *Storage, writes int) {
+    page := models.StudentPage{Sort: models.SortByName, Limit: 20}
+    b.ReportAllocs()
+    b.SetParallelism(8)
+    b.ResetTimer()
+    b.RunParallel(func(pb *testing.PB) {
+        r := rand.New(rand.NewSource(rand.Int63()))
+        for pb.Next() {
+            id := 1 + r.Intn(benchStudents)
+            switch n := r.Intn(100); {
+            case n < writes:
+                if _, _, err := s.Update(id, benchStudent(id-1, n), Actor{}); err != nil {
+                    b.Error(err)
+                }
+            case n%10 == 0:
+                s.List(models.StudentFilter{}, page)
+            default:
+                if _, err := s.GetByID(id); err != nil {
+                    b.Error(err)
+                }
+            }
+        }
+    })
+}
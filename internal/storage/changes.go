@@ -0,0 +1,332 @@
+package storage
+
+import (
+    "fmt"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/AashishKumar-3002/FealtyX/internal/search"
+)
+
+// Operations a change can make.
+const (
+    opPutStudent         = "put_student"
+    opPurgeStudent       = "purge_student"
+    opPutCourse          = "put_course"
+    opDeleteCourse       = "delete_course"
+    opPutEnrollment      = "put_enrollment"
+    opDeleteEnrollment   = "delete_enrollment"
+    opPutGrade           = "put_grade"
+    opDeleteGrade        = "delete_grade"
+    opPutAttendance      = "put_attendance"
+    opDeleteAttendance   = "delete_attendance"
+    opPutNote            = "put_note"
+    opDeleteNote         = "delete_note"
+    opPutGuardian        = "put_guardian"
+    opDeleteGuardian     = "delete_guardian"
+    opPutGuardianLink    = "put_guardian_link"
+    opDeleteGuardianLink = "delete_guardian_link"
+    opPutCustomField     = "put_custom_field"
+    opDeleteCustomField  = "delete_custom_field"
+    opPutSummary         = "put_summary"
+    opPutAPIKey          = "put_api_key"
+    opDeleteAPIKey       = "delete_api_key"
+    opPutAdvisor         = "put_advisor"
+    opDeleteAdvisor      = "delete_advisor"
+    opAppendAudit        = "append_audit"
+)
+
+// change is one mutation of the storage: a record to store or a record to
+// remove, along with whatever depends on it. Every write goes through apply,
+// both when it is made and when it is replayed from the write-ahead log, so
+// the two cannot drift apart.
+type change struct {
+    Op           string                   `json:"op"`
+    Student      *models.Student          `json:"student,omitempty"`
+    Course       *models.Course           `json:"course,omitempty"`
+    Enrollment   *models.Enrollment       `json:"enrollment,omitempty"`
+    Grade        *models.Grade            `json:"grade,omitempty"`
+    Attendance   *models.AttendanceRecord `json:"attendance,omitempty"`
+    Note         *models.Note             `json:"note,omitempty"`
+    Guardian     *models.Guardian         `json:"guardian,omitempty"`
+    GuardianLink *models.StudentGuardian  `json:"guardian_link,omitempty"`
+    CustomField  *models.CustomField      `json:"custom_field,omitempty"`
+    Summary      *models.StudentSummary   `json:"summary,omitempty"`
+    APIKey       *apiKey                  `json:"api_key,omitempty"`
+    Advisor      *models.StudentAdvisor   `json:"advisor,omitempty"`
+    Audit        *models.AuditEntry       `json:"audit,omitempty"`
+}
+
+// validate checks that a change read back from disk has the record its
+// operation needs.
+func (c change) validate() error {
+    var ok bool
+    switch c.Op {
+    case opPutStudent, opPurgeStudent:
+        ok = c.Student != nil
+    case opPutCourse, opDeleteCourse:
+        ok = c.Course != nil
+    case opPutEnrollment, opDeleteEnrollment:
+        ok = c.Enrollment != nil
+    case opPutGrade, opDeleteGrade:
+        ok = c.Grade != nil
+    case opPutAttendance, opDeleteAttendance:
+        ok = c.Attendance != nil
+    case opPutNote, opDeleteNote:
+        ok = c.Note != nil
+    case opPutGuardian, opDeleteGuardian:
+        ok = c.Guardian != nil
+    case opPutGuardianLink, opDeleteGuardianLink:
+        ok = c.GuardianLink != nil
+    case opPutCustomField, opDeleteCustomField:
+        ok = c.CustomField != nil
+    case opPutSummary:
+        ok = c.Summary != nil
+    case opPutAPIKey, opDeleteAPIKey:
+        ok = c.APIKey != nil
+    case opPutAdvisor, opDeleteAdvisor:
+        ok = c.Advisor != nil
+    case opAppendAudit:
+        ok = c.Audit != nil
+    default:
+        return fmt.Errorf("unknown operation %q", c.Op)
+    }
+    if !ok {
+        return fmt.Errorf("%s change has no record", c.Op)
+    }
+    return nil
+}
+
+// commit writes the changes to the write-ahead log, if the storage is
+// persistent, and then applies them. Nothing is applied if they cannot be
+// logged. The caller must hold writeMutex; readers are only held up while
+// the changes are applied.
+func (s *Storage) commit(changes ...change) error {
+    if s.wal != nil {
+        if err := s.wal.append(changes); err != nil {
+            return fmt.Errorf("writing to the write-ahead log: %w", err)
+        }
+    }
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    for _, c := range changes {
+        s.apply(c)
+    }
+    return nil
+}
+
+// nextAfter returns the next ID to hand out once id is taken.
+func nextAfter(next, id int) int {
+    if id >= next {
+        return id + 1
+    }
+    return next
+}
+
+// apply makes a change in memory. The caller must hold both writeMutex and
+// the mutex, or have the storage to itself.
+func (s *Storage) apply(c change) {
+    switch c.Op {
+    case opPutStudent:
+        student := *c.Student
+        if old, ok := s.students.get(student.ID); ok {
+            if owner, _ := s.emails.get(emailKey(old.Email)); owner == student.ID {
+                s.emails.delete(emailKey(old.Email))
+            }
+            s.unindexSorted(old)
+        }
+        s.students.set(student.ID, student)
+        s.indexSorted(student)
+        if !student.IsDeleted() {
+            s.emails.set(emailKey(student.Email), student.ID)
+        }
+        s.nextID = nextAfter(s.nextID, student.ID)
+        models.IndexStudent(s.index, student)
+
+    case opPurgeStudent:
+        id := c.Student.ID
+        student, _ := s.students.get(id)
+        if owner, _ := s.emails.get(emailKey(student.Email)); owner == id {
+            s.emails.delete(emailKey(student.Email))
+        }
+        s.unindexSorted(student)
+        s.students.delete(id)
+        delete(s.enrollments, id)
+        s.deleteGradesWhere(func(g models.Grade) bool { return g.StudentID == id })
+        delete(s.attendance, id)
+        delete(s.guardianLinks, id)
+        for advisor := range s.advisors[id] {
+            s.unassign(id, advisor)
+        }
+        delete(s.summaries, id)
+        s.index.Remove(search.Key{Kind: search.KindStudent, ID: id})
+        s.index.Remove(search.Key{Kind: search.KindSummary, ID: id})
+        for noteID, note := range s.notes {
+            if note.StudentID == id {
+                delete(s.notes, noteID)
+                s.index.Remove(search.Key{Kind: search.KindNote, ID: noteID})
+            }
+        }
+
+    case opPutCourse:
+        course := *c.Course
+        if old, ok := s.courses[course.ID]; ok && s.courseCodes[courseCodeKey(old.Code)] == course.ID {
+            delete(s.courseCodes, courseCodeKey(old.Code))
+        }
+        s.courses[course.ID] = course
+        s.courseCodes[courseCodeKey(course.Code)] = course.ID
+        s.nextCourseID = nextAfter(s.nextCourseID, course.ID)
+
+    case opDeleteCourse:
+        id := c.Course.ID
+        if s.courseCodes[courseCodeKey(s.courses[id].Code)] == id {
+            delete(s.courseCodes, courseCodeKey(s.courses[id].Code))
+        }
+        delete(s.courses, id)
+        for _, byCourse := range s.enrollments {
+            delete(byCourse, id)
+        }
+        s.deleteGradesWhere(func(g models.Grade) bool { return g.CourseID == id })
+        for _, byDate := range s.attendance {
+            for date, record := range byDate {
+                if record.CourseID != nil && *record.CourseID == id {
+                    record.CourseID = nil
+                    byDate[date] = record
+                }
+            }
+        }
+
+    case opPutEnrollment:
+        enrollment := *c.Enrollment
+        byCourse := s.enrollments[enrollment.StudentID]
+        if byCourse == nil {
+            byCourse = make(map[int]models.Enrollment)
+            s.enrollments[enrollment.StudentID] = byCourse
+        }
+        byCourse[enrollment.CourseID] = enrollment
+        s.nextEnrollmentID = nextAfter(s.nextEnrollmentID, enrollment.ID)
+
+    case opDeleteEnrollment:
+        delete(s.enrollments[c.Enrollment.StudentID], c.Enrollment.CourseID)
+
+    case opPutGrade:
+        s.grades[c.Grade.ID] = *c.Grade
+        s.nextGradeID = nextAfter(s.nextGradeID, c.Grade.ID)
+
+    case opDeleteGrade:
+        delete(s.grades, c.Grade.ID)
+
+    case opPutAttendance:
+        record := *c.Attendance
+        byDate := s.attendance[record.StudentID]
+        if byDate == nil {
+            byDate = make(map[string]models.AttendanceRecord)
+            s.attendance[record.StudentID] = byDate
+        }
+        byDate[record.Date] = record
+        s.nextAttendanceID = nextAfter(s.nextAttendanceID, record.ID)
+
+    case opDeleteAttendance:
+        delete(s.attendance[c.Attendance.StudentID], c.Attendance.Date)
+
+    case opPutNote:
+        s.notes[c.Note.ID] = *c.Note
+        s.nextNoteID = nextAfter(s.nextNoteID, c.Note.ID)
+        models.IndexNote(s.index, *c.Note)
+
+    case opDeleteNote:
+        delete(s.notes, c.Note.ID)
+        s.index.Remove(search.Key{Kind: search.KindNote, ID: c.Note.ID})
+
+    case opPutSummary:
+        s.summaries[c.Summary.StudentID] = *c.Summary
+        models.IndexSummary(s.index, *c.Summary)
+
+    case opPutAPIKey:
+        key := *c.APIKey
+        if key.Role == "" {
+            key.Role = legacyRole(key.Admin)
+            key.Admin = false
+        }
+        s.apiKeys[key.ID] = key
+        s.apiKeyHashes[c.APIKey.Hash] = c.APIKey.ID
+        s.nextAPIKeyID = nextAfter(s.nextAPIKeyID, c.APIKey.ID)
+
+    case opDeleteAPIKey:
+        delete(s.apiKeyHashes, s.apiKeys[c.APIKey.ID].Hash)
+        delete(s.apiKeys, c.APIKey.ID)
+
+    case opPutAdvisor:
+        a := *c.Advisor
+        byAdvisor := s.advisors[a.StudentID]
+        if byAdvisor == nil {
+            byAdvisor = make(map[string]models.StudentAdvisor)
+            s.advisors[a.StudentID] = byAdvisor
+        }
+        byAdvisor[a.Advisor] = a
+        students := s.advisees[a.Advisor]
+        if students == nil {
+            students = make(map[int]struct{})
+            s.advisees[a.Advisor] = students
+        }
+        students[a.StudentID] = struct{}{}
+
+    case opDeleteAdvisor:
+        s.unassign(c.Advisor.StudentID, c.Advisor.Advisor)
+
+    case opAppendAudit:
+        // Audit entries outlive the students they describe, as they do in
+        // the audit_log table, so purging a student leaves them in place.
+        entry := *c.Audit
+        s.audit[entry.StudentID] = append(s.audit[entry.StudentID], entry)
+        if entry.ID >= s.nextAuditID {
+            s.nextAuditID = entry.ID + 1
+        }
+
+    case opPutGuardian:
+        s.guardians[c.Guardian.ID] = *c.Guardian
+        s.nextGuardianID = nextAfter(s.nextGuardianID, c.Guardian.ID)
+
+    case opDeleteGuardian:
+        delete(s.guardians, c.Guardian.ID)
+        for _, links := range s.guardianLinks {
+            delete(links, c.Guardian.ID)
+        }
+
+    case opPutGuardianLink:
+        link := *c.GuardianLink
+        links := s.guardianLinks[link.StudentID]
+        if links == nil {
+            links = make(map[int]models.StudentGuardian)
+            s.guardianLinks[link.StudentID] = links
+        }
+        links[link.GuardianID] = link
+
+    case opDeleteGuardianLink:
+        delete(s.guardianLinks[c.GuardianLink.StudentID], c.GuardianLink.GuardianID)
+
+    case opPutCustomField:
+        s.customFields[c.CustomField.Name] = *c.CustomField
+        s.nextCustomFieldID = nextAfter(s.nextCustomFieldID, c.CustomField.ID)
+
+    case opDeleteCustomField:
+        // Attribute maps are replaced rather than changed so that readers
+        // holding a student are not affected.
+        name := c.CustomField.Name
+        delete(s.customFields, name)
+        var changed []models.Student
+        s.students.each(func(_ int, student models.Student) {
+            if _, ok := student.Attributes[name]; ok {
+                changed = append(changed, student)
+            }
+        })
+        for _, student := range changed {
+            attrs := copyAttributes(student.Attributes)
+            delete(attrs, name)
+            if len(attrs) == 0 {
+                attrs = nil
+            }
+            student.Attributes = attrs
+            s.students.set(student.ID, student)
+        }
+    }
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+func courseCodeKey(code string) string {
+    return strings.ToLower(strings.TrimSpace(code))
+}
+
+func (s *Storage) CreateCourse(course models.Course) (models.Course, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if _, ok := s.courseCodes[courseCodeKey(course.Code)]; ok {
+        return models.Course{}, apperror.Conflict("a course with this code already exists")
+    }
+
+    now := time.Now().UTC()
+    course.ID = s.nextCourseID
+    course.CreatedAt = now
+    course.UpdatedAt = now
+    if err := s.commit(change{Op: opPutCourse, Course: &course}); err != nil {
+        return models.Course{}, err
+    }
+    return course, nil
+}
+
+func (s *Storage) GetAllCourses() []models.Course {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    courses := make([]models.Course, 0, len(s.courses))
+    for _, course := range s.courses {
+        courses = append(courses, course)
+    }
+    sort.Slice(courses, func(i, j int) bool { return courses[i].ID < courses[j].ID })
+    return courses
+}
+
+func (s *Storage) GetCourse(id int) (models.Course, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    course, ok := s.courses[id]
+    if !ok {
+        return models.Course{}, apperror.NotFound("course %d not found", id)
+    }
+    return course, nil
+}
+
+func (s *Storage) UpdateCourse(id int, course models.Course) (models.Course, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    existing, ok := s.courses[id]
+    if !ok {
+        return models.Course{}, apperror.NotFound("course %d not found", id)
+    }
+    if owner, ok := s.courseCodes[courseCodeKey(course.Code)]; ok && owner != id {
+        return models.Course{}, apperror.Conflict("a course with this code already exists")
+    }
+
+    course.ID = id
+    course.CreatedAt = existing.CreatedAt
+    course.UpdatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutCourse, Course: &course}); err != nil {
+        return models.Course{}, err
+    }
+    return course, nil
+}
+
+// DeleteCourse removes a course and every enrollment and grade in it, and
+// detaches attendance taken in it, like the foreign keys of the Postgres
+// backend.
+func (s *Storage) DeleteCourse(id int) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    course, ok := s.courses[id]
+    if !ok {
+        return apperror.NotFound("course %d not found", id)
+    }
+    return s.commit(change{Op: opDeleteCourse, Course: &course})
+}
+
+// Enroll enrolls a live student in a course.
+func (s *Storage) Enroll(enrollment models.Enrollment) (models.Enrollment, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if student, ok := s.students.get(enrollment.StudentID); !ok || student.IsDeleted() {
+        return models.Enrollment{}, apperror.NotFound("student %d not found", enrollment.StudentID)
+    }
+    if _, ok := s.courses[enrollment.CourseID]; !ok {
+        return models.Enrollment{}, apperror.NotFound("course %d not found", enrollment.CourseID)
+    }
+    if _, ok := s.enrollments[enrollment.StudentID][enrollment.CourseID]; ok {
+        return models.Enrollment{}, apperror.Conflict("the student is already enrolled in this course")
+    }
+
+    now := time.Now().UTC()
+    enrollment.ID = s.nextEnrollmentID
+    enrollment.EnrolledAt = now
+    enrollment.UpdatedAt = now
+    enrollment.Course = nil
+    if err := s.commit(change{Op: opPutEnrollment, Enrollment: &enrollment}); err != nil {
+        return models.Enrollment{}, err
+    }
+    return enrollment, nil
+}
+
+// GetEnrollments lists a student's enrollments with their courses.
+func (s *Storage) GetEnrollments(studentID int) []models.Enrollment {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    enrollments := make([]models.Enrollment, 0, len(s.enrollments[studentID]))
+    for courseID, enrollment := range s.enrollments[studentID] {
+        course := s.courses[courseID]
+        enrollment.Course = &course
+        enrollments = append(enrollments, enrollment)
+    }
+    sort.Slice(enrollments, func(i, j int) bool { return enrollments[i].ID < enrollments[j].ID })
+    return enrollments
+}
+
+func (s *Storage) UpdateEnrollmentStatus(studentID, courseID int, status string) (models.Enrollment, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    enrollment, ok := s.enrollments[studentID][courseID]
+    if !ok {
+        return models.Enrollment{}, apperror.NotFound("student %d is not enrolled in course %d", studentID, courseID)
+    }
+
+    enrollment.Status = status
+    enrollment.UpdatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutEnrollment, Enrollment: &enrollment}); err != nil {
+        return models.Enrollment{}, err
+    }
+    return enrollment, nil
+}
+
+func (s *Storage) DeleteEnrollment(studentID, courseID int) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    enrollment, ok := s.enrollments[studentID][courseID]
+    if !ok {
+        return apperror.NotFound("student %d is not enrolled in course %d", studentID, courseID)
+    }
+    return s.commit(change{Op: opDeleteEnrollment, Enrollment: &enrollment})
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+    "sort"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// copyAttributes returns a private copy of a student's attributes. Stored
+// students never share their map with callers, so it can be replaced under
+// the mutex without racing with readers.
+func copyAttributes(attrs map[string]interface{}) map[string]interface{} {
+    if len(attrs) == 0 {
+        return nil
+    }
+    c := make(map[string]interface{}, len(attrs))
+    for name, value := range attrs {
+        c[name] = value
+    }
+    return c
+}
+
+func (s *Storage) CreateCustomField(field models.CustomField) (models.CustomField, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if _, ok := s.customFields[field.Name]; ok {
+        return models.CustomField{}, apperror.Conflict("a custom field with this name already exists")
+    }
+
+    now := time.Now().UTC()
+    field.ID = s.nextCustomFieldID
+    field.CreatedAt = now
+    field.UpdatedAt = now
+    if err := s.commit(change{Op: opPutCustomField, CustomField: &field}); err != nil {
+        return models.CustomField{}, err
+    }
+    return field, nil
+}
+
+// GetCustomFields lists the custom field definitions in the order they were
+// created.
+func (s *Storage) GetCustomFields() []models.CustomField {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    fields := make([]models.CustomField, 0, len(s.customFields))
+    for _, field := range s.customFields {
+        fields = append(fields, field)
+    }
+    sort.Slice(fields, func(i, j int) bool { return fields[i].ID < fields[j].ID })
+    return fields
+}
+
+func (s *Storage) GetCustomField(name string) (models.CustomField, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    field, ok := s.customFields[name]
+    if !ok {
+        return models.CustomField{}, apperror.NotFound("custom field %s not found", name)
+    }
+    return field, nil
+}
+
+// UpdateCustomField changes the label, required flag and rules of the named
+// field. Like the Postgres backend it refuses to change the type.
+func (s *Storage) UpdateCustomField(name string, field models.CustomField) (models.CustomField, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    existing, ok := s.customFields[name]
+    if !ok {
+        return models.CustomField{}, apperror.NotFound("custom field %s not found", name)
+    }
+    if field.Type != existing.Type {
+        return models.CustomField{}, apperror.Conflict("custom field %s is of type %s and its type cannot be changed", name, existing.Type)
+    }
+
+    field.ID = existing.ID
+    field.Name = name
+    field.CreatedAt = existing.CreatedAt
+    field.UpdatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutCustomField, CustomField: &field}); err != nil {
+        return models.CustomField{}, err
+    }
+    return field, nil
+}
+
+// DeleteCustomField removes a field definition and its value from every
+// student, including soft-deleted ones.
+func (s *Storage) DeleteCustomField(name string) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    field, ok := s.customFields[name]
+    if !ok {
+        return apperror.NotFound("custom field %s not found", name)
+    }
+    return s.commit(change{Op: opDeleteCustomField, CustomField: &field})
+}
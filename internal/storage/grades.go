@@ -0,0 +1,129 @@
+package storage
+
+import (
+    "sort"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// checkGrade verifies that a grade refers to a live student and an existing
+// course and does not duplicate another grade for the same course and term.
+// The caller must hold writeMutex.
+func (s *Storage) checkGrade(grade models.Grade, id int) error {
+    if student, ok := s.students.get(grade.StudentID); !ok || student.IsDeleted() {
+        return apperror.NotFound("student %d not found", grade.StudentID)
+    }
+    if _, ok := s.courses[grade.CourseID]; !ok {
+        return apperror.NotFound("course %d not found", grade.CourseID)
+    }
+    for _, g := range s.grades {
+        if g.ID != id && g.StudentID == grade.StudentID && g.CourseID == grade.CourseID && g.Term == grade.Term {
+            return apperror.Conflict("the student already has a grade for this course and term")
+        }
+    }
+    return nil
+}
+
+// withCourse returns the grade with its course filled in. The caller must
+// hold the mutex or writeMutex.
+func (s *Storage) withCourse(grade models.Grade) models.Grade {
+    course := s.courses[grade.CourseID]
+    grade.Course = &course
+    return grade
+}
+
+// deleteGradesWhere removes every grade matching fn. The caller must hold the
+// mutex.
+func (s *Storage) deleteGradesWhere(fn func(models.Grade) bool) {
+    for id, g := range s.grades {
+        if fn(g) {
+            delete(s.grades, id)
+        }
+    }
+}
+
+func (s *Storage) CreateGrade(grade models.Grade) (models.Grade, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if err := s.checkGrade(grade, 0); err != nil {
+        return models.Grade{}, err
+    }
+
+    now := time.Now().UTC()
+    grade.ID = s.nextGradeID
+    grade.CreatedAt = now
+    grade.UpdatedAt = now
+    grade.Course = nil
+    if err := s.commit(change{Op: opPutGrade, Grade: &grade}); err != nil {
+        return models.Grade{}, err
+    }
+    return grade, nil
+}
+
+// GetGrades lists a student's grades with their courses.
+func (s *Storage) GetGrades(studentID int) []models.Grade {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    grades := []models.Grade{}
+    for _, g := range s.grades {
+        if g.StudentID == studentID {
+            grades = append(grades, s.withCourse(g))
+        }
+    }
+    sort.Slice(grades, func(i, j int) bool {
+        if grades[i].Term != grades[j].Term {
+            return grades[i].Term < grades[j].Term
+        }
+        return grades[i].ID < grades[j].ID
+    })
+    return grades
+}
+
+func (s *Storage) GetGrade(studentID, id int) (models.Grade, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    grade, ok := s.grades[id]
+    if !ok || grade.StudentID != studentID {
+        return models.Grade{}, apperror.NotFound("grade %d not found", id)
+    }
+    return s.withCourse(grade), nil
+}
+
+func (s *Storage) UpdateGrade(studentID, id int, grade models.Grade) (models.Grade, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    existing, ok := s.grades[id]
+    if !ok || existing.StudentID != studentID {
+        return models.Grade{}, apperror.NotFound("grade %d not found", id)
+    }
+    grade.StudentID = studentID
+    if err := s.checkGrade(grade, id); err != nil {
+        return models.Grade{}, err
+    }
+
+    grade.ID = id
+    grade.CreatedAt = existing.CreatedAt
+    grade.UpdatedAt = time.Now().UTC()
+    grade.Course = nil
+    if err := s.commit(change{Op: opPutGrade, Grade: &grade}); err != nil {
+        return models.Grade{}, err
+    }
+    return grade, nil
+}
+
+func (s *Storage) DeleteGrade(studentID, id int) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    grade, ok := s.grades[id]
+    if !ok || grade.StudentID != studentID {
+        return apperror.NotFound("grade %d not found", id)
+    }
+    return s.commit(change{Op: opDeleteGrade, Grade: &grade})
+}
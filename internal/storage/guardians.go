@@ -0,0 +1,215 @@
+package storage
+
+import (
+    "sort"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+func (s *Storage) CreateGuardian(guardian models.Guardian) (models.Guardian, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    now := time.Now().UTC()
+    guardian.ID = s.nextGuardianID
+    guardian.CreatedAt = now
+    guardian.UpdatedAt = now
+    if err := s.commit(change{Op: opPutGuardian, Guardian: &guardian}); err != nil {
+        return models.Guardian{}, err
+    }
+    return guardian, nil
+}
+
+func (s *Storage) GetAllGuardians() []models.Guardian {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    guardians := make([]models.Guardian, 0, len(s.guardians))
+    for _, guardian := range s.guardians {
+        guardians = append(guardians, guardian)
+    }
+    sort.Slice(guardians, func(i, j int) bool { return guardians[i].ID < guardians[j].ID })
+    return guardians
+}
+
+func (s *Storage) GetGuardian(id int) (models.Guardian, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    guardian, ok := s.guardians[id]
+    if !ok {
+        return models.Guardian{}, apperror.NotFound("guardian %d not found", id)
+    }
+    return guardian, nil
+}
+
+func (s *Storage) UpdateGuardian(id int, guardian models.Guardian) (models.Guardian, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    existing, ok := s.guardians[id]
+    if !ok {
+        return models.Guardian{}, apperror.NotFound("guardian %d not found", id)
+    }
+
+    guardian.ID = id
+    guardian.CreatedAt = existing.CreatedAt
+    guardian.UpdatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutGuardian, Guardian: &guardian}); err != nil {
+        return models.Guardian{}, err
+    }
+    return guardian, nil
+}
+
+// DeleteGuardian removes a guardian and its links to students, like the
+// foreign keys of the Postgres backend.
+func (s *Storage) DeleteGuardian(id int) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    guardian, ok := s.guardians[id]
+    if !ok {
+        return apperror.NotFound("guardian %d not found", id)
+    }
+    return s.commit(change{Op: opDeleteGuardian, Guardian: &guardian})
+}
+
+// LinkGuardian links a live student to a guardian.
+func (s *Storage) LinkGuardian(link models.StudentGuardian) (models.StudentGuardian, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if student, ok := s.students.get(link.StudentID); !ok || student.IsDeleted() {
+        return models.StudentGuardian{}, apperror.NotFound("student %d not found", link.StudentID)
+    }
+    if _, ok := s.guardians[link.GuardianID]; !ok {
+        return models.StudentGuardian{}, apperror.NotFound("guardian %d not found", link.GuardianID)
+    }
+    if _, ok := s.guardianLinks[link.StudentID][link.GuardianID]; ok {
+        return models.StudentGuardian{}, apperror.Conflict("the guardian is already linked to this student")
+    }
+    if err := s.checkPrimary(link); err != nil {
+        return models.StudentGuardian{}, err
+    }
+
+    now := time.Now().UTC()
+    link.CreatedAt = now
+    link.UpdatedAt = now
+    link.Guardian = nil
+    link.Student = nil
+    if err := s.commit(change{Op: opPutGuardianLink, GuardianLink: &link}); err != nil {
+        return models.StudentGuardian{}, err
+    }
+    return link, nil
+}
+
+// checkPrimary returns a conflict if link would give its student a second
+// primary guardian. The caller must hold writeMutex.
+func (s *Storage) checkPrimary(link models.StudentGuardian) error {
+    if !link.Primary {
+        return nil
+    }
+    for guardianID, other := range s.guardianLinks[link.StudentID] {
+        if other.Primary && guardianID != link.GuardianID {
+            return apperror.Conflict("the student already has a primary guardian")
+        }
+    }
+    return nil
+}
+
+// GetStudentGuardians lists a student's guardians, primary first.
+func (s *Storage) GetStudentGuardians(studentID int) []models.StudentGuardian {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    links := make([]models.StudentGuardian, 0, len(s.guardianLinks[studentID]))
+    for guardianID, link := range s.guardianLinks[studentID] {
+        guardian := s.guardians[guardianID]
+        link.Guardian = &guardian
+        links = append(links, link)
+    }
+    sort.Slice(links, func(i, j int) bool {
+        if links[i].Primary != links[j].Primary {
+            return links[i].Primary
+        }
+        if !links[i].CreatedAt.Equal(links[j].CreatedAt) {
+            return links[i].CreatedAt.Before(links[j].CreatedAt)
+        }
+        return links[i].GuardianID < links[j].GuardianID
+    })
+    return links
+}
+
+// GetStudentGuardian returns the link between a student and a guardian,
+// with the guardian.
+func (s *Storage) GetStudentGuardian(studentID, guardianID int) (models.StudentGuardian, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    link, ok := s.guardianLinks[studentID][guardianID]
+    if !ok {
+        return models.StudentGuardian{}, apperror.NotFound("guardian %d is not linked to student %d", guardianID, studentID)
+    }
+    guardian := s.guardians[guardianID]
+    link.Guardian = &guardian
+    return link, nil
+}
+
+// GetGuardianStudents lists the live students a guardian is linked to,
+// among those assigned to advisor if it is set.
+func (s *Storage) GetGuardianStudents(guardianID int, advisor string) []models.StudentGuardian {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    links := []models.StudentGuardian{}
+    for studentID, studentLinks := range s.guardianLinks {
+        link, ok := studentLinks[guardianID]
+        student, _ := s.students.get(studentID)
+        if !ok || student.IsDeleted() {
+            continue
+        }
+        if _, ok := s.advisees[advisor][studentID]; advisor != "" && !ok {
+            continue
+        }
+        link.Student = &student
+        links = append(links, link)
+    }
+    sort.Slice(links, func(i, j int) bool { return links[i].StudentID < links[j].StudentID })
+    return links
+}
+
+// UpdateGuardianLink changes the relationship and primary flag of the link
+// between a student and a guardian.
+func (s *Storage) UpdateGuardianLink(link models.StudentGuardian) (models.StudentGuardian, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    existing, ok := s.guardianLinks[link.StudentID][link.GuardianID]
+    if !ok {
+        return models.StudentGuardian{}, apperror.NotFound("guardian %d is not linked to student %d", link.GuardianID, link.StudentID)
+    }
+    if err := s.checkPrimary(link); err != nil {
+        return models.StudentGuardian{}, err
+    }
+
+    existing.Relationship = link.Relationship
+    existing.Primary = link.Primary
+    existing.UpdatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutGuardianLink, GuardianLink: &existing}); err != nil {
+        return models.StudentGuardian{}, err
+    }
+    return existing, nil
+}
+
+func (s *Storage) UnlinkGuardian(studentID, guardianID int) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    link, ok := s.guardianLinks[studentID][guardianID]
+    if !ok {
+        return apperror.NotFound("guardian %d is not linked to student %d", guardianID, studentID)
+    }
+    return s.commit(change{Op: opDeleteGuardianLink, GuardianLink: &link})
+}
@@ -0,0 +1,363 @@
+package storage
+
+import (
+    "iter"
+    "maps"
+    "math"
+    "slices"
+    "sort"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// maxBlockSize bounds the blocks of an orderedIndex: changing the index
+// moves at most this many keys, and finding a key is a binary search over
+// the blocks followed by one within a block.
+const maxBlockSize = 512
+
+// orderedIndex is a sorted set of keys, kept in blocks of up to
+// maxBlockSize so that it stays cheap to change as it grows.
+type orderedIndex struct {
+    blocks [][]models.SortKey
+    len    int
+}
+
+// find returns the position of the first key that is not less than k: a
+// block and an index within it, which is the block's length if k is
+// greater than every key.
+func (ix *orderedIndex) find(k models.SortKey) (int, int) {
+    if len(ix.blocks) == 0 {
+        return 0, 0
+    }
+    b := sort.Search(len(ix.blocks), func(b int) bool {
+        block := ix.blocks[b]
+        return block[len(block)-1].Compare(k) >= 0
+    })
+    if b == len(ix.blocks) {
+        return b - 1, len(ix.blocks[b-1])
+    }
+    block := ix.blocks[b]
+    return b, sort.Search(len(block), func(i int) bool { return block[i].Compare(k) >= 0 })
+}
+
+func (ix *orderedIndex) insert(k models.SortKey) {
+    if len(ix.blocks) == 0 {
+        ix.blocks = [][]models.SortKey{{k}}
+        ix.len = 1
+        return
+    }
+    b, i := ix.find(k)
+    block := ix.blocks[b]
+    if i < len(block) && block[i] == k {
+        return
+    }
+    block = slices.Insert(block, i, k)
+    ix.len++
+    if len(block) > maxBlockSize {
+        half := len(block) / 2
+        tail := slices.Clone(block[half:])
+        ix.blocks = slices.Insert(ix.blocks, b+1, tail)
+        block = block[:half]
+    }
+    ix.blocks[b] = block
+}
+
+func (ix *orderedIndex) delete(k models.SortKey) {
+    b, i := ix.find(k)
+    if b == len(ix.blocks) || i == len(ix.blocks[b]) || ix.blocks[b][i] != k {
+        return
+    }
+    block := slices.Delete(ix.blocks[b], i, i+1)
+    ix.len--
+    switch {
+    case len(block) == 0:
+        ix.blocks = slices.Delete(ix.blocks, b, b+1)
+        return
+    case len(block) < maxBlockSize/4 && b+1 < len(ix.blocks) && len(block)+len(ix.blocks[b+1]) <= maxBlockSize:
+        // Merge small blocks so that the number of blocks follows the
+        // number of keys down as well as up.
+        block = append(block, ix.blocks[b+1]...)
+        ix.blocks = slices.Delete(ix.blocks, b+1, b+2)
+    }
+    ix.blocks[b] = block
+}
+
+// rank returns how many keys are less than k.
+func (ix *orderedIndex) rank(k models.SortKey) int {
+    b, n := ix.find(k)
+    for _, block := range ix.blocks[:b] {
+        n += len(block)
+    }
+    return n
+}
+
+// ascend calls fn with the keys from the first that is not less than from,
+// or from the first key if from is nil, in order, until fn returns false.
+func (ix *orderedIndex) ascend(from *models.SortKey, fn func(models.SortKey) bool) {
+    b, i := 0, 0
+    if from != nil {
+        b, i = ix.find(*from)
+    }
+    for ; b < len(ix.blocks); b, i = b+1, 0 {
+        for _, k := range ix.blocks[b][i:] {
+            if !fn(k) {
+                return
+            }
+        }
+    }
+}
+
+// descend calls fn with the keys less than before, or with every key if
+// before is nil, in reverse order, until fn returns false.
+func (ix *orderedIndex) descend(before *models.SortKey, fn func(models.SortKey) bool) {
+    if len(ix.blocks) == 0 {
+        return
+    }
+    b := len(ix.blocks) - 1
+    i := len(ix.blocks[b])
+    if before != nil {
+        b, i = ix.find(*before)
+    }
+    for {
+        for j := i - 1; j >= 0; j-- {
+            if !fn(ix.blocks[b][j]) {
+                return
+            }
+        }
+        if b == 0 {
+            return
+        }
+        b--
+        i = len(ix.blocks[b])
+    }
+}
+
+// keyRange is the keys from lo, inclusive, to hi, exclusive. A nil bound
+// leaves that end open.
+type keyRange struct {
+    lo, hi *models.SortKey
+}
+
+func (r keyRange) count(ix *orderedIndex) int {
+    lo, hi := 0, ix.len
+    if r.lo != nil {
+        lo = ix.rank(*r.lo)
+    }
+    if r.hi != nil {
+        hi = ix.rank(*r.hi)
+    }
+    return max(0, hi-lo)
+}
+
+// studentRanges returns the range of keys the filter allows in the index of
+// each field it bounds.
+func studentRanges(filter models.StudentFilter) map[string]keyRange {
+    ranges := make(map[string]keyRange)
+    if filter.MinAge != nil || filter.MaxAge != nil {
+        var r keyRange
+        if filter.MinAge != nil {
+            r.lo = &models.SortKey{Num: *filter.MinAge, ID: math.MinInt}
+        }
+        if filter.MaxAge != nil && *filter.MaxAge < math.MaxInt {
+            r.hi = &models.SortKey{Num: *filter.MaxAge + 1, ID: math.MinInt}
+        }
+        ranges[models.SortByAge] = r
+    }
+    if filter.NamePrefix != "" {
+        ranges[models.SortByName] = prefixRange(filter.NamePrefix)
+    }
+    if filter.EmailPrefix != "" {
+        ranges[models.SortByEmail] = prefixRange(filter.EmailPrefix)
+    }
+    return ranges
+}
+
+// prefixRange returns the range of the strings that start with prefix.
+func prefixRange(prefix string) keyRange {
+    r := keyRange{lo: &models.SortKey{Str: prefix, ID: math.MinInt}}
+    end := []byte(prefix)
+    for len(end) > 0 && end[len(end)-1] == 0xff {
+        end = end[:len(end)-1]
+    }
+    if len(end) > 0 {
+        end[len(end)-1]++
+        r.hi = &models.SortKey{Str: string(end), ID: math.MinInt}
+    }
+    return r
+}
+
+// indexSorted adds a student to the sort indexes. The caller must hold the
+// mutex.
+func (s *Storage) indexSorted(student models.Student) {
+    for field, ix := range s.sortIndexes {
+        ix.insert(student.SortKey(field))
+    }
+}
+
+// unindexSorted removes a student from the sort indexes. The caller must
+// hold the mutex.
+func (s *Storage) unindexSorted(student models.Student) {
+    for field, ix := range s.sortIndexes {
+        ix.delete(student.SortKey(field))
+    }
+}
+
+// List returns a page of the students that match the filter, and the
+// cursor for the next page, which is nil on the last one. It walks the
+// index of the sort field, stopping once the page is full, unless a range
+// filter on another field, or the advisor's students, leave fewer students
+// to look at; then it reads those and sorts what matches.
+func (s *Storage) List(filter models.StudentFilter, page models.StudentPage) ([]models.Student, *models.StudentCursor) {
+    if page.Sort == "" {
+        page.Sort = models.SortByID
+    }
+    want := 0
+    if page.Limit > 0 {
+        // One more than the page holds tells whether there is another.
+        want = page.Limit + 1
+    }
+
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    ranges := studentRanges(filter)
+    scanField, scanCost := page.Sort, ranges[page.Sort].count(s.sortIndexes[page.Sort])
+    for field, r := range ranges {
+        if field == page.Sort {
+            continue
+        }
+        n := r.count(s.sortIndexes[field])
+        // Walking the sort index until the page is full reads about
+        // want/selectivity keys if matches are spread evenly.
+        cost := scanCost
+        if want > 0 && n > 0 {
+            cost = min(cost, want*s.students.len()/n)
+        }
+        if n < cost {
+            scanField, scanCost = field, n
+        }
+    }
+
+    readAdvisees := false
+    if filter.Advisor != "" {
+        n := len(s.advisees[filter.Advisor])
+        cost := scanCost
+        if want > 0 && n > 0 {
+            cost = min(cost, want*s.students.len()/n)
+        }
+        readAdvisees = n < cost
+    }
+
+    var students []models.Student
+    switch {
+    case readAdvisees:
+        students = s.readCandidates(filter, page, maps.Keys(s.advisees[filter.Advisor]), want)
+    case scanField == page.Sort:
+        students = s.walkSorted(filter, page, ranges[page.Sort], want)
+    default:
+        students = s.readCandidates(filter, page, s.indexRange(scanField, ranges[scanField]), want)
+    }
+
+    if page.Limit > 0 && len(students) > page.Limit {
+        students = students[:page.Limit]
+        return students, page.NextCursor(students[page.Limit-1])
+    }
+    if students == nil {
+        students = []models.Student{}
+    }
+    return students, nil
+}
+
+// walkSorted collects up to want students (all if 0) matching the filter by
+// walking the sort field's index from the page's cursor. The caller must
+// hold the mutex.
+func (s *Storage) walkSorted(filter models.StudentFilter, page models.StudentPage, r keyRange, want int) []models.Student {
+    var students []models.Student
+    visit := func(k models.SortKey) bool {
+        if student, _ := s.students.get(k.ID); s.matches(filter, student) {
+            students = append(students, student)
+        }
+        return want == 0 || len(students) < want
+    }
+
+    ix := s.sortIndexes[page.Sort]
+    if page.Desc {
+        before := r.hi
+        if page.After != nil && (before == nil || page.After.Key.Compare(*before) < 0) {
+            before = &page.After.Key
+        }
+        ix.descend(before, func(k models.SortKey) bool {
+            return (r.lo == nil || k.Compare(*r.lo) >= 0) && visit(k)
+        })
+        return students
+    }
+
+    from := r.lo
+    if page.After != nil && (from == nil || page.After.Key.Compare(*from) > 0) {
+        from = &page.After.Key
+    }
+    ix.ascend(from, func(k models.SortKey) bool {
+        if page.After != nil && k == page.After.Key {
+            return true
+        }
+        return (r.hi == nil || k.Compare(*r.hi) < 0) && visit(k)
+    })
+    return students
+}
+
+// indexRange returns the IDs in a range of a field's index. The caller must
+// hold the mutex.
+func (s *Storage) indexRange(field string, r keyRange) iter.Seq[int] {
+    return func(yield func(int) bool) {
+        s.sortIndexes[field].ascend(r.lo, func(k models.SortKey) bool {
+            return (r.hi == nil || k.Compare(*r.hi) < 0) && yield(k.ID)
+        })
+    }
+}
+
+// readCandidates collects the candidate students that match the filter,
+// sorts them and keeps the first want (all if 0) after the page's cursor.
+// The caller must hold the mutex.
+func (s *Storage) readCandidates(filter models.StudentFilter, page models.StudentPage, candidates iter.Seq[int], want int) []models.Student {
+    type sorted struct {
+        key     models.SortKey
+        student models.Student
+    }
+    var matches []sorted
+    for id := range candidates {
+        student, _ := s.students.get(id)
+        if !s.matches(filter, student) {
+            continue
+        }
+        key := student.SortKey(page.Sort)
+        if page.After == nil || page.Before(page.After.Key, key) {
+            matches = append(matches, sorted{key, student})
+        }
+    }
+    slices.SortFunc(matches, func(a, b sorted) int {
+        if page.Desc {
+            return b.key.Compare(a.key)
+        }
+        return a.key.Compare(b.key)
+    })
+    if want > 0 && len(matches) > want {
+        matches = matches[:want]
+    }
+    students := make([]models.Student, len(matches))
+    for i, m := range matches {
+        students[i] = m.student
+    }
+    return students
+}
+
+// matches reports whether the student belongs in a listing with the filter,
+// which Matches cannot tell alone if it has an advisor. The caller must hold
+// the mutex.
+func (s *Storage) matches(filter models.StudentFilter, student models.Student) bool {
+    if filter.Advisor != "" {
+        if _, ok := s.advisees[filter.Advisor][student.ID]; !ok {
+            return false
+        }
+    }
+    return filter.Matches(student)
+}
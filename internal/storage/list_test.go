@@ -0,0 +1,21 @@
+package storage
+
+import (
+    "fmt"
+    "testing"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/backendtest"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+func TestListPagesThroughEveryRange(t *testing.T) {
+    s := NewStorage()
+    // Enough other students for range filters to be worth reading through
+    // their own index rather than the sort field's.
+    for i := 0; i < 100; i++ {
+        if _, err := s.Create(models.Student{Name: "Other", Age: 50, Email: fmt.Sprintf("other%d@example.com", i)}, Actor{}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    backendtest.List(t, backend{s})
+}
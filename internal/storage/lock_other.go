@@ -0,0 +1,17 @@
+//go:build !unix
+
+package storage
+
+import (
+    "os"
+    "path/filepath"
+)
+
+// lockFile is created in the storage's directory, as on Unix.
+const lockFile = "LOCK"
+
+// lockDir only opens dir's lock file: there is no flock here, so nothing
+// keeps a second process out of the directory.
+func lockDir(dir string) (*os.File, error) {
+    return os.OpenFile(filepath.Join(dir, lockFile), os.O_RDWR|os.O_CREATE, 0o644)
+}
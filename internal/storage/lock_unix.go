@@ -0,0 +1,31 @@
+//go:build unix
+
+package storage
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "syscall"
+)
+
+// lockFile is locked by the storage that has its directory open.
+const lockFile = "LOCK"
+
+// lockDir takes an exclusive lock on dir, failing at once if it is held. The
+// lock goes away with the returned file, so a crashed process releases it.
+func lockDir(dir string) (*os.File, error) {
+    f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_RDWR|os.O_CREATE, 0o644)
+    if err != nil {
+        return nil, err
+    }
+    if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+        f.Close()
+        if errors.Is(err, syscall.EWOULDBLOCK) {
+            return nil, fmt.Errorf("%s is in use by another storage", dir)
+        }
+        return nil, fmt.Errorf("locking %s: %w", dir, err)
+    }
+    return f, nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+    "sort"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// CreateNote adds a note to a live student.
+func (s *Storage) CreateNote(note models.Note) (models.Note, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if student, ok := s.students.get(note.StudentID); !ok || student.IsDeleted() {
+        return models.Note{}, apperror.NotFound("student %d not found", note.StudentID)
+    }
+
+    now := time.Now().UTC()
+    note.ID = s.nextNoteID
+    note.CreatedAt = now
+    note.UpdatedAt = now
+    if err := s.commit(change{Op: opPutNote, Note: &note}); err != nil {
+        return models.Note{}, err
+    }
+    return note, nil
+}
+
+// GetNotes lists a student's notes, oldest first.
+func (s *Storage) GetNotes(studentID int) []models.Note {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    notes := []models.Note{}
+    for _, note := range s.notes {
+        if note.StudentID == studentID {
+            notes = append(notes, note)
+        }
+    }
+    sort.Slice(notes, func(i, j int) bool { return notes[i].ID < notes[j].ID })
+    return notes
+}
+
+func (s *Storage) GetNote(studentID, id int) (models.Note, error) {
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    note, ok := s.notes[id]
+    if !ok || note.StudentID != studentID {
+        return models.Note{}, apperror.NotFound("note %d not found", id)
+    }
+    return note, nil
+}
+
+// UpdateNote changes the note's body, keeping its original author.
+func (s *Storage) UpdateNote(studentID, id int, body string) (models.Note, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    note, ok := s.notes[id]
+    if !ok || note.StudentID != studentID {
+        return models.Note{}, apperror.NotFound("note %d not found", id)
+    }
+
+    note.Body = body
+    note.UpdatedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutNote, Note: &note}); err != nil {
+        return models.Note{}, err
+    }
+    return note, nil
+}
+
+func (s *Storage) DeleteNote(studentID, id int) error {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    note, ok := s.notes[id]
+    if !ok || note.StudentID != studentID {
+        return apperror.NotFound("note %d not found", id)
+    }
+    return s.commit(change{Op: opDeleteNote, Note: &note})
+}
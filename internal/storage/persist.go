@@ -0,0 +1,383 @@
+package storage
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// snapshotFile holds the storage as of a point in the write-ahead log.
+const snapshotFile = "snapshot.json"
+
+// Options configure a storage kept on disk.
+type Options struct {
+    // Dir holds the snapshot and the write-ahead log. It is created if it
+    // does not exist. Open locks it, so only one process may use it at a
+    // time.
+    Dir string
+    // Fsync defaults to FsyncAlways.
+    Fsync FsyncPolicy
+    // FsyncInterval is how often the log is flushed with FsyncInterval. It
+    // defaults to one second.
+    FsyncInterval time.Duration
+}
+
+// snapshot is the content of the snapshot file.
+type snapshot struct {
+    // Seq is the last entry of the write-ahead log the snapshot includes.
+    Seq uint64 `json:"seq"`
+    // NextIDs keeps IDs from being reused after the records holding the
+    // highest ones are deleted.
+    NextIDs       nextIDs                   `json:"next_ids"`
+    Students      []models.Student          `json:"students"`
+    Courses       []models.Course           `json:"courses"`
+    Enrollments   []models.Enrollment       `json:"enrollments"`
+    Grades        []models.Grade            `json:"grades"`
+    Attendance    []models.AttendanceRecord `json:"attendance"`
+    Notes         []models.Note             `json:"notes"`
+    Guardians     []models.Guardian         `json:"guardians"`
+    GuardianLinks []models.StudentGuardian  `json:"guardian_links"`
+    CustomFields  []models.CustomField      `json:"custom_fields"`
+    Summaries     []models.StudentSummary   `json:"summaries"`
+    APIKeys       []apiKey                  `json:"api_keys"`
+    Advisors      []models.StudentAdvisor   `json:"advisors"`
+    Audit         []models.AuditEntry       `json:"audit"`
+}
+
+type nextIDs struct {
+    Student     int   `json:"student"`
+    Course      int   `json:"course"`
+    Enrollment  int   `json:"enrollment"`
+    Grade       int   `json:"grade"`
+    Attendance  int   `json:"attendance"`
+    Note        int   `json:"note"`
+    Guardian    int   `json:"guardian"`
+    CustomField int   `json:"custom_field"`
+    APIKey      int   `json:"api_key"`
+    Audit       int64 `json:"audit"`
+}
+
+// Open returns a storage kept on disk in opts.Dir, recovering what was
+// written there before: the latest snapshot and then every entry of the
+// write-ahead log after it. An entry torn by a crash at the end of the log
+// is discarded; it was never acknowledged. Open fails if another storage,
+// in this process or another, has the directory open. Close the storage to
+// flush it and release the directory.
+func Open(opts Options) (*Storage, error) {
+    if opts.Fsync == "" {
+        opts.Fsync = FsyncAlways
+    }
+    if opts.FsyncInterval <= 0 {
+        opts.FsyncInterval = time.Second
+    }
+    if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+        return nil, err
+    }
+    lock, err := lockDir(opts.Dir)
+    if err != nil {
+        return nil, err
+    }
+    s, err := recoverDir(opts)
+    if err != nil {
+        lock.Close()
+        return nil, err
+    }
+    s.lock = lock
+    return s, nil
+}
+
+// recoverDir loads the snapshot and replays the log in opts.Dir.
+func recoverDir(opts Options) (*Storage, error) {
+    s := NewStorage()
+    s.dir = opts.Dir
+    seq, err := s.loadSnapshot()
+    if err != nil {
+        return nil, fmt.Errorf("loading %s: %w", snapshotFile, err)
+    }
+    s.snapshotSeq = seq
+
+    firsts, err := segments(opts.Dir)
+    if err != nil {
+        return nil, err
+    }
+    replayed := 0
+    for i, first := range firsts {
+        path := filepath.Join(opts.Dir, segmentName(first))
+        size, torn, err := readSegment(path, func(e entry) error {
+            if e.Seq <= seq {
+                return nil
+            }
+            if e.Seq != seq+1 {
+                return fmt.Errorf("entry %d follows entry %d", e.Seq, seq)
+            }
+            for _, c := range e.Changes {
+                if err := c.validate(); err != nil {
+                    return fmt.Errorf("entry %d: %w", e.Seq, err)
+                }
+            }
+            for _, c := range e.Changes {
+                s.apply(c)
+            }
+            seq = e.Seq
+            replayed++
+            return nil
+        })
+        if err != nil {
+            return nil, fmt.Errorf("replaying %s: %w", path, err)
+        }
+        if torn {
+            if i < len(firsts)-1 {
+                return nil, fmt.Errorf("replaying %s: the segment is corrupt", path)
+            }
+            log.Printf("Discarding an incomplete entry at the end of %s", path)
+            if err := os.Truncate(path, size); err != nil {
+                return nil, err
+            }
+        }
+    }
+    log.Printf("Recovered storage from %s: snapshot at entry %d, %d entries replayed", opts.Dir, s.snapshotSeq, replayed)
+
+    if s.wal, err = openWAL(opts.Dir, seq, opts.Fsync, opts.FsyncInterval); err != nil {
+        return nil, err
+    }
+    return s, nil
+}
+
+// loadSnapshot loads the snapshot file, if there is one, and returns the
+// last entry of the write-ahead log it includes.
+func (s *Storage) loadSnapshot() (uint64, error) {
+    f, err := os.Open(filepath.Join(s.dir, snapshotFile))
+    if os.IsNotExist(err) {
+        return 0, nil
+    }
+    if err != nil {
+        return 0, err
+    }
+    defer f.Close()
+
+    var snap snapshot
+    if err := json.NewDecoder(bufio.NewReader(f)).Decode(&snap); err != nil {
+        return 0, err
+    }
+    for i := range snap.Students {
+        s.apply(change{Op: opPutStudent, Student: &snap.Students[i]})
+    }
+    for i := range snap.Courses {
+        s.apply(change{Op: opPutCourse, Course: &snap.Courses[i]})
+    }
+    for i := range snap.Enrollments {
+        s.apply(change{Op: opPutEnrollment, Enrollment: &snap.Enrollments[i]})
+    }
+    for i := range snap.Grades {
+        s.apply(change{Op: opPutGrade, Grade: &snap.Grades[i]})
+    }
+    for i := range snap.Attendance {
+        s.apply(change{Op: opPutAttendance, Attendance: &snap.Attendance[i]})
+    }
+    for i := range snap.Notes {
+        s.apply(change{Op: opPutNote, Note: &snap.Notes[i]})
+    }
+    for i := range snap.Guardians {
+        s.apply(change{Op: opPutGuardian, Guardian: &snap.Guardians[i]})
+    }
+    for i := range snap.GuardianLinks {
+        s.apply(change{Op: opPutGuardianLink, GuardianLink: &snap.GuardianLinks[i]})
+    }
+    for i := range snap.CustomFields {
+        s.apply(change{Op: opPutCustomField, CustomField: &snap.CustomFields[i]})
+    }
+    for i := range snap.Summaries {
+        s.apply(change{Op: opPutSummary, Summary: &snap.Summaries[i]})
+    }
+    for i := range snap.APIKeys {
+        s.apply(change{Op: opPutAPIKey, APIKey: &snap.APIKeys[i]})
+    }
+    for i := range snap.Advisors {
+        s.apply(change{Op: opPutAdvisor, Advisor: &snap.Advisors[i]})
+    }
+    for i := range snap.Audit {
+        s.apply(change{Op: opAppendAudit, Audit: &snap.Audit[i]})
+    }
+
+    s.nextID = max(s.nextID, snap.NextIDs.Student)
+    s.nextCourseID = max(s.nextCourseID, snap.NextIDs.Course)
+    s.nextEnrollmentID = max(s.nextEnrollmentID, snap.NextIDs.Enrollment)
+    s.nextGradeID = max(s.nextGradeID, snap.NextIDs.Grade)
+    s.nextAttendanceID = max(s.nextAttendanceID, snap.NextIDs.Attendance)
+    s.nextNoteID = max(s.nextNoteID, snap.NextIDs.Note)
+    s.nextGuardianID = max(s.nextGuardianID, snap.NextIDs.Guardian)
+    s.nextCustomFieldID = max(s.nextCustomFieldID, snap.NextIDs.CustomField)
+    s.nextAPIKeyID = max(s.nextAPIKeyID, snap.NextIDs.APIKey)
+    s.nextAuditID = max(s.nextAuditID, snap.NextIDs.Audit)
+    return snap.Seq, nil
+}
+
+// Snapshot compacts the write-ahead log: it writes the whole storage to the
+// snapshot file and removes the log segments the snapshot covers. Writes
+// are only blocked while the storage is copied, not while it is written
+// out, and reads are not blocked at all. Snapshot does nothing for a
+// storage that is not kept on disk, or if nothing has changed since the
+// last snapshot.
+func (s *Storage) Snapshot() error {
+    if s.wal == nil {
+        return nil
+    }
+    s.snapshotMutex.Lock()
+    defer s.snapshotMutex.Unlock()
+
+    // Writes log their changes before applying them, so only writeMutex
+    // keeps the log and the storage at the same point.
+    s.writeMutex.Lock()
+    if s.wal.seq == s.snapshotSeq {
+        s.writeMutex.Unlock()
+        return nil
+    }
+    snap := s.snapshot()
+    // Later writes go to a new segment, so that the old ones only hold
+    // entries the snapshot includes.
+    seq, err := s.wal.rotate()
+    s.writeMutex.Unlock()
+    if err != nil {
+        return err
+    }
+    snap.Seq = seq
+
+    if err := writeSnapshot(s.dir, snap); err != nil {
+        return err
+    }
+    s.snapshotSeq = seq
+    return removeSegments(s.dir, seq)
+}
+
+// snapshot copies the storage. Attribute maps are shared, since they are
+// never changed in place. The caller must hold the mutex or writeMutex.
+func (s *Storage) snapshot() snapshot {
+    snap := snapshot{
+        NextIDs: nextIDs{
+            Student:     s.nextID,
+            Course:      s.nextCourseID,
+            Enrollment:  s.nextEnrollmentID,
+            Grade:       s.nextGradeID,
+            Attendance:  s.nextAttendanceID,
+            Note:        s.nextNoteID,
+            Guardian:    s.nextGuardianID,
+            CustomField: s.nextCustomFieldID,
+            APIKey:      s.nextAPIKeyID,
+            Audit:       s.nextAuditID,
+        },
+        Students:      make([]models.Student, 0, s.students.len()),
+        Courses:       make([]models.Course, 0, len(s.courses)),
+        Enrollments:   []models.Enrollment{},
+        Grades:        make([]models.Grade, 0, len(s.grades)),
+        Attendance:    []models.AttendanceRecord{},
+        Notes:         make([]models.Note, 0, len(s.notes)),
+        Guardians:     make([]models.Guardian, 0, len(s.guardians)),
+        GuardianLinks: []models.StudentGuardian{},
+        CustomFields:  make([]models.CustomField, 0, len(s.customFields)),
+        Summaries:     make([]models.StudentSummary, 0, len(s.summaries)),
+        APIKeys:       make([]apiKey, 0, len(s.apiKeys)),
+        Advisors:      []models.StudentAdvisor{},
+        Audit:         []models.AuditEntry{},
+    }
+    s.students.each(func(_ int, student models.Student) {
+        snap.Students = append(snap.Students, student)
+    })
+    for _, course := range s.courses {
+        snap.Courses = append(snap.Courses, course)
+    }
+    for _, byCourse := range s.enrollments {
+        for _, enrollment := range byCourse {
+            snap.Enrollments = append(snap.Enrollments, enrollment)
+        }
+    }
+    for _, grade := range s.grades {
+        snap.Grades = append(snap.Grades, grade)
+    }
+    for _, byDate := range s.attendance {
+        for _, record := range byDate {
+            snap.Attendance = append(snap.Attendance, record)
+        }
+    }
+    for _, note := range s.notes {
+        snap.Notes = append(snap.Notes, note)
+    }
+    for _, guardian := range s.guardians {
+        snap.Guardians = append(snap.Guardians, guardian)
+    }
+    for _, links := range s.guardianLinks {
+        for _, link := range links {
+            snap.GuardianLinks = append(snap.GuardianLinks, link)
+        }
+    }
+    for _, field := range s.customFields {
+        snap.CustomFields = append(snap.CustomFields, field)
+    }
+    for _, summary := range s.summaries {
+        snap.Summaries = append(snap.Summaries, summary)
+    }
+    for _, key := range s.apiKeys {
+        snap.APIKeys = append(snap.APIKeys, key)
+    }
+    for _, byAdvisor := range s.advisors {
+        for _, a := range byAdvisor {
+            snap.Advisors = append(snap.Advisors, a)
+        }
+    }
+    // Each student's entries stay in order, which is all History and AsOf
+    // rely on.
+    for _, entries := range s.audit {
+        snap.Audit = append(snap.Audit, entries...)
+    }
+    return snap
+}
+
+// writeSnapshot replaces the snapshot file atomically: the new one is
+// written and flushed under a temporary name and then renamed over it.
+func writeSnapshot(dir string, snap snapshot) error {
+    tmp := filepath.Join(dir, snapshotFile+".tmp")
+    f, err := os.Create(tmp)
+    if err != nil {
+        return err
+    }
+    w := bufio.NewWriter(f)
+    err = json.NewEncoder(w).Encode(snap)
+    if err == nil {
+        err = w.Flush()
+    }
+    if err == nil {
+        err = f.Sync()
+    }
+    if cerr := f.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        os.Remove(tmp)
+        return err
+    }
+    if err := os.Rename(tmp, filepath.Join(dir, snapshotFile)); err != nil {
+        return err
+    }
+    return syncDir(dir)
+}
+
+// Close takes a final snapshot, closes the write-ahead log of a storage
+// kept on disk and releases its directory. Writes fail after Close.
+func (s *Storage) Close() error {
+    if s.wal == nil {
+        return nil
+    }
+    err := s.Snapshot()
+    if cerr := s.wal.close(); err == nil {
+        err = cerr
+    }
+    if cerr := s.lock.Close(); err == nil {
+        err = cerr
+    }
+    return err
+}
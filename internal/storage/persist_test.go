@@ -0,0 +1,183 @@
+package storage
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+func openTest(t *testing.T, dir string) *Storage {
+    t.Helper()
+    s, err := Open(Options{Dir: dir, Fsync: FsyncNever})
+    if err != nil {
+        t.Fatal(err)
+    }
+    return s
+}
+
+// crash closes the write-ahead log without the final snapshot Close takes,
+// leaving the directory as a killed server would, lock released.
+func crash(t *testing.T, s *Storage) {
+    t.Helper()
+    if err := s.wal.close(); err != nil {
+        t.Fatal(err)
+    }
+    if err := s.lock.Close(); err != nil {
+        t.Fatal(err)
+    }
+}
+
+// lastSegment returns the path of the newest segment of the log in dir.
+func lastSegment(t *testing.T, dir string) string {
+    t.Helper()
+    firsts, err := segments(dir)
+    if err != nil || len(firsts) == 0 {
+        t.Fatalf("no segments in %s: %v", dir, err)
+    }
+    return filepath.Join(dir, segmentName(firsts[len(firsts)-1]))
+}
+
+func checkHistory(t *testing.T, s *Storage, id int, want ...models.AuditAction) {
+    t.Helper()
+    history := s.History(id)
+    if len(history) != len(want) {
+        t.Fatalf("student %d has %d audit entries, want %d: %+v", id, len(history), len(want), history)
+    }
+    for i, e := range history {
+        if e.Action != want[i] || e.Actor != "alice" || e.RequestID != "req" {
+            t.Errorf("entry %d of student %d is %s by %q in %q, want %s by alice in req", i, id, e.Action, e.Actor, e.RequestID, want[i])
+        }
+        if i > 0 && e.ID <= history[i-1].ID {
+            t.Errorf("entry %d of student %d has ID %d after %d", i, id, e.ID, history[i-1].ID)
+        }
+    }
+}
+
+var alice = Actor{Name: "alice", RequestID: "req"}
+
+func TestOpenRecoversSnapshotAndLog(t *testing.T) {
+    dir := t.TempDir()
+    s := openTest(t, dir)
+    a, err := s.Create(models.Student{Name: "Ada", Age: 20, Email: "ada@example.com"}, alice)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, _, err := s.Update(a.ID, models.Student{Name: "Ada L", Age: 21, Email: "ada@example.com"}, alice); err != nil {
+        t.Fatal(err)
+    }
+    if err := s.Snapshot(); err != nil {
+        t.Fatal(err)
+    }
+    // These are only in the log.
+    if _, err := s.Delete(a.ID, alice); err != nil {
+        t.Fatal(err)
+    }
+    b, err := s.Create(models.Student{Name: "Bob", Age: 30, Email: "bob@example.com"}, alice)
+    if err != nil {
+        t.Fatal(err)
+    }
+    crash(t, s)
+
+    s = openTest(t, dir)
+    defer s.Close()
+    if got, err := s.GetIncludingDeleted(a.ID); err != nil || !got.IsDeleted() || got.Name != "Ada L" {
+        t.Errorf("expected Ada L deleted, got %+v, %v", got, err)
+    }
+    if _, err := s.GetByID(b.ID); err != nil {
+        t.Error(err)
+    }
+    checkHistory(t, s, a.ID, models.AuditCreate, models.AuditUpdate, models.AuditDelete)
+    checkHistory(t, s, b.ID, models.AuditCreate)
+    if before := s.History(a.ID)[1].Before; before == nil || before.Name != "Ada" {
+        t.Errorf("expected the update to record Ada before it, got %+v", before)
+    }
+
+    // Audit IDs carry on from where they were.
+    if _, _, err := s.Update(b.ID, models.Student{Name: "Bobby", Age: 30, Email: "bob@example.com"}, alice); err != nil {
+        t.Fatal(err)
+    }
+    if history := s.History(b.ID); history[1].ID != 5 {
+        t.Errorf("expected audit entry 5, got %d", history[1].ID)
+    }
+}
+
+func TestOpenDiscardsDamagedFinalEntry(t *testing.T) {
+    for name, damage := range map[string]func(path string, size int64) error{
+        "torn": func(path string, size int64) error {
+            return os.Truncate(path, size-5)
+        },
+        "corrupt": func(path string, size int64) error {
+            f, err := os.OpenFile(path, os.O_RDWR, 0)
+            if err != nil {
+                return err
+            }
+            defer f.Close()
+            b := make([]byte, 1)
+            if _, err := f.ReadAt(b, size-5); err != nil {
+                return err
+            }
+            b[0] ^= 0xff
+            _, err = f.WriteAt(b, size-5)
+            return err
+        },
+    } {
+        t.Run(name, func(t *testing.T) {
+            dir := t.TempDir()
+            s := openTest(t, dir)
+            a, err := s.Create(models.Student{Name: "Ada", Age: 20, Email: "ada@example.com"}, alice)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if _, _, err := s.Update(a.ID, models.Student{Name: "Ada L", Age: 21, Email: "ada@example.com"}, alice); err != nil {
+                t.Fatal(err)
+            }
+            crash(t, s)
+
+            path := lastSegment(t, dir)
+            info, err := os.Stat(path)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if err := damage(path, info.Size()); err != nil {
+                t.Fatal(err)
+            }
+
+            // The update and its audit entry were logged together, so
+            // neither is recovered.
+            s = openTest(t, dir)
+            if got, err := s.GetByID(a.ID); err != nil || got.Name != "Ada" {
+                t.Errorf("expected Ada as created, got %+v, %v", got, err)
+            }
+            checkHistory(t, s, a.ID, models.AuditCreate)
+
+            // The damaged entry is cut off, so what is written next can be
+            // read back.
+            if _, _, err := s.Update(a.ID, models.Student{Name: "Ada K", Age: 22, Email: "ada@example.com"}, alice); err != nil {
+                t.Fatal(err)
+            }
+            crash(t, s)
+            s = openTest(t, dir)
+            defer s.Close()
+            if got, err := s.GetByID(a.ID); err != nil || got.Name != "Ada K" {
+                t.Errorf("expected Ada K, got %+v, %v", got, err)
+            }
+            checkHistory(t, s, a.ID, models.AuditCreate, models.AuditUpdate)
+        })
+    }
+}
+
+func TestOpenLocksTheDirectory(t *testing.T) {
+    dir := t.TempDir()
+    s := openTest(t, dir)
+    if other, err := Open(Options{Dir: dir, Fsync: FsyncNever}); err == nil {
+        other.Close()
+        t.Fatal("expected a second Open of the directory to fail")
+    }
+    if err := s.Close(); err != nil {
+        t.Fatal(err)
+    }
+    s = openTest(t, dir)
+    s.Close()
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+    "time"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
+    "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/AashishKumar-3002/FealtyX/internal/search"
+)
+
+// SaveSummary keeps a live student's latest summary, in place of the
+// previous one, so that students can be searched by it.
+func (s *Storage) SaveSummary(summary models.StudentSummary) (models.StudentSummary, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    if student, ok := s.students.get(summary.StudentID); !ok || student.IsDeleted() {
+        return models.StudentSummary{}, apperror.NotFound("student %d not found", summary.StudentID)
+    }
+
+    summary.GeneratedAt = time.Now().UTC()
+    if err := s.commit(change{Op: opPutSummary, Summary: &summary}); err != nil {
+        return models.StudentSummary{}, err
+    }
+    return summary, nil
+}
+
+// Search finds up to limit live students whose name and email, latest
+// summary or one of whose notes hold every word of q, best first, among
+// those assigned to advisor if it is set.
+func (s *Storage) Search(q string, limit int, advisor string) []models.SearchResult {
+    query := search.ParseQuery(q)
+
+    s.mutex.RLock()
+    defer s.mutex.RUnlock()
+
+    results := []models.SearchResult{}
+    for _, hit := range s.index.Search(query) {
+        if len(results) == limit {
+            break
+        }
+        student, _ := s.students.get(hit.Student)
+        if student.IsDeleted() {
+            continue
+        }
+        if _, ok := s.advisees[advisor][student.ID]; advisor != "" && !ok {
+            continue
+        }
+        results = append(results, models.NewSearchResult(query, hit, student, s.searchText))
+    }
+    return results
+}
+
+// searchText returns the text of a note or summary in the index. The
+// caller must hold the mutex.
+func (s *Storage) searchText(key search.Key) string {
+    if key.Kind == search.KindNote {
+        return s.notes[key.ID].Body
+    }
+    return s.summaries[key.ID].Summary
+}
@@ -0,0 +1,11 @@
+package storage
+
+import (
+    "testing"
+
+    "github.com/AashishKumar-3002/FealtyX/internal/backendtest"
+)
+
+func TestSearchRanksHighlightsAndIgnoresOperators(t *testing.T) {
+    backendtest.Search(t, backend{NewStorage()})
+}
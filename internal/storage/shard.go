@@ -0,0 +1,90 @@
+package storage
+
+import (
+    "hash/maphash"
+    "sync"
+)
+
+// shardCount is how many shards a shardedMap has.
+const shardCount = 64
+
+// shardedMap is a map split into shards with a lock each, so that readers
+// of different keys do not contend. Its methods lock the shard they use.
+type shardedMap[K comparable, V any] struct {
+    shards [shardCount]shard[K, V]
+    hash   func(K) uint64
+}
+
+type shard[K comparable, V any] struct {
+    mutex sync.RWMutex
+    m     map[K]V
+}
+
+func newShardedMap[K comparable, V any](hash func(K) uint64) *shardedMap[K, V] {
+    m := &shardedMap[K, V]{hash: hash}
+    for i := range m.shards {
+        m.shards[i].m = make(map[K]V)
+    }
+    return m
+}
+
+// intHash spreads consecutive IDs over consecutive shards.
+func intHash(k int) uint64 {
+    return uint64(k)
+}
+
+var stringSeed = maphash.MakeSeed()
+
+func stringHash(k string) uint64 {
+    return maphash.String(stringSeed, k)
+}
+
+func (m *shardedMap[K, V]) shard(k K) *shard[K, V] {
+    return &m.shards[m.hash(k)%shardCount]
+}
+
+func (m *shardedMap[K, V]) get(k K) (V, bool) {
+    sh := m.shard(k)
+    sh.mutex.RLock()
+    defer sh.mutex.RUnlock()
+    v, ok := sh.m[k]
+    return v, ok
+}
+
+func (m *shardedMap[K, V]) set(k K, v V) {
+    sh := m.shard(k)
+    sh.mutex.Lock()
+    defer sh.mutex.Unlock()
+    sh.m[k] = v
+}
+
+func (m *shardedMap[K, V]) delete(k K) {
+    sh := m.shard(k)
+    sh.mutex.Lock()
+    defer sh.mutex.Unlock()
+    delete(sh.m, k)
+}
+
+func (m *shardedMap[K, V]) len() int {
+    n := 0
+    for i := range m.shards {
+        sh := &m.shards[i]
+        sh.mutex.RLock()
+        n += len(sh.m)
+        sh.mutex.RUnlock()
+    }
+    return n
+}
+
+// each calls fn for every entry, holding one shard's lock at a time, so fn
+// must not change the map.
+func (m *shardedMap[K, V]) each(fn func(K, V)) {
+    for i := range m.shards {
+        sh := &m.shards[i]
+        sh.mutex.RLock()
+        for k, v := range sh.m {
+            fn(k, v)
+        }
+        sh.mutex.RUnlock()
+    }
+}
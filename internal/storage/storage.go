@@ -1,78 +1,309 @@
 package storage
 
 import (
-    "errors"
+    "os"
+    "strings"
     "sync"
+    "time"
 
+    "github.com/AashishKumar-3002/FealtyX/internal/apperror"
     "github.com/AashishKumar-3002/FealtyX/internal/models"
+    "github.com/AashishKumar-3002/FealtyX/internal/search"
 )
 
+// Storage is safe for concurrent use. Writes are serialized by writeMutex
+// from the checks they make until their changes are applied, which also
+// makes handing out IDs atomic. The changes themselves are applied by
+// commit, which holds mutex only while it changes memory, so readers wait
+// for neither the checks nor the write-ahead log. The students and their
+// emails are further split into shards, which lets a read of one student
+// skip mutex altogether. Holding either mutex is enough to read anything
+// consistently, since changes need both.
 type Storage struct {
-    students map[int]models.Student
-    mutex    sync.RWMutex
-    nextID   int
+    students *shardedMap[int, models.Student]
+    // sortIndexes orders the students by each field they can be sorted
+    // by, for range filters and paging.
+    sortIndexes map[string]*orderedIndex
+    // emails indexes live student IDs by normalized email so that uniqueness
+    // matches the partial unique index of the Postgres backend.
+    emails     *shardedMap[string, int]
+    mutex      sync.RWMutex
+    writeMutex sync.Mutex
+    nextID     int
+
+    courses      map[int]models.Course
+    courseCodes  map[string]int
+    nextCourseID int
+    // enrollments maps student ID to course ID to enrollment.
+    enrollments      map[int]map[int]models.Enrollment
+    nextEnrollmentID int
+
+    grades      map[int]models.Grade
+    nextGradeID int
+
+    // attendance maps student ID to date to record.
+    attendance       map[int]map[string]models.AttendanceRecord
+    nextAttendanceID int
+
+    notes      map[int]models.Note
+    nextNoteID int
+
+    guardians      map[int]models.Guardian
+    nextGuardianID int
+    // guardianLinks maps student ID to guardian ID to link.
+    guardianLinks map[int]map[int]models.StudentGuardian
+
+    // advisors maps student ID to advisor to assignment, and advisees
+    // advisor to the IDs of its students.
+    advisors map[int]map[string]models.StudentAdvisor
+    advisees map[string]map[int]struct{}
+
+    // customFields maps field name to definition.
+    customFields      map[string]models.CustomField
+    nextCustomFieldID int
+
+    // summaries maps student ID to the student's latest summary.
+    summaries map[int]models.StudentSummary
+    // index holds the students, notes and summaries for full-text search.
+    // It is kept up to date by apply.
+    index *search.Index
+
+    // apiKeys maps ID to key, and apiKeyHashes hash to ID.
+    apiKeys      map[int]apiKey
+    apiKeyHashes map[string]int
+    nextAPIKeyID int
+
+    // audit maps student ID to the student's audit entries, oldest first.
+    audit       map[int][]models.AuditEntry
+    nextAuditID int64
+
+    // wal is nil unless the storage is kept on disk with Open.
+    wal *wal
+    dir string
+    // lock keeps other processes out of dir while the storage is open.
+    lock          *os.File
+    snapshotMutex sync.Mutex
+    // snapshotSeq is the last log entry in the snapshot file.
+    snapshotSeq uint64
 }
 
 func NewStorage() *Storage {
     return &Storage{
-        students: make(map[int]models.Student),
-        nextID:   1,
+        students:          newShardedMap[int, models.Student](intHash),
+        sortIndexes: map[string]*orderedIndex{
+            models.SortByID:    {},
+            models.SortByName:  {},
+            models.SortByAge:   {},
+            models.SortByEmail: {},
+        },
+        emails:            newShardedMap[string, int](stringHash),
+        nextID:            1,
+        courses:           make(map[int]models.Course),
+        courseCodes:       make(map[string]int),
+        nextCourseID:      1,
+        enrollments:       make(map[int]map[int]models.Enrollment),
+        nextEnrollmentID:  1,
+        grades:            make(map[int]models.Grade),
+        nextGradeID:       1,
+        attendance:        make(map[int]map[string]models.AttendanceRecord),
+        nextAttendanceID:  1,
+        notes:             make(map[int]models.Note),
+        nextNoteID:        1,
+        guardians:         make(map[int]models.Guardian),
+        nextGuardianID:    1,
+        guardianLinks:     make(map[int]map[int]models.StudentGuardian),
+        advisors:          make(map[int]map[string]models.StudentAdvisor),
+        advisees:          make(map[string]map[int]struct{}),
+        customFields:      make(map[string]models.CustomField),
+        nextCustomFieldID: 1,
+        summaries:         make(map[int]models.StudentSummary),
+        index:             search.NewIndex(),
+        apiKeys:           make(map[int]apiKey),
+        apiKeyHashes:      make(map[string]int),
+        nextAPIKeyID:      1,
+        audit:             make(map[int][]models.AuditEntry),
+        nextAuditID:       1,
     }
 }
 
-func (s *Storage) Create(student models.Student) (models.Student, error) {
-    s.mutex.Lock()
-    defer s.mutex.Unlock()
+func emailKey(email string) string {
+    return strings.ToLower(strings.TrimSpace(email))
+}
 
-    student.ID = s.nextID
-    s.students[student.ID] = student
-    s.nextID++
-    return student, nil
+// checkEmail returns a conflict if email belongs to a student other than id.
+// The caller must hold writeMutex.
+func (s *Storage) checkEmail(email string, id int) error {
+    if owner, ok := s.emails.get(emailKey(email)); ok && owner != id {
+        return apperror.Conflict("a student with this email already exists")
+    }
+    return nil
 }
 
-func (s *Storage) GetAll() []models.Student {
-    s.mutex.RLock()
-    defer s.mutex.RUnlock()
+func (s *Storage) Create(student models.Student, actor Actor) (models.Student, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
 
-    students := make([]models.Student, 0, len(s.students))
-    for _, student := range s.students {
-        students = append(students, student)
+    if err := s.checkEmail(student.Email, 0); err != nil {
+        return models.Student{}, err
     }
+
+    now := time.Now().UTC()
+    student.ID = s.nextID
+    student.Attributes = copyAttributes(student.Attributes)
+    student.CreatedAt = now
+    student.UpdatedAt = now
+    student.DeletedAt = nil
+    if err := s.commit(
+        change{Op: opPutStudent, Student: &student},
+        s.auditChange(models.AuditCreate, nil, &student, actor),
+    ); err != nil {
+        return models.Student{}, err
+    }
+    return student, nil
+}
+
+// GetAll lists the students that match the filter, ordered by ID.
+func (s *Storage) GetAll(filter models.StudentFilter) []models.Student {
+    students, _ := s.List(filter, models.StudentPage{})
     return students
 }
 
 func (s *Storage) GetByID(id int) (models.Student, error) {
+    student, ok := s.students.get(id)
+    if !ok || student.IsDeleted() {
+        return models.Student{}, apperror.NotFound("student %d not found", id)
+    }
+    return student, nil
+}
+
+// GetIncludingDeleted returns a student whether or not it is soft deleted.
+func (s *Storage) GetIncludingDeleted(id int) (models.Student, error) {
+    student, ok := s.students.get(id)
+    if !ok {
+        return models.Student{}, apperror.NotFound("student %d not found", id)
+    }
+    return student, nil
+}
+
+// GetByEmail looks a student up by email, ignoring case.
+func (s *Storage) GetByEmail(email string) (models.Student, error) {
+    // The email and the student are read from their shards one after the
+    // other, so a write may come in between; the student is only returned
+    // without taking the mutex if it still has the email.
+    key := emailKey(email)
+    if id, ok := s.emails.get(key); ok {
+        if student, ok := s.students.get(id); ok && !student.IsDeleted() && emailKey(student.Email) == key {
+            return student, nil
+        }
+    }
+
     s.mutex.RLock()
     defer s.mutex.RUnlock()
 
-    student, ok := s.students[id]
+    id, ok := s.emails.get(key)
     if !ok {
-        return models.Student{}, errors.New("student not found")
+        return models.Student{}, apperror.NotFound("no student with email %s", email)
     }
+    student, _ := s.students.get(id)
     return student, nil
 }
 
-func (s *Storage) Update(id int, student models.Student) (models.Student, error) {
-    s.mutex.Lock()
-    defer s.mutex.Unlock()
+// Update replaces a student and returns it as it was before and after, both
+// read under the same lock.
+func (s *Storage) Update(id int, student models.Student, actor Actor) (before, after models.Student, err error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
 
-    if _, ok := s.students[id]; !ok {
-        return models.Student{}, errors.New("student not found")
+    existing, ok := s.students.get(id)
+    if !ok || existing.IsDeleted() {
+        return models.Student{}, models.Student{}, apperror.NotFound("student %d not found", id)
+    }
+    if err := s.checkEmail(student.Email, id); err != nil {
+        return models.Student{}, models.Student{}, err
     }
 
     student.ID = id
-    s.students[id] = student
+    student.Attributes = copyAttributes(student.Attributes)
+    student.CreatedAt = existing.CreatedAt
+    student.UpdatedAt = time.Now().UTC()
+    student.DeletedAt = nil
+    if err := s.commit(
+        change{Op: opPutStudent, Student: &student},
+        s.auditChange(models.AuditUpdate, &existing, &student, actor),
+    ); err != nil {
+        return models.Student{}, models.Student{}, err
+    }
+    return existing, student, nil
+}
+
+// Delete soft deletes a student, releasing its email, and returns the
+// deleted record.
+func (s *Storage) Delete(id int, actor Actor) (models.Student, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    existing, ok := s.students.get(id)
+    if !ok || existing.IsDeleted() {
+        return models.Student{}, apperror.NotFound("student %d not found", id)
+    }
+
+    student := existing
+    now := time.Now().UTC()
+    student.DeletedAt = &now
+    if err := s.commit(
+        change{Op: opPutStudent, Student: &student},
+        s.auditChange(models.AuditDelete, &existing, &student, actor),
+    ); err != nil {
+        return models.Student{}, err
+    }
     return student, nil
 }
 
-func (s *Storage) Delete(id int) error {
-    s.mutex.Lock()
-    defer s.mutex.Unlock()
+// Restore undoes a soft delete and returns the student as it was before and
+// after. It fails with a conflict if another student has taken the email in
+// the meantime.
+func (s *Storage) Restore(id int, actor Actor) (before, after models.Student, err error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
 
-    if _, ok := s.students[id]; !ok {
-        return errors.New("student not found")
+    existing, ok := s.students.get(id)
+    if !ok || !existing.IsDeleted() {
+        return models.Student{}, models.Student{}, apperror.NotFound("no deleted student %d", id)
+    }
+    if err := s.checkEmail(existing.Email, id); err != nil {
+        return models.Student{}, models.Student{}, err
     }
 
-    delete(s.students, id)
-    return nil
-}
\ No newline at end of file
+    student := existing
+    student.DeletedAt = nil
+    student.UpdatedAt = time.Now().UTC()
+    if err := s.commit(
+        change{Op: opPutStudent, Student: &student},
+        s.auditChange(models.AuditRestore, &existing, &student, actor),
+    ); err != nil {
+        return models.Student{}, models.Student{}, err
+    }
+    return existing, student, nil
+}
+
+// Purge permanently removes students soft deleted before the given time,
+// along with their enrollments, grades, attendance, notes and guardian links,
+// and returns how many were removed.
+func (s *Storage) Purge(before time.Time) (int64, error) {
+    s.writeMutex.Lock()
+    defer s.writeMutex.Unlock()
+
+    var purged []change
+    s.students.each(func(_ int, student models.Student) {
+        if student.IsDeleted() && student.DeletedAt.Before(before) {
+            purged = append(purged, change{Op: opPurgeStudent, Student: &student})
+        }
+    })
+    if len(purged) == 0 {
+        return 0, nil
+    }
+    if err := s.commit(purged...); err != nil {
+        return 0, err
+    }
+    return int64(len(purged)), nil
+}
@@ -0,0 +1,350 @@
+package storage
+
+import (
+    "bufio"
+    "encoding/binary"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "hash/crc32"
+    "io"
+    "log"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// FsyncPolicy decides when writes to the write-ahead log are flushed to
+// disk.
+type FsyncPolicy string
+
+const (
+    // FsyncAlways flushes every write before it is acknowledged, so an
+    // acknowledged write survives even a power failure.
+    FsyncAlways FsyncPolicy = "always"
+    // FsyncInterval flushes in the background, so a power failure loses at
+    // most the writes of the last interval.
+    FsyncInterval FsyncPolicy = "interval"
+    // FsyncNever leaves flushing to the operating system. Writes survive a
+    // crash of the process but not of the machine.
+    FsyncNever FsyncPolicy = "never"
+)
+
+// ParseFsyncPolicy parses always, interval or never.
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+    switch p := FsyncPolicy(s); p {
+    case FsyncAlways, FsyncInterval, FsyncNever:
+        return p, nil
+    }
+    return "", fmt.Errorf("fsync policy must be always, interval or never, not %q", s)
+}
+
+// The log is split into segments named after the sequence number of their
+// first entry, such as wal-00000000000000000001.log, so that the segments
+// covered by a snapshot can be removed whole.
+const (
+    segmentPrefix = "wal-"
+    segmentSuffix = ".log"
+)
+
+func segmentName(first uint64) string {
+    return fmt.Sprintf("%s%020d%s", segmentPrefix, first, segmentSuffix)
+}
+
+// entry is one record of the write-ahead log: the changes made by one
+// storage operation, which are replayed together or not at all.
+type entry struct {
+    Seq     uint64   `json:"seq"`
+    Changes []change `json:"changes"`
+}
+
+// Each entry is written as its length and CRC-32 checksum followed by its
+// JSON encoding, so that an entry torn by a crash is recognized.
+const (
+    frameHeaderSize = 8
+    maxEntrySize    = 64 << 20
+)
+
+var errWALClosed = errors.New("the write-ahead log is closed")
+
+// wal appends entries to the current segment of the log.
+type wal struct {
+    mu     sync.Mutex
+    dir    string
+    policy FsyncPolicy
+    file   *os.File
+    size   int64
+    // seq is the sequence number of the last entry written.
+    seq   uint64
+    dirty bool
+    // err is set once the log cannot be written safely, such as after a
+    // failed write that could not be undone. Every later write fails with
+    // it rather than appending after a torn entry.
+    err error
+
+    stop chan struct{}
+    done chan struct{}
+}
+
+// openWAL starts a new segment for the entries after seq.
+func openWAL(dir string, seq uint64, policy FsyncPolicy, interval time.Duration) (*wal, error) {
+    l := &wal{dir: dir, policy: policy, seq: seq}
+    if err := l.openSegment(); err != nil {
+        return nil, err
+    }
+    if policy == FsyncInterval {
+        l.stop = make(chan struct{})
+        l.done = make(chan struct{})
+        go l.syncEvery(interval)
+    }
+    return l, nil
+}
+
+// openSegment opens the segment for the entries after l.seq.
+func (l *wal) openSegment() error {
+    f, err := os.OpenFile(filepath.Join(l.dir, segmentName(l.seq+1)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+    if err != nil {
+        return err
+    }
+    info, err := f.Stat()
+    if err == nil {
+        err = syncDir(l.dir)
+    }
+    if err != nil {
+        f.Close()
+        return err
+    }
+    l.file = f
+    l.size = info.Size()
+    return nil
+}
+
+// append writes the changes as the next entry.
+func (l *wal) append(changes []change) error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if l.err != nil {
+        return l.err
+    }
+    payload, err := json.Marshal(entry{Seq: l.seq + 1, Changes: changes})
+    if err != nil {
+        return err
+    }
+    if len(payload) > maxEntrySize {
+        return fmt.Errorf("entry of %d bytes is larger than the limit of %d", len(payload), maxEntrySize)
+    }
+    frame := make([]byte, frameHeaderSize+len(payload))
+    binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+    binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+    copy(frame[frameHeaderSize:], payload)
+
+    if _, err := l.file.Write(frame); err != nil {
+        return l.undo(err)
+    }
+    if l.policy == FsyncAlways {
+        if err := l.file.Sync(); err != nil {
+            return l.undo(err)
+        }
+    } else {
+        l.dirty = true
+    }
+    l.seq++
+    l.size += int64(len(frame))
+    return nil
+}
+
+// undo cuts a failed write off the end of the segment. If that fails too the
+// log is unusable.
+func (l *wal) undo(err error) error {
+    if terr := l.file.Truncate(l.size); terr != nil {
+        l.err = fmt.Errorf("write-ahead log is unusable after a failed write: %w", err)
+        return l.err
+    }
+    return err
+}
+
+func (l *wal) syncEvery(interval time.Duration) {
+    defer close(l.done)
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ticker.C:
+            l.mu.Lock()
+            if err := l.sync(); err != nil {
+                log.Printf("Error syncing the write-ahead log: %v", err)
+            }
+            l.mu.Unlock()
+        case <-l.stop:
+            return
+        }
+    }
+}
+
+// sync flushes the current segment. The caller must hold l.mu.
+func (l *wal) sync() error {
+    if !l.dirty || l.err != nil {
+        return nil
+    }
+    if err := l.file.Sync(); err != nil {
+        // Whether the unflushed writes reached the disk is unknown, so the
+        // log cannot be trusted any more.
+        l.err = fmt.Errorf("write-ahead log is unusable after a failed sync: %w", err)
+        return l.err
+    }
+    l.dirty = false
+    return nil
+}
+
+// rotate closes the current segment and starts a new one, unless the current
+// one is still empty, and returns the sequence number of the last entry
+// before the new segment.
+func (l *wal) rotate() (uint64, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if l.err != nil {
+        return 0, l.err
+    }
+    if l.size == 0 {
+        return l.seq, nil
+    }
+    if err := l.sync(); err != nil {
+        return 0, err
+    }
+    if err := l.file.Close(); err != nil {
+        l.err = err
+        return 0, err
+    }
+    if err := l.openSegment(); err != nil {
+        l.err = err
+        return 0, err
+    }
+    return l.seq, nil
+}
+
+// close flushes and closes the log. Later writes fail.
+func (l *wal) close() error {
+    if l.stop != nil {
+        close(l.stop)
+        <-l.done
+        l.stop = nil
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if l.err == errWALClosed {
+        return nil
+    }
+    err := l.sync()
+    if cerr := l.file.Close(); err == nil {
+        err = cerr
+    }
+    l.err = errWALClosed
+    return err
+}
+
+// segments lists the segments in dir by the sequence number of their first
+// entry, oldest first.
+func segments(dir string) ([]uint64, error) {
+    files, err := os.ReadDir(dir)
+    if err != nil {
+        return nil, err
+    }
+    var firsts []uint64
+    for _, f := range files {
+        name := f.Name()
+        if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+            continue
+        }
+        first, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+        if err != nil {
+            continue
+        }
+        firsts = append(firsts, first)
+    }
+    sort.Slice(firsts, func(i, j int) bool { return firsts[i] < firsts[j] })
+    return firsts, nil
+}
+
+// removeSegments deletes the segments that only hold entries up to seq.
+func removeSegments(dir string, seq uint64) error {
+    firsts, err := segments(dir)
+    if err != nil {
+        return err
+    }
+    for _, first := range firsts {
+        if first > seq {
+            break
+        }
+        if err := os.Remove(filepath.Join(dir, segmentName(first))); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// readSegment calls fn for each entry of a segment. It returns the size of
+// the complete entries and whether they are followed by a torn or corrupt
+// one.
+func readSegment(path string, fn func(entry) error) (size int64, torn bool, err error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return 0, false, err
+    }
+    defer f.Close()
+
+    r := bufio.NewReader(f)
+    header := make([]byte, frameHeaderSize)
+    for {
+        if _, err := io.ReadFull(r, header); err != nil {
+            if err == io.EOF {
+                return size, false, nil
+            }
+            if err == io.ErrUnexpectedEOF {
+                return size, true, nil
+            }
+            return size, false, err
+        }
+        n := binary.BigEndian.Uint32(header[0:4])
+        if n > maxEntrySize {
+            return size, true, nil
+        }
+        payload := make([]byte, n)
+        if _, err := io.ReadFull(r, payload); err != nil {
+            if err == io.EOF || err == io.ErrUnexpectedEOF {
+                return size, true, nil
+            }
+            return size, false, err
+        }
+        if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+            return size, true, nil
+        }
+
+        var e entry
+        if err := json.Unmarshal(payload, &e); err != nil {
+            return size, false, fmt.Errorf("entry at offset %d: %w", size, err)
+        }
+        if err := fn(e); err != nil {
+            return size, false, err
+        }
+        size += int64(frameHeaderSize) + int64(n)
+    }
+}
+
+// syncDir flushes a directory so that files created or renamed in it survive
+// a crash.
+func syncDir(dir string) error {
+    d, err := os.Open(dir)
+    if err != nil {
+        return err
+    }
+    defer d.Close()
+    return d.Sync()
+}
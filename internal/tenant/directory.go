@@ -0,0 +1,89 @@
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+)
+
+// Settings are what a tenant configures for itself.
+type Settings struct {
+	// OllamaModel generates the tenant's summaries; empty uses the
+	// deployment's model.
+	OllamaModel string `json:"ollama_model"`
+	// SummaryPrompt and GuardianPrompt are added to the prompts for student
+	// and guardian summaries, to set a tone or explain the school's grading,
+	// for instance.
+	SummaryPrompt  string `json:"summary_prompt"`
+	GuardianPrompt string `json:"guardian_prompt"`
+}
+
+// Directory is the tenants a deployment serves.
+type Directory struct {
+	settings map[string]Settings
+	// listed limits the deployment to the tenants in settings and Default.
+	listed bool
+}
+
+// NewDirectory returns a directory that serves only Default.
+func NewDirectory() *Directory {
+	return &Directory{settings: map[string]Settings{}, listed: true}
+}
+
+// NewOpenDirectory returns a directory that serves any tenant, none of
+// which have settings. Every new ID a request names makes a tenant, so it
+// is only for deployments that trust their clients to name tenants.
+func NewOpenDirectory() *Directory {
+	return &Directory{settings: map[string]Settings{}}
+}
+
+// LoadDirectory reads a JSON object mapping tenant IDs to their settings.
+// The deployment then serves only those tenants and Default.
+func LoadDirectory(path string) (*Directory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("decoding %s: %v", path, err)
+	}
+	for id := range settings {
+		if err := Validate(id); err != nil {
+			return nil, fmt.Errorf("tenant %q: %v", id, err)
+		}
+	}
+	if settings == nil {
+		settings = map[string]Settings{}
+	}
+	return &Directory{settings: settings, listed: true}, nil
+}
+
+// Lookup returns the tenant with the given ID, failing with not found if
+// the deployment does not serve it.
+func (d *Directory) Lookup(id string) (Tenant, error) {
+	if err := Validate(id); err != nil {
+		return Tenant{}, err
+	}
+	settings, ok := d.settings[id]
+	if !ok && d.listed && id != Default {
+		return Tenant{}, apperror.NotFound("tenant %s not found", id)
+	}
+	return Tenant{ID: id, Settings: settings}, nil
+}
+
+// IDs lists Default and the tenants that have settings, in order: every
+// tenant the directory serves, unless it is open.
+func (d *Directory) IDs() []string {
+	ids := []string{Default}
+	for id := range d.settings {
+		if id != Default {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids[1:])
+	return ids
+}
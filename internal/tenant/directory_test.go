@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+)
+
+func TestLookupServesOnlyKnownTenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	if err := os.WriteFile(path, []byte(`{"north": {"ollama_model": "llama3.2:3b"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := LoadDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		dir    *Directory
+		id     string
+		served bool
+	}{
+		{"default", NewDirectory(), Default, true},
+		{"default", NewDirectory(), "north", false},
+		{"listed", listed, Default, true},
+		{"listed", listed, "north", true},
+		{"listed", listed, "south", false},
+		{"open", NewOpenDirectory(), "south", true},
+	} {
+		_, err := tc.dir.Lookup(tc.id)
+		if tc.served && err != nil {
+			t.Errorf("%s directory: %s: %v", tc.name, tc.id, err)
+		}
+		if !tc.served && !apperror.Is(err, apperror.KindNotFound) {
+			t.Errorf("%s directory: %s: expected not found, got %v", tc.name, tc.id, err)
+		}
+	}
+
+	if _, err := NewOpenDirectory().Lookup("North!"); !apperror.Is(err, apperror.KindBadRequest) {
+		t.Errorf("expected an invalid ID to be rejected, got %v", err)
+	}
+	if north, _ := listed.Lookup("north"); north.Settings.OllamaModel != "llama3.2:3b" {
+		t.Errorf("expected north's settings, got %+v", north)
+	}
+}
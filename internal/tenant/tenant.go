@@ -0,0 +1,62 @@
+// Package tenant separates the schools that share a deployment. Each
+// request acts for one tenant, which is passed down in its context, and
+// both backends keep every tenant's records apart.
+package tenant
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+)
+
+const (
+	// Default is the tenant of requests that name none, and of the records
+	// that were stored before there were tenants.
+	Default = "default"
+	// Header names the tenant a request acts for. Principals that belong
+	// to a tenant may only repeat their own.
+	Header = "X-Tenant-ID"
+)
+
+// idPattern keeps tenant IDs safe to use as directory names and in logs.
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Validate checks that id can name a tenant.
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return apperror.BadRequest("tenant ID must be 1 to 63 lower case letters, digits, hyphens and underscores, starting with a letter or digit")
+	}
+	return nil
+}
+
+// Tenant is a tenant and its settings.
+type Tenant struct {
+	ID       string
+	Settings Settings
+}
+
+type contextKey int
+
+const tenantKey contextKey = iota
+
+// WithTenant returns a copy of ctx acting for t.
+func WithTenant(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey, t)
+}
+
+// FromContext returns the tenant ctx acts for. ok is false for work that
+// is not done for a request, such as the purge job, which spans tenants.
+func FromContext(ctx context.Context) (t Tenant, ok bool) {
+	t, ok = ctx.Value(tenantKey).(Tenant)
+	return t, ok
+}
+
+// ID returns the ID of the tenant ctx acts for, or Default if there is
+// none.
+func ID(ctx context.Context) string {
+	if t, ok := FromContext(ctx); ok {
+		return t.ID
+	}
+	return Default
+}
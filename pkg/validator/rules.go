@@ -0,0 +1,171 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Config holds the deployment-specific limits used by the custom rules.
+type Config struct {
+	MinAge            int
+	MaxAge            int
+	NameMinLength     int
+	NameMaxLength     int
+	DisposableDomains []string
+}
+
+var defaultDisposableDomains = []string{
+	"10minutemail.com",
+	"guerrillamail.com",
+	"mailinator.com",
+	"sharklasers.com",
+	"tempmail.com",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// DefaultConfig returns the limits used when nothing is configured.
+func DefaultConfig() Config {
+	return Config{
+		MinAge:            1,
+		MaxAge:            150,
+		NameMinLength:     2,
+		NameMaxLength:     100,
+		DisposableDomains: defaultDisposableDomains,
+	}
+}
+
+// ConfigFromEnv reads overrides for DefaultConfig from VALIDATION_MIN_AGE,
+// VALIDATION_MAX_AGE, VALIDATION_NAME_MIN_LENGTH, VALIDATION_NAME_MAX_LENGTH
+// and DISPOSABLE_EMAIL_DOMAINS (a comma-separated list added to the defaults).
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	ints := []struct {
+		key string
+		dst *int
+	}{
+		{"VALIDATION_MIN_AGE", &cfg.MinAge},
+		{"VALIDATION_MAX_AGE", &cfg.MaxAge},
+		{"VALIDATION_NAME_MIN_LENGTH", &cfg.NameMinLength},
+		{"VALIDATION_NAME_MAX_LENGTH", &cfg.NameMaxLength},
+	}
+	for _, i := range ints {
+		v := os.Getenv(i.key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %v", i.key, err)
+		}
+		*i.dst = n
+	}
+	if cfg.MinAge > cfg.MaxAge {
+		return Config{}, fmt.Errorf("VALIDATION_MIN_AGE %d is greater than VALIDATION_MAX_AGE %d", cfg.MinAge, cfg.MaxAge)
+	}
+	if cfg.NameMinLength > cfg.NameMaxLength {
+		return Config{}, fmt.Errorf("VALIDATION_NAME_MIN_LENGTH %d is greater than VALIDATION_NAME_MAX_LENGTH %d", cfg.NameMinLength, cfg.NameMaxLength)
+	}
+	if extra := os.Getenv("DISPOSABLE_EMAIL_DOMAINS"); extra != "" {
+		cfg.DisposableDomains = append([]string(nil), cfg.DisposableDomains...)
+		for _, d := range strings.Split(extra, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				cfg.DisposableDomains = append(cfg.DisposableDomains, d)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+type ruleSet struct {
+	Config
+	disposable map[string]bool
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = newRuleSet(DefaultConfig())
+)
+
+func newRuleSet(cfg Config) *ruleSet {
+	rs := &ruleSet{Config: cfg, disposable: make(map[string]bool, len(cfg.DisposableDomains))}
+	for _, d := range cfg.DisposableDomains {
+		rs.disposable[strings.ToLower(d)] = true
+	}
+	return rs
+}
+
+// Configure replaces the limits used by the custom rules. It is meant to be
+// called once at startup.
+func Configure(cfg Config) {
+	rs := newRuleSet(cfg)
+	rulesMu.Lock()
+	rules = rs
+	rulesMu.Unlock()
+}
+
+func current() *ruleSet {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return rules
+}
+
+func validatePersonName(fl validator.FieldLevel) bool {
+	cfg := current()
+	name := fl.Field().String()
+	n := utf8.RuneCountInString(name)
+	if n < cfg.NameMinLength || n > cfg.NameMaxLength {
+		return false
+	}
+	if strings.TrimSpace(name) != name {
+		return false
+	}
+	// Combining marks, such as Devanagari and Tamil vowel signs or the
+	// accents of decomposed Latin letters, belong to the letter before them.
+	prev := ' '
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), r == ' ', r == '-', r == '\'', r == '.':
+		case unicode.In(r, unicode.Mn, unicode.Mc):
+			if !unicode.IsLetter(prev) && !unicode.In(prev, unicode.Mn, unicode.Mc) {
+				return false
+			}
+		default:
+			return false
+		}
+		prev = r
+	}
+	return true
+}
+
+func validateAgeRange(fl validator.FieldLevel) bool {
+	cfg := current()
+	age := int(fl.Field().Int())
+	return age >= cfg.MinAge && age <= cfg.MaxAge
+}
+
+func validateNotDisposable(fl validator.FieldLevel) bool {
+	email := fl.Field().String()
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		// Malformed addresses are reported by the email rule.
+		return true
+	}
+	return !current().disposable[strings.ToLower(email[at+1:])]
+}
+
+// termPattern matches academic terms such as 2024-fall or 2024-2.
+var termPattern = regexp.MustCompile(`^\d{4}-(winter|spring|summer|fall|[1-9])$`)
+
+func validateTerm(fl validator.FieldLevel) bool {
+	return termPattern.MatchString(fl.Field().String())
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type person struct {
+	Name  string `json:"name" validate:"personname"`
+	Age   int    `json:"age" validate:"agerange"`
+	Email string `json:"email" validate:"email,notdisposable"`
+}
+
+// rulesFailed returns the rules that failed on each field of p.
+func rulesFailed(t *testing.T, p person) map[string]string {
+	t.Helper()
+	err := Validate(p)
+	if err == nil {
+		return nil
+	}
+	var verrs Errors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	failed := map[string]string{}
+	for _, fe := range verrs {
+		failed[fe.Field] = fe.Rule
+	}
+	return failed
+}
+
+func TestPersonName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"Jo":                      true,
+		"Mary-Jane O'Neil Jr.":    true,
+		"José García":             true,
+		"Jose\u0301 Garci\u0301a": true, // decomposed
+		"आशीष कुमार":              true,
+		"தமிழ்":                   true,
+		"Zoë Ångström":            true,
+		"Nguyễn Văn A":            true,
+		"J":                       false,
+		"John2":                   false,
+		"John_Doe":                false,
+		" John":                   false,
+		"John ":                   false,
+		"\u0301John":              false, // a mark with no letter
+		"John \u0301Doe":          false,
+		"John\tDoe":               false,
+		strings.Repeat("a", 101):  false,
+	} {
+		failed := rulesFailed(t, person{Name: name, Age: 20, Email: "a@example.com"})
+		if got := failed["name"] == ""; got != valid {
+			t.Errorf("name %q: valid %v, want %v", name, got, valid)
+		}
+	}
+}
+
+func TestAgeRangeAndDisposableEmail(t *testing.T) {
+	for _, tc := range []struct {
+		p    person
+		want map[string]string
+	}{
+		{person{Name: "Jo", Age: 1, Email: "a@example.com"}, nil},
+		{person{Name: "Jo", Age: 150, Email: "a@example.com"}, nil},
+		{person{Name: "Jo", Age: 0, Email: "a@example.com"}, map[string]string{"age": "agerange"}},
+		{person{Name: "Jo", Age: 151, Email: "a@example.com"}, map[string]string{"age": "agerange"}},
+		{person{Name: "Jo", Age: 20, Email: "a@Mailinator.com"}, map[string]string{"email": "notdisposable"}},
+		{person{Name: "Jo", Age: 20, Email: "not-an-email"}, map[string]string{"email": "email"}},
+	} {
+		failed := rulesFailed(t, tc.p)
+		if len(failed) != len(tc.want) {
+			t.Errorf("%+v: failed %v, want %v", tc.p, failed, tc.want)
+			continue
+		}
+		for field, rule := range tc.want {
+			if failed[field] != rule {
+				t.Errorf("%+v: failed %v, want %v", tc.p, failed, tc.want)
+			}
+		}
+	}
+}
+
+func TestConfigureChangesLimits(t *testing.T) {
+	defer Configure(DefaultConfig())
+
+	cfg := DefaultConfig()
+	cfg.NameMinLength, cfg.MaxAge = 4, 18
+	Configure(cfg)
+
+	failed := rulesFailed(t, person{Name: "Jo", Age: 19, Email: "a@example.com"})
+	if failed["name"] != "personname" || failed["age"] != "agerange" {
+		t.Errorf("expected the configured limits to apply, got %v", failed)
+	}
+}
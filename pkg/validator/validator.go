@@ -1,9 +1,121 @@
 package validator
 
-import "github.com/go-playground/validator/v10"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 
-var validate = validator.New()
+	"github.com/go-playground/validator/v10"
+)
 
+// FieldError is a single failed rule on a field, with a message suitable for
+// showing to an API client.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// Errors is returned by Validate when one or more fields fail their rules.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	// Report fields by their JSON names so errors match the request body.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterValidation("personname", validatePersonName)
+	v.RegisterValidation("agerange", validateAgeRange)
+	v.RegisterValidation("notdisposable", validateNotDisposable)
+	v.RegisterValidation("term", validateTerm)
+	return v
+}
+
+// Validate checks s against its validate tags. Rule failures are returned as
+// Errors; any other error means s could not be validated at all.
 func Validate(s interface{}) error {
-	return validate.Struct(s)
-}
\ No newline at end of file
+	err := validate.Struct(s)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	fields := make(Errors, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return fields
+}
+
+// fieldPath returns the JSON path of the field, such as records[0].status,
+// by dropping the struct type from the namespace.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	cfg := current()
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "required_if":
+		return fmt.Sprintf("%s is required when %s", fe.Field(), requiredIfCondition(fe.Param()))
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fe.Field())
+	case "personname":
+		return fmt.Sprintf("%s must be %d to %d characters of letters, spaces, hyphens, apostrophes or periods",
+			fe.Field(), cfg.NameMinLength, cfg.NameMaxLength)
+	case "agerange":
+		return fmt.Sprintf("%s must be between %d and %d", fe.Field(), cfg.MinAge, cfg.MaxAge)
+	case "notdisposable":
+		return fmt.Sprintf("%s must not use a disposable email provider", fe.Field())
+	case "term":
+		return fmt.Sprintf("%s must look like 2024-fall (winter, spring, summer or fall) or 2024-1", fe.Field())
+	case "gte", "min":
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "lte", "max":
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "datetime":
+		return fmt.Sprintf("%s must be formatted as %s", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), fe.Param())
+	case "e164":
+		return fmt.Sprintf("%s must be a phone number in international format, such as +15551234567", fe.Field())
+	case "bcp47_language_tag":
+		return fmt.Sprintf("%s must be a language tag such as en or pt-BR", fe.Field())
+	}
+	return fmt.Sprintf("%s failed on the '%s' rule", fe.Field(), fe.Tag())
+}
+
+// requiredIfCondition describes the parameter of a required_if rule, such as
+// "PreferredContact email".
+func requiredIfCondition(param string) string {
+	parts := strings.Fields(param)
+	if len(parts) != 2 {
+		return param
+	}
+	return fmt.Sprintf("%s is %s", parts[0], parts[1])
+}
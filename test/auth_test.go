@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/auth"
+	api "github.com/AashishKumar-3002/FealtyX/internal/memory"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+	"github.com/AashishKumar-3002/FealtyX/internal/storage"
+
+	"github.com/gorilla/mux"
+)
+
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// signHS256 returns a token for sub signed with testJWTSecret.
+func signHS256(t *testing.T, sub, role string) string {
+	t.Helper()
+	segment := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := segment(map[string]string{"alg": "HS256"}) + "." +
+		segment(map[string]interface{}{"sub": sub, "role": role, "exp": time.Now().Add(time.Hour).Unix()})
+	mac := hmac.New(sha256.New, testJWTSecret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// studentRoutes are the handlers of one backend that the audit log test
+// goes through.
+type studentRoutes struct {
+	create, update, history http.HandlerFunc
+}
+
+// checkAuditRecordsPrincipals makes a change with an API key and another
+// with a token, and checks that the history names each principal.
+func checkAuditRecordsPrincipals(t *testing.T, routes studentRoutes, key string, keys auth.KeyLookup) {
+	t.Helper()
+	authenticator := auth.New(auth.Config{Keys: keys, JWT: auth.NewJWTVerifier(auth.JWTConfig{Secret: testJWTSecret})})
+	r := mux.NewRouter()
+	r.Use(authenticator.Middleware)
+	r.HandleFunc("/students", routes.create).Methods("POST")
+	r.HandleFunc("/students/{id}", routes.update).Methods("PUT")
+	r.HandleFunc("/students/{id}/history", routes.history).Methods("GET")
+
+	serve := func(method, path, credential string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Authorization", "Bearer "+credential)
+		// A forged actor header must not override the principal.
+		req.Header.Set("X-Actor", "someone-else")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve("POST", "/students", key, models.Student{Name: "Audited Student", Age: 20, Email: "audited.principal@example.com"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create returned %d: %s", rr.Code, rr.Body)
+	}
+	var student models.Student
+	json.Unmarshal(rr.Body.Bytes(), &student)
+
+	token := signHS256(t, "teacher-7", "advisor")
+	rr = serve("PUT", fmt.Sprintf("/students/%d", student.ID), token, models.Student{Name: "Audited Student", Age: 21, Email: "audited.principal@example.com"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update returned %d: %s", rr.Code, rr.Body)
+	}
+
+	rr = serve("GET", fmt.Sprintf("/students/%d/history", student.ID), key, nil)
+	var history []models.AuditEntry
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil {
+		t.Fatalf("history returned %d: %s", rr.Code, rr.Body)
+	}
+	if len(history) != 2 || history[0].Actor != "api-key:auditor" || history[1].Actor != "teacher-7" {
+		t.Errorf("expected the key and then the token's subject as actors, got %+v", history)
+	}
+}
+
+func TestAuditRecordsThePrincipal(t *testing.T) {
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("sql", func(t *testing.T) {
+		stored := models.APIKey{Name: "auditor", Prefix: prefix, Role: string(auth.RoleAdmin), CreatedBy: "test"}
+		if err := stored.Create(context.Background(), db, hash); err != nil {
+			t.Fatal(err)
+		}
+		checkAuditRecordsPrincipals(t, studentRoutes{h.CreateStudent, h.UpdateStudent, h.GetStudentHistory}, key,
+			func(ctx context.Context, hash string) (models.APIKey, error) {
+				return models.GetAPIKeyByHash(ctx, db, hash)
+			})
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		a := api.NewAPI(storage.NewStorage())
+		checkAuditRecordsPrincipals(t, studentRoutes{a.CreateStudent, a.UpdateStudent, a.GetStudentHistory}, key,
+			func(ctx context.Context, h string) (models.APIKey, error) {
+				if h != hash {
+					return models.APIKey{}, apperror.NotFound("no such key")
+				}
+				return models.APIKey{Name: "auditor", Role: string(auth.RoleAdmin)}, nil
+			})
+	})
+}
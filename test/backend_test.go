@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+// sqlBackend runs the shared backend tests against the test database.
+type sqlBackend struct{ ctx context.Context }
+
+func (b sqlBackend) CreateStudent(student models.Student) (models.Student, error) {
+	err := student.Create(b.ctx, db)
+	return student, err
+}
+
+func (b sqlBackend) SaveSummary(summary models.StudentSummary) error {
+	return summary.Save(b.ctx, db)
+}
+
+func (b sqlBackend) CreateNote(note models.Note) error {
+	return note.Create(b.ctx, db)
+}
+
+func (b sqlBackend) ListStudents(filter models.StudentFilter, page models.StudentPage) ([]models.Student, *models.StudentCursor, error) {
+	return models.ListStudents(b.ctx, db, filter, page)
+}
+
+func (b sqlBackend) SearchStudents(q string, limit int) ([]models.SearchResult, error) {
+	return models.SearchStudents(b.ctx, db, q, limit, "")
+}
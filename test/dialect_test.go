@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// The tests in this file cover the SQL that differs between Postgres and
+// SQLite, and run against whichever TEST_CONNECTION_STRING names.
+
+func TestExportIgnoresHostileFilterValues(t *testing.T) {
+	ctx := context.Background()
+	for _, s := range []models.Student{
+		{Name: "O'Brien Export", Age: 20, Email: "obrien.export@example.com"},
+		{Name: "Other Export", Age: 20, Email: "other.export@example.com"},
+	} {
+		if err := s.Create(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students/export", h.ExportStudents).Methods("GET")
+
+	for _, filter := range []url.Values{
+		{"email_prefix": {"$2"}, "name_prefix": {`" or 1=1 --`}},
+		{"email_prefix": {"$3"}, "name_prefix": {`' or 1=1 --`}},
+		{"name_prefix": {`\' or 1=1 --`}},
+		{"name_prefix": {`$1`}, "min_age": {"1"}},
+		{"email_prefix": {`%`}},
+		{"email_prefix": {`\`}},
+	} {
+		filter.Set("format", "ndjson")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", "/students/export?"+filter.Encode(), nil))
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("export with %v returned %v: %s", filter, rr.Code, rr.Body)
+			continue
+		}
+		if body := strings.TrimSpace(rr.Body.String()); body != "" {
+			t.Errorf("export with %v matched students: %s", filter, body)
+		}
+	}
+
+	// The quote is still matched as a character of the name.
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/students/export?format=ndjson&name_prefix="+url.QueryEscape("o'brien"), nil))
+	var student models.Student
+	if err := json.Unmarshal(rr.Body.Bytes(), &student); err != nil || student.Email != "obrien.export@example.com" {
+		t.Errorf("expected O'Brien, got %s", rr.Body)
+	}
+}
+
+func TestEachStudentReadsEveryPage(t *testing.T) {
+	ctx := context.Background()
+	created := map[int]bool{}
+	for i := 0; i < 3; i++ {
+		s := models.Student{Name: "Paged Export", Age: 40 + i, Email: "paged.export" + string(rune('a'+i)) + "@example.com"}
+		if err := s.Create(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+		created[s.ID] = true
+	}
+
+	minAge, maxAge := 40, 42
+	last := 0
+	err := models.EachStudent(ctx, db, models.StudentFilter{MinAge: &minAge, MaxAge: &maxAge, NamePrefix: "paged export"}, func(s models.Student) error {
+		if s.ID <= last {
+			t.Errorf("student %d came after %d", s.ID, last)
+		}
+		last = s.ID
+		delete(created, s.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 0 {
+		t.Errorf("students %v were not exported", created)
+	}
+}
+
+// TestEachStudentReadsOneSnapshot writes while an export runs, between its
+// first page and the rest, and expects the export not to see the writes.
+func TestEachStudentReadsOneSnapshot(t *testing.T) {
+	ctx := context.Background()
+	var students []models.Student
+	var ids []int
+	for i := 0; i < 501; i++ {
+		s := models.Student{Name: "Snapshot Export", Age: 50, Email: fmt.Sprintf("snapshot.export%d@example.com", i)}
+		if err := s.Create(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+		students = append(students, s)
+		ids = append(ids, s.ID)
+	}
+
+	filter := models.StudentFilter{NamePrefix: "snapshot export"}
+	var exported []int
+	err := models.EachStudent(ctx, db, filter, func(s models.Student) error {
+		if len(exported) == 0 {
+			// The last student is on the second page, and the new one
+			// would be too.
+			last := students[len(students)-1]
+			last.Name = "Moved Away"
+			if err := last.Update(ctx, db, last.ID); err != nil {
+				return err
+			}
+			added := models.Student{Name: "Snapshot Export", Age: 50, Email: "snapshot.export.added@example.com"}
+			if err := added.Create(ctx, db); err != nil {
+				return err
+			}
+		}
+		exported = append(exported, s.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(exported, ids) {
+		t.Errorf("the export saw the writes made while it ran: got %d students from %v to %v, want %d from %d to %d",
+			len(exported), exported[:1], exported[len(exported)-1:], len(ids), ids[0], ids[len(ids)-1])
+	}
+}
+
+func TestAttendanceSaveReplacesTheDay(t *testing.T) {
+	ctx := context.Background()
+	student := models.Student{Name: "Upsert Student", Age: 15, Email: "upsert@example.com"}
+	if err := student.Create(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, status := range []string{models.AttendanceAbsent, models.AttendanceExcused} {
+		record := models.AttendanceRecord{StudentID: student.ID, Date: "2024-10-01", Status: status}
+		if err := record.Save(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := models.GetStudentAttendance(ctx, db, student.ID, models.DateRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Status != models.AttendanceExcused {
+		t.Errorf("expected the day to be replaced by an excused record, got %+v", records)
+	}
+}
+
+func TestDeletingCustomFieldRemovesItsValues(t *testing.T) {
+	ctx := context.Background()
+	for _, name := range []string{"locker", "bus"} {
+		field := models.CustomField{Name: name, Type: models.FieldString}
+		if err := field.Create(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer models.DeleteCustomField(ctx, db, "bus")
+	student := models.Student{Name: "Field Student", Age: 20, Email: "field.student@example.com",
+		Attributes: map[string]interface{}{"locker": "12", "bus": "7"}}
+	if err := student.Create(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := models.DeleteCustomField(ctx, db, "locker"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := models.GetStudent(ctx, db, student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Attributes) != 1 || got.Attributes["bus"] != "7" {
+		t.Errorf("expected only the bus attribute to remain, got %v", got.Attributes)
+	}
+}
+
+func TestEmailUniquenessIgnoresCase(t *testing.T) {
+	ctx := context.Background()
+	first := models.Student{Name: "Mixed Case", Age: 20, Email: "Mixed.Case@Example.com"}
+	if err := first.Create(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	second := models.Student{Name: "Lower Case", Age: 20, Email: "mixed.case@example.com"}
+	if err := second.Create(ctx, db); !apperror.Is(err, apperror.KindConflict) {
+		t.Errorf("expected a conflict for the same email in another case, got %v", err)
+	}
+
+	found, err := models.GetStudentByEmail(ctx, db, "MIXED.CASE@EXAMPLE.COM")
+	if err != nil || found.ID != first.ID {
+		t.Errorf("expected student %d by email, got %v, %v", first.ID, found, err)
+	}
+}
+
+func TestPrefixFiltersMatchWildcardsLiterally(t *testing.T) {
+	ctx := context.Background()
+	for _, email := range []string{"wild_card@example.com", "wildXcard@example.com", `wild\card@example.com`} {
+		s := models.Student{Name: "Wild Card", Age: 20, Email: email}
+		if err := s.Create(ctx, db); err != nil {
+			t.Fatalf("creating %s: %v", email, err)
+		}
+	}
+
+	for prefix, want := range map[string][]string{
+		"wild_":  {"wild_card@example.com"},
+		"wild%":  nil,
+		`wild\`:  {`wild\card@example.com`},
+		"wildx":  {"wildxcard@example.com"},
+		"wild":   {"wild_card@example.com", "wildxcard@example.com", `wild\card@example.com`},
+		"wildc":  nil,
+		"wild_%": nil,
+	} {
+		students, err := models.GetAllStudents(ctx, db, models.StudentFilter{EmailPrefix: prefix})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range students {
+			got = append(got, strings.ToLower(s.Email))
+		}
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("email_prefix %q matched %v, want %v", prefix, got, want)
+		}
+	}
+}
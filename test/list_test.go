@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
+	"github.com/AashishKumar-3002/FealtyX/internal/backendtest"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+func TestListStudentsPagesThroughEveryRange(t *testing.T) {
+	backendtest.List(t, sqlBackend{context.Background()})
+}
+
+func TestParseStudentPageRejectsForeignCursors(t *testing.T) {
+	cursor := models.StudentCursor{Sort: models.SortByName, Key: models.SortKey{Str: "keyset ann", ID: 1}}
+	for _, values := range []url.Values{
+		{"sort": {"age"}, "after": {cursor.Encode()}},
+		{"sort": {"-name"}, "after": {cursor.Encode()}},
+		{"sort": {"name"}, "after": {"not a cursor"}},
+		{"sort": {"name"}, "after": {"e30"}}, // {}
+	} {
+		if _, err := models.ParseStudentPage(values); !apperror.Is(err, apperror.KindBadRequest) {
+			t.Errorf("%v: expected a bad request, got %v", values, err)
+		}
+	}
+
+	page, err := models.ParseStudentPage(url.Values{"sort": {"name"}, "after": {cursor.Encode()}})
+	if err != nil || page.After == nil || *page.After != cursor {
+		t.Errorf("expected the cursor back, got %+v, %v", page.After, err)
+	}
+}
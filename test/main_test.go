@@ -2,16 +2,21 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/AashishKumar-3002/FealtyX/internal/apperror"
 	"github.com/AashishKumar-3002/FealtyX/internal/database"
 	"github.com/AashishKumar-3002/FealtyX/internal/handlers"
+	"github.com/AashishKumar-3002/FealtyX/internal/importer"
 	"github.com/AashishKumar-3002/FealtyX/internal/models"
 
 	"github.com/gorilla/mux"
@@ -22,14 +27,27 @@ var db *sql.DB
 var h *handlers.Handler
 
 func TestMain(m *testing.M) {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		panic(err)
-	}
+	// Load .env file, if there is one
+	godotenv.Load()
 
-	// Set up test database
+	os.Exit(run(m))
+}
+
+// run sets up the test database and runs the tests. It is separate from
+// TestMain so that its deferred clean-up runs before os.Exit.
+func run(m *testing.M) int {
+	// Set up test database: TEST_CONNECTION_STRING, or a fresh SQLite file
+	dbURL := os.Getenv("TEST_CONNECTION_STRING")
+	if dbURL == "" {
+		dir, err := os.MkdirTemp("", "fealtyx-test")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+		dbURL = "sqlite://" + filepath.Join(dir, "test.db")
+	}
 	var err error
-	db, err = database.Connect(os.Getenv("TEST_CONNECTION_STRING"))
+	db, err = database.Connect(dbURL, database.Options{})
 	if err != nil {
 		panic(err)
 	}
@@ -41,9 +59,9 @@ func TestMain(m *testing.M) {
 	code := m.Run()
 
 	// Clean up
-	db.Exec("DROP TABLE IF EXISTS students")
+	database.MigrateDown(db, 0)
 
-	os.Exit(code)
+	return code
 }
 
 func TestCreateStudent(t *testing.T) {
@@ -87,6 +105,10 @@ func TestGetAllStudents(t *testing.T) {
 	}
 }
 
+// concurrencyNames are posted by TestConcurrency, including names written
+// with combining marks.
+var concurrencyNames = []string{"Test User", "आशीष कुमार", "தமிழ் செல்வன்", "Jose\u0301 Garci\u0301a"}
+
 func TestConcurrency(t *testing.T) {
     numRequests := 100
     done := make(chan bool)
@@ -94,7 +116,7 @@ func TestConcurrency(t *testing.T) {
     for i := 0; i < numRequests; i++ {
         go func(i int) {
             student := models.Student{
-                Name:  fmt.Sprintf("Test User %d", i),
+                Name:  concurrencyNames[i%len(concurrencyNames)],
                 Age:   25,
                 Email: fmt.Sprintf("test%d@example.com", i),
             }
@@ -117,7 +139,7 @@ func TestConcurrency(t *testing.T) {
             r.HandleFunc("/students", h.CreateStudent).Methods("POST")
             r.ServeHTTP(rr, req)
 
-            if rr.Code != http.StatusOK {
+            if rr.Code != http.StatusCreated {
                 t.Errorf("failed to create student: %v", rr.Body.String())
                 done <- false
                 return
@@ -137,7 +159,7 @@ func TestConcurrency(t *testing.T) {
     }
 
     // Check if all students were created
-    req, err := http.NewRequest("GET", "/students", nil)
+    req, err := http.NewRequest("GET", "/students?email_prefix=test", nil)
     if err != nil {
         t.Fatalf("failed to create request: %v", err)
     }
@@ -155,4 +177,353 @@ func TestConcurrency(t *testing.T) {
     if len(students) != successCount {
         t.Errorf("expected %d students, got %d", successCount, len(students))
     }
-}
\ No newline at end of file
+}
+func TestCreateDuplicateStudentReturnsConflict(t *testing.T) {
+	student := models.Student{Name: "Jane Doe", Age: 22, Email: "jane.duplicate@example.com"}
+	body, _ := json.Marshal(student)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students", h.CreateStudent).Methods("POST")
+
+	for i, want := range []int{http.StatusCreated, http.StatusConflict} {
+		req, _ := http.NewRequest("POST", "/students", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != want {
+			t.Errorf("request %d returned wrong status code: got %v want %v", i, status, want)
+		}
+	}
+}
+
+func TestCreateInvalidStudentReturnsProblem(t *testing.T) {
+	body, _ := json.Marshal(models.Student{Name: "", Age: 0, Email: "not-an-email"})
+
+	req, _ := http.NewRequest("POST", "/students", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students", h.CreateStudent).Methods("POST")
+	r.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("handler returned wrong content type: got %v", ct)
+	}
+
+	var problem apperror.Problem
+	json.Unmarshal(rr.Body.Bytes(), &problem)
+	if len(problem.Errors) != 3 {
+		t.Errorf("expected 3 field errors, got %v", problem.Errors)
+	}
+}
+
+func TestGetStudentsByEmailIgnoresCase(t *testing.T) {
+	student := models.Student{Name: "Case Test", Age: 30, Email: "Case.Test@Example.com"}
+	if err := student.Create(context.Background(), db); err != nil {
+		t.Fatalf("failed to create student: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/students?email=case.test@example.com", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students", h.GetAllStudents).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	var students []models.Student
+	json.Unmarshal(rr.Body.Bytes(), &students)
+	if len(students) != 1 || students[0].ID != student.ID {
+		t.Errorf("expected student %d, got %v", student.ID, students)
+	}
+}
+
+func TestDeleteAndRestoreStudent(t *testing.T) {
+	student := models.Student{Name: "Soft Delete", Age: 19, Email: "soft.delete@example.com"}
+	if err := student.Create(context.Background(), db); err != nil {
+		t.Fatalf("failed to create student: %v", err)
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students/{id}", h.GetStudent).Methods("GET")
+	r.HandleFunc("/students/{id}", h.DeleteStudent).Methods("DELETE")
+	r.HandleFunc("/students/{id:[0-9]+}:restore", h.RestoreStudent).Methods("POST")
+
+	steps := []struct {
+		method, path string
+		want         int
+	}{
+		{"DELETE", fmt.Sprintf("/students/%d", student.ID), http.StatusNoContent},
+		{"GET", fmt.Sprintf("/students/%d", student.ID), http.StatusNotFound},
+		{"POST", fmt.Sprintf("/students/%d:restore", student.ID), http.StatusOK},
+		{"GET", fmt.Sprintf("/students/%d", student.ID), http.StatusOK},
+	}
+	for _, step := range steps {
+		req, _ := http.NewRequest(step.method, step.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != step.want {
+			t.Errorf("%s %s returned wrong status code: got %v want %v", step.method, step.path, rr.Code, step.want)
+		}
+	}
+}
+
+func TestStudentHistoryRecordsChanges(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/students", h.CreateStudent).Methods("POST")
+	r.HandleFunc("/students/{id}", h.UpdateStudent).Methods("PUT")
+	r.HandleFunc("/students/{id}/history", h.GetStudentHistory).Methods("GET")
+
+	body, _ := json.Marshal(models.Student{Name: "History Test", Age: 20, Email: "history@example.com"})
+	req, _ := http.NewRequest("POST", "/students", bytes.NewBuffer(body))
+	req.Header.Set("X-Actor", "registrar")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var student models.Student
+	json.Unmarshal(rr.Body.Bytes(), &student)
+
+	body, _ = json.Marshal(models.Student{Name: "History Test", Age: 21, Email: "history@example.com"})
+	req, _ = http.NewRequest("PUT", fmt.Sprintf("/students/%d", student.ID), bytes.NewBuffer(body))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/students/%d/history", student.ID), nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var history []models.AuditEntry
+	json.Unmarshal(rr.Body.Bytes(), &history)
+	if len(history) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(history))
+	}
+	if history[0].Action != models.AuditCreate || history[0].Actor != "registrar" {
+		t.Errorf("unexpected create entry: %+v", history[0])
+	}
+	if change, ok := history[1].Changes["age"]; !ok || change.To != float64(21) {
+		t.Errorf("expected age change to 21, got %+v", history[1].Changes)
+	}
+}
+
+func TestDeletingCourseRemovesEnrollments(t *testing.T) {
+	student := models.Student{Name: "Enrolled Student", Age: 18, Email: "enrolled@example.com"}
+	if err := student.Create(context.Background(), db); err != nil {
+		t.Fatalf("failed to create student: %v", err)
+	}
+	course := models.Course{Code: "HIST200", Title: "World History", Credits: 3}
+	if err := course.Create(context.Background(), db); err != nil {
+		t.Fatalf("failed to create course: %v", err)
+	}
+	enrollment := models.Enrollment{StudentID: student.ID, CourseID: course.ID, Status: models.EnrollmentActive}
+	if err := enrollment.Create(context.Background(), db); err != nil {
+		t.Fatalf("failed to enroll student: %v", err)
+	}
+
+	if err := models.DeleteCourse(context.Background(), db, course.ID); err != nil {
+		t.Fatalf("failed to delete course: %v", err)
+	}
+
+	enrollments, err := models.GetStudentEnrollments(context.Background(), db, student.ID)
+	if err != nil {
+		t.Fatalf("failed to list enrollments: %v", err)
+	}
+	if len(enrollments) != 0 {
+		t.Errorf("expected enrollments to be removed with the course, got %v", enrollments)
+	}
+}
+
+func TestCalculatePerformanceTrend(t *testing.T) {
+	course := &models.Course{Code: "MATH101", Title: "Algebra", Credits: 3}
+	grades := []models.Grade{
+		{Term: "2024-spring", Score: 72, LetterGrade: "C-", Course: course},
+		{Term: "2024-fall", Score: 84, LetterGrade: "B", Course: course},
+		{Term: "2025-spring", Score: 95, LetterGrade: "A", Course: course},
+	}
+
+	p := models.CalculatePerformance(grades)
+	if len(p.Terms) != 3 || p.Terms[0].Term != "2024-spring" || p.Terms[2].Term != "2025-spring" {
+		t.Fatalf("terms are not in chronological order: %+v", p.Terms)
+	}
+	if p.Trend != models.TrendImproving {
+		t.Errorf("expected an improving trend, got %s (slope %.2f)", p.Trend, p.TrendSlope)
+	}
+	if p.CumulativeGPA != 2.9 {
+		t.Errorf("expected cumulative GPA 2.9, got %.2f", p.CumulativeGPA)
+	}
+}
+
+func TestCalculateAttendanceStats(t *testing.T) {
+	statuses := []string{"present", "late", "absent", "excused", "absent", "present", "present"}
+	records := make([]models.AttendanceRecord, len(statuses))
+	for i, status := range statuses {
+		records[i] = models.AttendanceRecord{Date: fmt.Sprintf("2024-09-%02d", i+1), Status: status}
+	}
+
+	stats := models.CalculateAttendanceStats(records, models.DateRange{})
+	if stats.TotalDays != 7 || stats.ExcusedDays != 1 {
+		t.Errorf("unexpected day counts: %+v", stats)
+	}
+	if stats.AttendanceRate != 66.67 {
+		t.Errorf("expected attendance rate 66.67, got %v", stats.AttendanceRate)
+	}
+	if stats.CurrentStreak != 2 || stats.LongestAbsenceStreak != 2 {
+		t.Errorf("unexpected streaks: current %d, longest absence %d", stats.CurrentStreak, stats.LongestAbsenceStreak)
+	}
+	if !stats.ChronicallyAbsent {
+		t.Errorf("expected a student missing a third of days to be chronically absent")
+	}
+}
+
+func TestChronicAbsenceCountsExcusedDays(t *testing.T) {
+	days := func(counts map[string]int) []models.AttendanceRecord {
+		var records []models.AttendanceRecord
+		for status, n := range counts {
+			for i := 0; i < n; i++ {
+				records = append(records, models.AttendanceRecord{Date: fmt.Sprintf("2024-09-%02d", len(records)+1), Status: status})
+			}
+		}
+		return records
+	}
+
+	for _, tc := range []struct {
+		counts  map[string]int
+		chronic bool
+	}{
+		{map[string]int{"present": 9, "absent": 1}, true},
+		{map[string]int{"present": 10, "absent": 1}, false},
+		{map[string]int{"present": 9, "excused": 1}, true},
+		{map[string]int{"present": 10, "excused": 1}, false},
+		{map[string]int{"present": 18, "absent": 1, "excused": 1}, true},
+		{map[string]int{"present": 17, "late": 2, "absent": 1}, false},
+		{map[string]int{"excused": 3}, true},
+	} {
+		stats := models.CalculateAttendanceStats(days(tc.counts), models.DateRange{})
+		if stats.ChronicallyAbsent != tc.chronic {
+			t.Errorf("%v: chronically absent %v, want %v", tc.counts, stats.ChronicallyAbsent, tc.chronic)
+		}
+	}
+}
+
+func TestCreateNoteRecordsActorAsAuthor(t *testing.T) {
+	student := models.Student{Name: "Noted Student", Age: 19, Email: "noted@example.com"}
+	if err := student.Create(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students/{id}/notes", h.CreateNote).Methods("POST")
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/students/%d/notes", student.ID), bytes.NewBufferString(`{"body":"Needs help with essays."}`))
+	req.Header.Set("X-Actor", "advisor-1")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+	var note models.Note
+	json.Unmarshal(rr.Body.Bytes(), &note)
+	if note.Author != "advisor-1" || note.StudentID != student.ID {
+		t.Errorf("unexpected note: %+v", note)
+	}
+}
+
+func TestStudentAttributesAreValidatedAndFiltered(t *testing.T) {
+	field := models.CustomField{Name: "house", Type: models.FieldEnum, Rules: models.FieldRules{Options: []string{"red", "blue"}}}
+	if err := field.Create(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	defer models.DeleteCustomField(context.Background(), db, field.Name)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students", h.CreateStudent).Methods("POST")
+	r.HandleFunc("/students", h.GetAllStudents).Methods("GET")
+
+	for body, want := range map[string]int{
+		`{"name":"Red Student","age":18,"email":"red@example.com","attributes":{"house":"red"}}`:       http.StatusCreated,
+		`{"name":"Green Student","age":18,"email":"green@example.com","attributes":{"house":"green"}}`: http.StatusUnprocessableEntity,
+	} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("POST", "/students", bytes.NewBufferString(body)))
+		if rr.Code != want {
+			t.Errorf("creating %s returned %v, want %v", body, rr.Code, want)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/students?attr.house=red", nil))
+	var students []models.Student
+	json.Unmarshal(rr.Body.Bytes(), &students)
+	if len(students) != 1 || students[0].Email != "red@example.com" {
+		t.Errorf("expected only the red student, got %+v", students)
+	}
+}
+
+func TestStudentCanHaveOnlyOnePrimaryGuardian(t *testing.T) {
+	student := models.Student{Name: "Guarded Student", Age: 12, Email: "guarded@example.com"}
+	if err := student.Create(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, name := range []string{"Maria Doe", "Tom Doe"} {
+		guardian := models.Guardian{Name: name, Email: fmt.Sprintf("guardian%d@example.com", i)}
+		if err := guardian.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := guardian.Create(context.Background(), db); err != nil {
+			t.Fatal(err)
+		}
+
+		link := models.StudentGuardian{StudentID: student.ID, GuardianID: guardian.ID, Relationship: "parent", Primary: true}
+		err := link.Create(context.Background(), db)
+		if i == 0 && err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 && !apperror.Is(err, apperror.KindConflict) {
+			t.Errorf("expected a conflict for a second primary guardian, got %v", err)
+		}
+	}
+}
+
+func TestImportStudentsReportsEachRow(t *testing.T) {
+	roster := "Full Name,Age,Email\nImported One,20,imported1@example.com\nImported Two,not a number,imported2@example.com\nImported Again,21,IMPORTED1@example.com\n"
+	req, _ := http.NewRequest("POST", "/students/import?map.Full%20Name=name&on_duplicate=skip", bytes.NewBufferString(roster))
+	req.Header.Set("Content-Type", "text/csv")
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students/import", h.ImportStudents).Methods("POST")
+	r.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var report importer.Report
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if report.Created != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestGetAllStudentsAsCSV(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/students", nil)
+	req.Header.Set("Accept", "text/csv")
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/students", h.GetAllStudents).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("handler returned wrong content type: got %v want %v", ct, "text/csv")
+	}
+	if header, _, _ := strings.Cut(rr.Body.String(), "\n"); !strings.HasPrefix(header, "id,name,age,email,") {
+		t.Errorf("unexpected CSV header: %q", header)
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/backendtest"
+)
+
+func TestSearchRanksHighlightsAndIgnoresOperators(t *testing.T) {
+	backendtest.Search(t, sqlBackend{context.Background()})
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AashishKumar-3002/FealtyX/internal/backendtest"
+	"github.com/AashishKumar-3002/FealtyX/internal/middleware"
+	"github.com/AashishKumar-3002/FealtyX/internal/models"
+)
+
+func TestTenantsAreIsolated(t *testing.T) {
+	backendtest.TenantIsolation(t, backendtest.TenantRoutes{
+		Create: h.CreateStudent, Get: h.GetStudent, Update: h.UpdateStudent, Delete: h.DeleteStudent,
+		History: h.GetStudentHistory, Export: h.ExportStudents, Search: h.SearchStudents, ImportStudents: h.ImportStudents,
+		Students: middleware.TenantStudents(func(ctx context.Context, studentID int) (bool, error) {
+			return models.StudentInTenant(ctx, db, studentID)
+		}),
+	})
+}